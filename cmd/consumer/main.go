@@ -0,0 +1,168 @@
+// Command consumer is a reference on_search pointer consumer. It resolves
+// each pointer to its catalog and logs a summary.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"adapter/internal/adapters/messaging"
+	"adapter/internal/adapters/storage"
+	"adapter/internal/config"
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/consumer"
+	"adapter/pkg/eventbus"
+	"adapter/pkg/events"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadConsumerConfig()
+	if err != nil {
+		fmt.Printf("Failed to load consumer config: %v\n", err)
+		os.Exit(1)
+	}
+
+	objectStorage, err := storage.NewMinIOStorage(storage.MinIOConfig{
+		Endpoint:  cfg.MinIOEndpoint,
+		AccessKey: cfg.MinIOAccessKey,
+		SecretKey: cfg.MinIOSecretKey,
+		UseSSL:    cfg.MinIOUseSSL,
+		Bucket:    cfg.MinIOBucket,
+	})
+	if err != nil {
+		logger.Fatal(ctx, err, "Failed to initialize MinIO storage")
+	}
+
+	subscriber, dlqPublisher, err := newSubscriber(cfg)
+	if err != nil {
+		logger.Fatal(ctx, err, "Failed to initialize subscriber")
+	}
+
+	opts := consumer.Options{
+		MaxAttempts:     cfg.MaxAttempts,
+		BackoffBase:     time.Duration(cfg.BackoffBaseMs) * time.Millisecond,
+		BackoffMax:      time.Duration(cfg.BackoffMaxMs) * time.Millisecond,
+		MaxPayloadBytes: cfg.MaxPayloadBytes,
+		Logger:          slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+	if cfg.DLQTopic != "" {
+		opts.DLQTopic = cfg.DLQTopic
+		opts.DLQPublisher = dlqPublisher
+	}
+
+	pointerConsumer, err := consumer.New(subscriber, objectStorage, logSummary, opts)
+	if err != nil {
+		logger.Fatal(ctx, err, "Failed to initialize consumer")
+	}
+
+	logger.Infof(ctx, "Consuming %s from %s as %s", cfg.KafkaOnSearchTopic, cfg.EventBus, cfg.GroupID)
+	runErr := pointerConsumer.Run(ctx)
+	if runErr != nil {
+		logger.Error(ctx, runErr, "Consumer stopped")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutSecs)*time.Second)
+	defer cancel()
+	for _, resource := range []any{subscriber, dlqPublisher} {
+		if closer, ok := resource.(ports.Closer); ok {
+			if err := closer.Close(shutdownCtx); err != nil {
+				logger.Error(shutdownCtx, err, "Failed to close consumer resource")
+			}
+		}
+	}
+	logger.Info(shutdownCtx, "Consumer shutdown complete")
+
+	if runErr != nil {
+		os.Exit(1)
+	}
+}
+
+// newSubscriber returns the subscription for the pointer topic and a
+// publisher on the same bus for dead letters.
+func newSubscriber(cfg *config.ConsumerConfig) (events.Subscriber, events.Publisher, error) {
+	topics := []string{cfg.KafkaOnSearchTopic}
+	if cfg.DLQTopic != "" {
+		topics = append(topics, cfg.DLQTopic)
+	}
+
+	switch cfg.EventBus {
+	case "kafka":
+		security := eventbus.KafkaSecurityConfig{
+			TLSEnabled:            cfg.KafkaTLSEnabled,
+			TLSCAFile:             cfg.KafkaTLSCAFile,
+			TLSCertFile:           cfg.KafkaTLSCertFile,
+			TLSKeyFile:            cfg.KafkaTLSKeyFile,
+			TLSInsecureSkipVerify: cfg.KafkaTLSInsecureSkipVerify,
+			SASLMechanism:         cfg.KafkaSASLMechanism,
+			SASLUsername:          cfg.KafkaSASLUsername,
+			SASLPassword:          cfg.KafkaSASLPassword,
+		}
+		subscriber, err := eventbus.NewKafkaSubscriber(eventbus.KafkaSubscriberConfig{
+			Brokers:     cfg.KafkaBrokers,
+			Topic:       cfg.KafkaOnSearchTopic,
+			GroupID:     cfg.GroupID,
+			Security:    security,
+			StartOffset: cfg.StartOffset,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		publisher, err := messaging.NewKafkaPublisher(messaging.KafkaConfig{
+			Brokers:  cfg.KafkaBrokers,
+			Topics:   topics,
+			Security: security,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return subscriber, publisher, nil
+	case "nats":
+		natsConfig := eventbus.NATSConfig{
+			URL:       cfg.NATSURL,
+			Stream:    cfg.NATSStream,
+			Topics:    topics,
+			Username:  cfg.NATSUser,
+			Password:  cfg.NATSPassword,
+			Token:     cfg.NATSToken,
+			CredsFile: cfg.NATSCredsFile,
+		}
+		subscriber, err := eventbus.NewNATSSubscriber(natsConfig, cfg.KafkaOnSearchTopic, cfg.GroupID)
+		if err != nil {
+			return nil, nil, err
+		}
+		publisher, err := messaging.NewNATSPublisher(natsConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return subscriber, publisher, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported event bus %q", cfg.EventBus)
+	}
+}
+
+func logSummary(ctx context.Context, delivery *consumer.Delivery) error {
+	onSearch := delivery.OnSearch
+	catalog := onSearch.Message.Catalog
+	logger.Infof(ctx,
+		"on_search txn=%s msg=%s domain=%s bpp=%s city=%s providers=%d items=%d bytes=%d object=%s",
+		onSearch.Context.TransactionID,
+		onSearch.Context.MessageID,
+		onSearch.Context.Domain,
+		onSearch.Context.BppID,
+		onSearch.Context.City,
+		len(catalog.Providers),
+		catalog.ItemCount(),
+		len(delivery.Payload),
+		delivery.Pointer.ObjectKey,
+	)
+	return nil
+}
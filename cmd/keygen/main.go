@@ -0,0 +1,142 @@
+// Command keygen generates the key pairs an ONDC network participant
+// registers: an ed25519 signing pair and an X25519 encryption pair. It
+// prints them as environment settings for the edge, followed by the
+// /subscribe payload to send to the registry. The entity details in the
+// payload are placeholders to complete before subscribing.
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"adapter/pkg/ondc"
+)
+
+func main() {
+	subscriberID := flag.String("subscriber-id", "", "subscriber id, the participant's domain name (required)")
+	uniqueKeyID := flag.String("unique-key-id", "", "unique key id of the key pair (random when empty)")
+	requestID := flag.String("request-id", "", "request id of the subscription (random when empty)")
+	callbackURL := flag.String("callback-url", "/", "path the registry appends /on_subscribe to")
+	domains := flag.String("domains", "ONDC:RET10", "comma-separated domains to subscribe to")
+	participantType := flag.String("type", "sellerApp", "network participant type: buyerApp, sellerApp or gateway")
+	cities := flag.String("cities", "std:080", "comma-separated city codes")
+	opsNo := flag.Int("ops-no", 2, "registry operation number (1 buyer app, 2 seller app, ...)")
+	validDays := flag.Int("valid-days", 365, "days the keys stay valid")
+	flag.Parse()
+
+	if *subscriberID == "" {
+		fmt.Fprintln(os.Stderr, "-subscriber-id is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *uniqueKeyID == "" {
+		*uniqueKeyID = uuid.NewString()
+	}
+	if *requestID == "" {
+		*requestID = uuid.NewString()
+	}
+
+	signingPublic, signingPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fail("failed to generate signing key: %v", err)
+	}
+	encryptionPrivate, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		fail("failed to generate encryption key: %v", err)
+	}
+	encryptionPrivateValue, err := ondc.MarshalEncryptionPrivateKey(encryptionPrivate)
+	if err != nil {
+		fail("failed to encode encryption key: %v", err)
+	}
+	encryptionPublicValue, err := ondc.MarshalEncryptionPublicKey(encryptionPrivate.PublicKey())
+	if err != nil {
+		fail("failed to encode encryption public key: %v", err)
+	}
+	signingPublicValue := base64.StdEncoding.EncodeToString(signingPublic)
+
+	now := time.Now().UTC()
+	cityCodes := splitList(*cities)
+	request := ondc.SubscribeRequest{}
+	request.Context.Operation.OpsNo = *opsNo
+	request.Message = ondc.SubscribeMessage{
+		RequestID: *requestID,
+		Timestamp: now.Format(time.RFC3339Nano),
+		Entity: ondc.SubscribeEntity{
+			GST: ondc.SubscriberGST{
+				LegalEntityName: "<legal entity name>",
+				BusinessAddress: "<business address>",
+				CityCode:        cityCodes,
+				GstNo:           "<gst number>",
+			},
+			PAN: ondc.SubscriberPAN{
+				NameAsPerPan:        "<name as per PAN>",
+				PanNo:               "<PAN number>",
+				DateOfIncorporation: "<DD/MM/YYYY>",
+			},
+			NameOfAuthorisedSignatory: "<authorised signatory>",
+			EmailID:                   "<email>",
+			Country:                   "IND",
+			SubscriberID:              *subscriberID,
+			UniqueKeyID:               *uniqueKeyID,
+			CallbackURL:               *callbackURL,
+			KeyPair: ondc.KeyPair{
+				SigningPublicKey:    signingPublicValue,
+				EncryptionPublicKey: encryptionPublicValue,
+				ValidFrom:           now.Format(time.RFC3339Nano),
+				ValidUntil:          now.AddDate(0, 0, *validDays).Format(time.RFC3339Nano),
+			},
+		},
+	}
+	for _, domain := range splitList(*domains) {
+		request.Message.NetworkParticipant = append(request.Message.NetworkParticipant, ondc.NetworkParticipant{
+			SubscriberURL: *callbackURL,
+			Domain:        domain,
+			Type:          *participantType,
+			CityCode:      cityCodes,
+		})
+	}
+	payload, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		fail("failed to encode subscribe payload: %v", err)
+	}
+
+	fmt.Println("# Edge settings; keep the private keys secret")
+	fmt.Printf("ONDC_SUBSCRIBER_ID=%s\n", *subscriberID)
+	fmt.Printf("ONDC_UNIQUE_KEY_ID=%s\n", *uniqueKeyID)
+	fmt.Printf("ONDC_SIGNING_PRIVATE_KEY=%s\n", base64.StdEncoding.EncodeToString(signingPrivate.Seed()))
+	fmt.Printf("ONDC_ENCRYPTION_PRIVATE_KEY=%s\n", encryptionPrivateValue)
+	fmt.Printf("ONDC_SUBSCRIBE_REQUEST_ID=%s\n", *requestID)
+	fmt.Println("# Set ONDC_REGISTRY_ENCRYPTION_PUBLIC_KEY to the registry's published key")
+	fmt.Println()
+	fmt.Println("# Public keys")
+	fmt.Printf("signing_public_key=%s\n", signingPublicValue)
+	fmt.Printf("encryption_public_key=%s\n", encryptionPublicValue)
+	fmt.Println()
+	fmt.Println("# /subscribe payload")
+	fmt.Println(string(payload))
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -14,6 +14,7 @@ import (
 	"adapter/internal/config/di"
 	"adapter/internal/handlers"
 	appError "adapter/internal/shared/error"
+	"adapter/internal/shared/lifecycle"
 	logger "adapter/internal/shared/log"
 	"adapter/internal/shared/middleware"
 )
@@ -29,8 +30,11 @@ func main() {
 
 	app := fiber.New(fiber.Config{
 		ErrorHandler: appError.ErrorHandler(),
+		// Server-wide ceiling; each route enforces its own limit on the decoded body
+		BodyLimit: container.Config.MaxRequestBodyBytes(),
 	})
 
+	app.Use(middleware.InFlightMiddleware(container.Lifecycle))
 	app.Use(middleware.RecoveryMiddleware())
 	app.Use(middleware.RequestIDMiddleware())
 	app.Use(middleware.LoggingMiddleware())
@@ -43,12 +47,16 @@ func main() {
 	// Application routes
 	handlers.RegisterRoutes(app, container)
 
+	// The HTTP server is drained before any adapter it depends on is closed
+	container.Lifecycle.Register(lifecycle.PhaseHTTP, "http", app.ShutdownWithContext)
+
 	port := container.Config.Port
 
 	fmt.Printf("\n🚀 Starting gcr-edge-service server\n")
 	fmt.Printf("   Port: %s\n", port)
 	fmt.Printf("   Logging: ✅ Enabled (Application-level logging active)\n")
 	fmt.Printf("   Health Check: http://localhost:%s/health\n", port)
+	fmt.Printf("   Readiness: http://localhost:%s/readyz\n", port)
 	fmt.Printf("\n")
 
 	logger.Infof(ctx, "Starting gcr-edge-service server on port %s", port)
@@ -82,14 +90,13 @@ func main() {
 	}
 	logger.Info(ctx, "Shutting down server...")
 
+	// Fail readiness first so load balancers stop sending new requests
+	container.Health.MarkShuttingDown()
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
 	if err := container.Shutdown(shutdownCtx); err != nil {
-		logger.Error(ctx, err, "Error during container shutdown")
-	}
-
-	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
 		logger.Error(ctx, err, "Server forced to shutdown")
 	} else {
 		logger.Info(ctx, "Server shutdown complete")
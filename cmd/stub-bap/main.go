@@ -0,0 +1,125 @@
+// Command stub-bap is a local buyer app for exercising callback dispatch.
+// It accepts POST /<action>, verifies the ONDC signature when a public key
+// is configured, records what it received and ACKs. Failures can be
+// injected to watch the dispatcher retry.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/valyala/fastjson"
+
+	"adapter/internal/shared/ack"
+	"adapter/pkg/ondc"
+)
+
+type stubConfig struct {
+	Addr string `envconfig:"ADDR" default:":9090"`
+	// PublicKey is the edge's base64 ed25519 signing public key. Signatures
+	// are not checked when it is empty.
+	PublicKey string `envconfig:"PUBLIC_KEY"`
+	// FailFirst makes the first N requests return 503.
+	FailFirst int64 `envconfig:"FAIL_FIRST" default:"0"`
+	// Nack answers every request with a NACK instead of an ACK.
+	Nack bool `envconfig:"NACK" default:"false"`
+}
+
+type receivedCallback struct {
+	Action        string    `json:"action"`
+	TransactionID string    `json:"transaction_id"`
+	MessageID     string    `json:"message_id"`
+	BppID         string    `json:"bpp_id"`
+	SizeBytes     int       `json:"size_bytes"`
+	SignedBy      string    `json:"signed_by,omitempty"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
+
+func main() {
+	var cfg stubConfig
+	if err := envconfig.Process("STUB_BAP", &cfg); err != nil {
+		fmt.Printf("Failed to load stub BAP config: %v\n", err)
+		os.Exit(1)
+	}
+
+	var (
+		mu       sync.Mutex
+		received []receivedCallback
+		requests atomic.Int64
+	)
+
+	app := fiber.New(fiber.Config{BodyLimit: 512 << 20})
+
+	app.Get("/received", func(c *fiber.Ctx) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return c.JSON(fiber.Map{"callbacks": received})
+	})
+	app.Delete("/received", func(c *fiber.Ctx) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = nil
+		requests.Store(0)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	app.Post("/:action", func(c *fiber.Ctx) error {
+		body := c.Body()
+		// Params point into a buffer fasthttp reuses after the handler returns
+		action := strings.Clone(c.Params("action"))
+		if n := requests.Add(1); n <= cfg.FailFirst {
+			fmt.Printf("Failing request %d of %d for /%s\n", n, cfg.FailFirst, action)
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+
+		var signedBy string
+		if cfg.PublicKey != "" {
+			params, err := ondc.VerifyAuthorization(c.Get(fiber.HeaderAuthorization), body, cfg.PublicKey)
+			if err != nil {
+				fmt.Printf("Rejected /%s: %v\n", action, err)
+				code := "10001"
+				if !errors.Is(err, ondc.ErrInvalidSignature) {
+					code = "10002"
+				}
+				return c.Status(fiber.StatusUnauthorized).JSON(ack.NewNack(ack.ErrorTypePolicy, code, err.Error()))
+			}
+			signedBy = params.SubscriberID
+		}
+
+		if got := fastjson.GetString(body, "context", "action"); got != action {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(ack.ErrorTypeContext, "20000", fmt.Sprintf("context.action %q does not match /%s", got, action)))
+		}
+
+		callback := receivedCallback{
+			Action:        action,
+			TransactionID: fastjson.GetString(body, "context", "transaction_id"),
+			MessageID:     fastjson.GetString(body, "context", "message_id"),
+			BppID:         fastjson.GetString(body, "context", "bpp_id"),
+			SizeBytes:     len(body),
+			SignedBy:      signedBy,
+			ReceivedAt:    time.Now().UTC(),
+		}
+		mu.Lock()
+		received = append(received, callback)
+		mu.Unlock()
+		fmt.Printf("Received %s transaction_id=%s from bpp_id=%s (%d bytes)\n", callback.Action, callback.TransactionID, callback.BppID, callback.SizeBytes)
+
+		if cfg.Nack {
+			return c.JSON(ack.NewNack(ack.ErrorTypeInternal, "20000", "stub BAP configured to NACK"))
+		}
+		return c.JSON(ack.NewAck())
+	})
+
+	fmt.Printf("Stub BAP listening on %s\n", cfg.Addr)
+	if err := app.Listen(cfg.Addr); err != nil {
+		fmt.Printf("Stub BAP stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
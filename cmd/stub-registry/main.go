@@ -0,0 +1,234 @@
+// Command stub-registry is a local ONDC registry for rehearsing
+// onboarding. POST /subscribe runs the registry's checks against the
+// subscriber: it fetches the site verification page and verifies the
+// signed request id, then sends an encrypted /on_subscribe challenge and
+// compares the answer. Subscribers that pass are listed by POST /lookup.
+package main
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kelseyhightower/envconfig"
+
+	"adapter/internal/shared/ack"
+	"adapter/pkg/ondc"
+)
+
+type stubConfig struct {
+	Addr string `envconfig:"ADDR" default:":9091"`
+	// PrivateKey is the registry's base64 X25519 key. A key is generated
+	// when empty; its public key is printed for
+	// ONDC_REGISTRY_ENCRYPTION_PUBLIC_KEY.
+	PrivateKey string `envconfig:"PRIVATE_KEY"`
+	// SubscriberBaseURL replaces https://<subscriber_id> when reaching the
+	// subscriber, e.g. http://localhost:8080.
+	SubscriberBaseURL string `envconfig:"SUBSCRIBER_BASE_URL"`
+	TimeoutMs         int    `envconfig:"TIMEOUT_MS" default:"5000"`
+}
+
+// subscriber is a lookup entry.
+type subscriber struct {
+	SubscriberID        string    `json:"subscriber_id"`
+	UniqueKeyID         string    `json:"ukId"`
+	SubscriberURL       string    `json:"subscriber_url"`
+	Type                string    `json:"type"`
+	Domain              string    `json:"domain"`
+	City                string    `json:"city"`
+	SigningPublicKey    string    `json:"signing_public_key"`
+	EncryptionPublicKey string    `json:"encr_public_key"`
+	ValidFrom           string    `json:"valid_from"`
+	ValidUntil          string    `json:"valid_until"`
+	Status              string    `json:"status"`
+	Created             time.Time `json:"created"`
+}
+
+type lookupRequest struct {
+	SubscriberID string `json:"subscriber_id"`
+	Domain       string `json:"domain"`
+	Type         string `json:"type"`
+	City         string `json:"city"`
+}
+
+var siteVerificationContent = regexp.MustCompile(`name=['"]ondc-site-verification['"]\s+content=['"]([^'"]+)['"]`)
+
+func main() {
+	var cfg stubConfig
+	if err := envconfig.Process("STUB_REGISTRY", &cfg); err != nil {
+		fmt.Printf("Failed to load stub registry config: %v\n", err)
+		os.Exit(1)
+	}
+
+	var privateKey *ecdh.PrivateKey
+	var err error
+	if cfg.PrivateKey != "" {
+		privateKey, err = ondc.ParseEncryptionPrivateKey(cfg.PrivateKey)
+	} else {
+		privateKey, err = ecdh.X25519().GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		fmt.Printf("Invalid registry key: %v\n", err)
+		os.Exit(1)
+	}
+	publicKey, err := ondc.MarshalEncryptionPublicKey(privateKey.PublicKey())
+	if err != nil {
+		fmt.Printf("Failed to encode registry public key: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Registry encryption public key: %s\n", publicKey)
+
+	client := &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond}
+	var (
+		mu          sync.Mutex
+		subscribers = make(map[string][]subscriber)
+	)
+
+	app := fiber.New()
+
+	app.Post("/subscribe", func(c *fiber.Ctx) error {
+		var request ondc.SubscribeRequest
+		if err := json.Unmarshal(c.Body(), &request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(ack.ErrorTypeJSONSchema, "1050", err.Error()))
+		}
+		entity := request.Message.Entity
+		if entity.SubscriberID == "" || request.Message.RequestID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(ack.ErrorTypeJSONSchema, "1050", "subscriber_id and request_id are required"))
+		}
+		subscriberKey, err := ondc.ParseEncryptionPublicKey(entity.KeyPair.EncryptionPublicKey)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(ack.ErrorTypePolicy, "1050", err.Error()))
+		}
+
+		baseURL := cfg.SubscriberBaseURL
+		if baseURL == "" {
+			baseURL = "https://" + entity.SubscriberID
+		}
+		baseURL = strings.TrimSuffix(baseURL, "/")
+
+		if err := verifySite(client, baseURL, request.Message.RequestID, entity.KeyPair.SigningPublicKey); err != nil {
+			fmt.Printf("Site verification of %s failed: %v\n", entity.SubscriberID, err)
+			return c.Status(fiber.StatusUnauthorized).JSON(ack.NewNack(ack.ErrorTypePolicy, "1052", "site verification failed: "+err.Error()))
+		}
+		callbackURL := strings.TrimSuffix(baseURL+"/"+strings.Trim(entity.CallbackURL, "/"), "/") + "/on_subscribe"
+		if err := challenge(client, callbackURL, entity.SubscriberID, privateKey, subscriberKey); err != nil {
+			fmt.Printf("Challenge of %s failed: %v\n", entity.SubscriberID, err)
+			return c.Status(fiber.StatusUnauthorized).JSON(ack.NewNack(ack.ErrorTypePolicy, "1053", "on_subscribe challenge failed: "+err.Error()))
+		}
+
+		var entries []subscriber
+		for _, participant := range request.Message.NetworkParticipant {
+			for _, city := range participant.CityCode {
+				entries = append(entries, subscriber{
+					SubscriberID:        entity.SubscriberID,
+					UniqueKeyID:         entity.UniqueKeyID,
+					SubscriberURL:       baseURL + participant.SubscriberURL,
+					Type:                participant.Type,
+					Domain:              participant.Domain,
+					City:                city,
+					SigningPublicKey:    entity.KeyPair.SigningPublicKey,
+					EncryptionPublicKey: entity.KeyPair.EncryptionPublicKey,
+					ValidFrom:           entity.KeyPair.ValidFrom,
+					ValidUntil:          entity.KeyPair.ValidUntil,
+					Status:              "SUBSCRIBED",
+					Created:             time.Now().UTC(),
+				})
+			}
+		}
+		mu.Lock()
+		subscribers[entity.SubscriberID] = entries
+		mu.Unlock()
+		fmt.Printf("Subscribed %s with %d lookup entries\n", entity.SubscriberID, len(entries))
+		return c.JSON(ack.NewAck())
+	})
+
+	app.Post("/lookup", func(c *fiber.Ctx) error {
+		var request lookupRequest
+		if err := json.Unmarshal(c.Body(), &request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(ack.ErrorTypeJSONSchema, "1050", err.Error()))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		matches := []subscriber{}
+		for _, entries := range subscribers {
+			for _, entry := range entries {
+				if (request.SubscriberID == "" || entry.SubscriberID == request.SubscriberID) &&
+					(request.Domain == "" || entry.Domain == request.Domain) &&
+					(request.Type == "" || entry.Type == request.Type) &&
+					(request.City == "" || entry.City == request.City) {
+					matches = append(matches, entry)
+				}
+			}
+		}
+		return c.JSON(matches)
+	})
+
+	fmt.Printf("Stub registry listening on %s\n", cfg.Addr)
+	if err := app.Listen(cfg.Addr); err != nil {
+		fmt.Printf("Stub registry stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// verifySite fetches the site verification page and checks the request id
+// it carries against the subscriber's signing key.
+func verifySite(client *http.Client, baseURL, requestID, signingPublicKey string) error {
+	response, err := client.Get(baseURL + ondc.SiteVerificationPath)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", ondc.SiteVerificationPath, response.StatusCode)
+	}
+	match := siteVerificationContent.FindSubmatch(body)
+	if match == nil {
+		return fmt.Errorf("no ondc-site-verification meta tag")
+	}
+	return ondc.VerifyRequestID(signingPublicKey, requestID, string(match[1]))
+}
+
+// challenge sends an encrypted random challenge to the subscriber's
+// /on_subscribe and checks the answer.
+func challenge(client *http.Client, callbackURL, subscriberID string, private *ecdh.PrivateKey, peer *ecdh.PublicKey) error {
+	expected := uuid.NewString()
+	encrypted, err := ondc.EncryptChallenge(expected, private, peer)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(ondc.OnSubscribeRequest{SubscriberID: subscriberID, Challenge: encrypted})
+	if err != nil {
+		return err
+	}
+	response, err := client.Post(callbackURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", callbackURL, response.StatusCode)
+	}
+	var answer ondc.OnSubscribeResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, 64<<10)).Decode(&answer); err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
+	if answer.Answer != expected {
+		return fmt.Errorf("answer does not match the challenge")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// IsRetryable reports whether a Kafka publish error is transient. Protocol
+// errors are classified using Kafka's own retriable flag; anything else
+// (network failures, timeouts) is assumed to be transient.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, writeErr := range writeErrs {
+			if writeErr != nil && !IsRetryable(writeErr) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Temporary() || kafkaErr.Timeout()
+	}
+	return true
+}
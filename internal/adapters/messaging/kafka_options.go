@@ -0,0 +1,97 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/eventbus"
+)
+
+// KafkaSecurityConfig is shared with the subscribers in pkg/eventbus.
+type KafkaSecurityConfig = eventbus.KafkaSecurityConfig
+
+// newKafkaTransport builds the transport shared by the writer and the
+// admin client.
+func newKafkaTransport(cfg KafkaSecurityConfig) (*kafka.Transport, error) {
+	transport := &kafka.Transport{}
+
+	tlsConfig, err := eventbus.KafkaTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLS = tlsConfig
+
+	mechanism, err := eventbus.KafkaSASLMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.SASL = mechanism
+
+	return transport, nil
+}
+
+func parseCompression(name string) (kafka.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported kafka compression %q", name)
+	}
+}
+
+func parseBalancer(name string) (kafka.Balancer, error) {
+	switch strings.ToLower(name) {
+	case "", "hash":
+		return &kafka.Hash{}, nil
+	case "murmur2":
+		return &kafka.Murmur2Balancer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka balancer %q", name)
+	}
+}
+
+// EnsureTopics creates the configured topics if they do not exist yet.
+// Existing topics are left untouched.
+func (p *KafkaPublisher) EnsureTopics(ctx context.Context, partitions, replicationFactor int) error {
+	if len(p.topics) == 0 {
+		return nil
+	}
+
+	configs := make([]kafka.TopicConfig, 0, len(p.topics))
+	for _, topic := range p.topics {
+		configs = append(configs, kafka.TopicConfig{
+			Topic:             topic,
+			NumPartitions:     partitions,
+			ReplicationFactor: replicationFactor,
+		})
+	}
+
+	resp, err := p.client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: configs})
+	if err != nil {
+		return fmt.Errorf("failed to create kafka topics: %w", err)
+	}
+	for topic, topicErr := range resp.Errors {
+		switch {
+		case topicErr == nil:
+			logger.Infof(ctx, "Created kafka topic %s (partitions=%d, replication=%d)", topic, partitions, replicationFactor)
+		case errors.Is(topicErr, kafka.TopicAlreadyExists):
+			logger.Infof(ctx, "Kafka topic %s already exists", topic)
+		default:
+			return fmt.Errorf("failed to create kafka topic %s: %w", topic, topicErr)
+		}
+	}
+	return nil
+}
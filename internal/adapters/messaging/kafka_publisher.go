@@ -9,15 +9,45 @@ import (
 	"github.com/segmentio/kafka-go"
 
 	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
 )
 
 type KafkaConfig struct {
 	Brokers string
+	// Topics the service publishes to. They are checked by the health probe
+	// and created at startup when AutoCreateTopics is set.
+	Topics []string
+	// WriteTimeout bounds a single publish; defaults to 10s.
+	WriteTimeout time.Duration
+
+	Security KafkaSecurityConfig
+
+	// Compression is one of none, gzip, snappy, lz4 or zstd.
+	Compression string
+	// Balancer is "hash" (FNV-1a, the kafka-go default) or "murmur2"
+	// (compatible with the Java client). Both partition by message key.
+	Balancer string
+	// BatchSize, BatchBytes and BatchTimeout tune writer batching. With
+	// synchronous writes BatchTimeout is the extra latency a lone message
+	// may wait for its batch to fill.
+	BatchSize    int
+	BatchBytes   int64
+	BatchTimeout time.Duration
+	// Async returns from Publish before the broker acknowledges the write.
+	// Delivery failures are then only logged.
+	Async bool
+
+	AutoCreateTopics       bool
+	TopicPartitions        int
+	TopicReplicationFactor int
 }
 
 // KafkaPublisher implements ports.EventPublisher using kafka-go.
 type KafkaPublisher struct {
-	writer *kafka.Writer
+	writer       *kafka.Writer
+	client       *kafka.Client
+	topics       []string
+	writeTimeout time.Duration
 }
 
 func NewKafkaPublisher(cfg KafkaConfig) (ports.EventPublisher, error) {
@@ -26,18 +56,63 @@ func NewKafkaPublisher(cfg KafkaConfig) (ports.EventPublisher, error) {
 		return nil, fmt.Errorf("no kafka brokers configured")
 	}
 
+	transport, err := newKafkaTransport(cfg.Security)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	balancer, err := parseBalancer(cfg.Balancer)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(brokers...),
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     balancer,
 		RequiredAcks: kafka.RequireAll,
-		Async:        false,
+		Async:        cfg.Async,
+		Compression:  compression,
+		BatchSize:    cfg.BatchSize,
+		BatchBytes:   cfg.BatchBytes,
+		BatchTimeout: cfg.BatchTimeout,
+		Transport:    transport,
+	}
+	if cfg.Async {
+		writer.Completion = func(messages []kafka.Message, err error) {
+			if err != nil {
+				logger.Errorf(context.Background(), err, "Async publish of %d kafka messages failed", len(messages))
+			}
+		}
+	}
+
+	writeTimeout := cfg.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = 10 * time.Second
 	}
 
-	return &KafkaPublisher{writer: writer}, nil
+	publisher := &KafkaPublisher{
+		writer:       writer,
+		client:       &kafka.Client{Addr: writer.Addr, Timeout: 5 * time.Second, Transport: transport},
+		topics:       cfg.Topics,
+		writeTimeout: writeTimeout,
+	}
+
+	if cfg.AutoCreateTopics {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := publisher.EnsureTopics(ctx, cfg.TopicPartitions, cfg.TopicReplicationFactor); err != nil {
+			return nil, err
+		}
+	}
+
+	return publisher, nil
 }
 
-func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	ctx, cancel := context.WithTimeout(ctx, p.writeTimeout)
 	defer cancel()
 
 	msg := kafka.Message{
@@ -45,9 +120,48 @@ func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key, value [
 		Key:   key,
 		Value: value,
 	}
+	for _, header := range headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: header.Key, Value: header.Value})
+	}
 
 	if err := p.writer.WriteMessages(ctx, msg); err != nil {
 		return fmt.Errorf("failed to publish kafka message: %w", err)
 	}
 	return nil
 }
+
+// HealthCheck fetches cluster metadata for the configured topics and fails
+// if a broker is unreachable or a topic reports an error.
+func (p *KafkaPublisher) HealthCheck(ctx context.Context) error {
+	resp, err := p.client.Metadata(ctx, &kafka.MetadataRequest{Topics: p.topics})
+	if err != nil {
+		return fmt.Errorf("failed to fetch kafka metadata: %w", err)
+	}
+	if len(resp.Brokers) == 0 {
+		return fmt.Errorf("kafka metadata returned no brokers")
+	}
+	for _, topic := range resp.Topics {
+		if topic.Error != nil {
+			return fmt.Errorf("kafka topic %s unavailable: %w", topic.Name, topic.Error)
+		}
+	}
+	return nil
+}
+
+// Close flushes any buffered messages and closes the underlying writer.
+func (p *KafkaPublisher) Close(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- p.writer.Close()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to close kafka writer: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out flushing kafka writer: %w", ctx.Err())
+	}
+}
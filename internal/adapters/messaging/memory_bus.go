@@ -0,0 +1,191 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+)
+
+// MemoryBus implements ports.EventPublisher with in-process channels. It
+// lets the pipeline run without a broker in local development and tests.
+// Messages published to a topic with no subscribers are dropped.
+type MemoryBus struct {
+	buffer int
+
+	mu            sync.RWMutex
+	subscriptions map[string][]*MemorySubscription
+	closed        bool
+}
+
+// MemorySubscription receives every message published to its topic after
+// it was created. It implements ports.EventSubscriber; offsets increase
+// per subscription and commits are no-ops.
+type MemorySubscription struct {
+	topic  string
+	ch     chan ports.Message
+	bus    *MemoryBus
+	once   sync.Once
+	offset atomic.Int64
+	// done unblocks publishers waiting on a full ch; ch is closed once
+	// the sends in flight have returned.
+	done  chan struct{}
+	sends sync.WaitGroup
+}
+
+// NewMemoryBus creates a bus whose subscriptions buffer up to buffer
+// messages. Publishing blocks while a subscriber's buffer is full, until
+// the subscription is closed or the publisher's ctx is done.
+func NewMemoryBus(buffer int) *MemoryBus {
+	if buffer <= 0 {
+		buffer = 1
+	}
+	return &MemoryBus{
+		buffer:        buffer,
+		subscriptions: make(map[string][]*MemorySubscription),
+	}
+}
+
+// Publish delivers the message to the topic's current subscriptions. It
+// waits on a full subscription without holding the bus lock, so a slow
+// subscriber cannot block Subscribe, Unsubscribe or Close.
+func (b *MemoryBus) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return fmt.Errorf("memory bus is closed")
+	}
+	subscriptions := append([]*MemorySubscription(nil), b.subscriptions[topic]...)
+	for _, sub := range subscriptions {
+		sub.sends.Add(1)
+	}
+	b.mu.RUnlock()
+
+	if len(subscriptions) == 0 {
+		logger.Debugf(ctx, "No subscribers for topic %s, dropping message", topic)
+		return nil
+	}
+
+	msg := ports.Message{
+		Topic:     topic,
+		Key:       append([]byte(nil), key...),
+		Value:     append([]byte(nil), value...),
+		Headers:   append([]ports.Header(nil), headers...),
+		Timestamp: time.Now().UTC(),
+	}
+	var err error
+	for _, sub := range subscriptions {
+		if err == nil {
+			err = sub.deliver(ctx, msg)
+		}
+		sub.sends.Done()
+	}
+	return err
+}
+
+// deliver waits for room in the subscription's buffer. A message for a
+// subscription closed meanwhile is dropped.
+func (s *MemorySubscription) deliver(ctx context.Context, msg ports.Message) error {
+	msg.Offset = s.offset.Add(1) - 1
+	select {
+	case s.ch <- msg:
+		return nil
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to deliver message to %s subscriber: %w", s.topic, ctx.Err())
+	}
+}
+
+// Subscribe registers a subscription for topic.
+func (b *MemoryBus) Subscribe(topic string) *MemorySubscription {
+	sub := &MemorySubscription{
+		topic: topic,
+		ch:    make(chan ports.Message, b.buffer),
+		bus:   b,
+		done:  make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.close()
+		return sub
+	}
+	b.subscriptions[topic] = append(b.subscriptions[topic], sub)
+	return sub
+}
+
+// Messages returns the channel of delivered messages. It is closed when the
+// subscription or the bus is closed.
+func (s *MemorySubscription) Messages() <-chan ports.Message {
+	return s.ch
+}
+
+// Fetch returns the next message, or ports.ErrSubscriptionClosed once the
+// subscription is closed.
+func (s *MemorySubscription) Fetch(ctx context.Context) (ports.Message, error) {
+	select {
+	case msg, ok := <-s.ch:
+		if !ok {
+			return ports.Message{}, ports.ErrSubscriptionClosed
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return ports.Message{}, ctx.Err()
+	}
+}
+
+// Commit is a no-op: delivered messages are never redelivered.
+func (s *MemorySubscription) Commit(ctx context.Context, msg ports.Message) error {
+	return nil
+}
+
+// Unsubscribe stops delivery and closes the message channel.
+func (s *MemorySubscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	subscriptions := s.bus.subscriptions[s.topic]
+	for i, sub := range subscriptions {
+		if sub == s {
+			s.bus.subscriptions[s.topic] = append(subscriptions[:i:i], subscriptions[i+1:]...)
+			break
+		}
+	}
+	s.bus.mu.Unlock()
+	s.close()
+}
+
+// close must be called once the subscription can no longer be picked up by
+// Publish: it releases waiting publishers and closes ch after the last one
+// has returned, so no send can hit a closed channel.
+func (s *MemorySubscription) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.sends.Wait()
+		close(s.ch)
+	})
+}
+
+// Close stops the bus and closes every subscription.
+func (b *MemoryBus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subscriptions := b.subscriptions
+	b.subscriptions = make(map[string][]*MemorySubscription)
+	b.mu.Unlock()
+
+	for _, topicSubscriptions := range subscriptions {
+		for _, sub := range topicSubscriptions {
+			sub.close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"adapter/internal/ports"
+)
+
+// publishBlocked starts a Publish that waits on a full subscription and
+// returns its result channel.
+func publishBlocked(t *testing.T, bus *MemoryBus, ctx context.Context) <-chan error {
+	t.Helper()
+	if err := bus.Publish(ctx, "topic", nil, []byte("first")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	result := make(chan error, 1)
+	go func() { result <- bus.Publish(ctx, "topic", nil, []byte("second")) }()
+	select {
+	case err := <-result:
+		t.Fatalf("Publish to a full subscription returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	return result
+}
+
+func waitResult(t *testing.T, result <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("blocked Publish did not return")
+		return nil
+	}
+}
+
+func TestMemoryBusSlowSubscriber(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(bus *MemoryBus, sub *MemorySubscription)
+	}{
+		{name: "close", stop: func(bus *MemoryBus, sub *MemorySubscription) { bus.Close(context.Background()) }},
+		{name: "unsubscribe", stop: func(bus *MemoryBus, sub *MemorySubscription) { sub.Unsubscribe() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewMemoryBus(1)
+			sub := bus.Subscribe("topic")
+			result := publishBlocked(t, bus, context.Background())
+
+			stopped := make(chan struct{})
+			go func() {
+				tt.stop(bus, sub)
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(time.Second):
+				t.Fatalf("%s blocked behind a publisher waiting on a full subscription", tt.name)
+			}
+			if err := waitResult(t, result); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+
+			// The buffered message is still delivered before the channel closes.
+			msg, err := sub.Fetch(context.Background())
+			if err != nil || string(msg.Value) != "first" {
+				t.Fatalf("Fetch = %q, %v; want the buffered message", msg.Value, err)
+			}
+			if _, err := sub.Fetch(context.Background()); !errors.Is(err, ports.ErrSubscriptionClosed) {
+				t.Fatalf("Fetch after %s = %v, want ErrSubscriptionClosed", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestMemoryBusPublishHonoursContext(t *testing.T) {
+	bus := NewMemoryBus(1)
+	bus.Subscribe("topic")
+	ctx, cancel := context.WithCancel(context.Background())
+	result := publishBlocked(t, bus, ctx)
+
+	cancel()
+	if err := waitResult(t, result); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Publish = %v, want context.Canceled", err)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := bus.Publish(context.Background(), "topic", nil, []byte("late")); err == nil {
+		t.Fatal("Publish on a closed bus succeeded")
+	}
+}
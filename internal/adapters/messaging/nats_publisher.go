@@ -0,0 +1,102 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/eventbus"
+)
+
+// cloudEventIDHeader doubles as the JetStream de-duplication id.
+const cloudEventIDHeader = "ce_id"
+
+// NATSConfig is shared with the subscribers in pkg/eventbus.
+type NATSConfig = eventbus.NATSConfig
+
+// NATSPublisher implements ports.EventPublisher using NATS JetStream.
+type NATSPublisher struct {
+	conn           *nats.Conn
+	js             jetstream.JetStream
+	stream         string
+	publishTimeout time.Duration
+}
+
+func NewNATSPublisher(cfg NATSConfig) (ports.EventPublisher, error) {
+	conn, js, err := eventbus.ConnectNATS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AutoCreateStream {
+		logger.Infof(context.Background(), "JetStream stream %s ready for subjects %v", cfg.Stream, cfg.Topics)
+	}
+
+	publishTimeout := cfg.PublishTimeout
+	if publishTimeout <= 0 {
+		publishTimeout = 10 * time.Second
+	}
+
+	return &NATSPublisher{
+		conn:           conn,
+		js:             js,
+		stream:         cfg.Stream,
+		publishTimeout: publishTimeout,
+	}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	ctx, cancel := context.WithTimeout(ctx, p.publishTimeout)
+	defer cancel()
+
+	msg := nats.NewMsg(topic)
+	msg.Data = value
+	if len(key) > 0 {
+		msg.Header.Set(eventbus.NATSKeyHeader, string(key))
+	}
+	var opts []jetstream.PublishOpt
+	for _, header := range headers {
+		msg.Header.Add(header.Key, string(header.Value))
+		if header.Key == cloudEventIDHeader {
+			opts = append(opts, jetstream.WithMsgID(string(header.Value)))
+		}
+	}
+
+	if _, err := p.js.PublishMsg(ctx, msg, opts...); err != nil {
+		return fmt.Errorf("failed to publish NATS message: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck verifies the connection is up and the stream exists.
+func (p *NATSPublisher) HealthCheck(ctx context.Context) error {
+	if status := p.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("nats connection is %s", status)
+	}
+	if _, err := p.js.Stream(ctx, p.stream); err != nil {
+		return fmt.Errorf("jetstream stream %s unavailable: %w", p.stream, err)
+	}
+	return nil
+}
+
+// Close drains pending publishes and closes the connection.
+func (p *NATSPublisher) Close(ctx context.Context) error {
+	closed := make(chan struct{})
+	p.conn.SetClosedHandler(func(*nats.Conn) { close(closed) })
+	if err := p.conn.Drain(); err != nil {
+		p.conn.Close()
+		return fmt.Errorf("failed to drain NATS connection: %w", err)
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		p.conn.Close()
+		return fmt.Errorf("timed out draining NATS connection: %w", ctx.Err())
+	}
+}
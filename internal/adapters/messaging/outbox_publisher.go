@@ -0,0 +1,162 @@
+package messaging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+)
+
+const outboxFileSuffix = ".jsonl"
+
+type outboxRecord struct {
+	Topic     string         `json:"topic"`
+	Key       []byte         `json:"key"`
+	Value     []byte         `json:"value"`
+	Headers   []ports.Header `json:"headers,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// OutboxPublisher implements ports.EventPublisher by appending messages to
+// a local JSON-lines file. It is used as a fallback while the broker is
+// unavailable; Replay forwards the stored messages once it is back.
+type OutboxPublisher struct {
+	dir string
+
+	mu   sync.Mutex
+	file *os.File
+	path string
+}
+
+func NewOutboxPublisher(dir string) (*OutboxPublisher, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &OutboxPublisher{dir: dir}, nil
+}
+
+func (o *OutboxPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	line, err := json.Marshal(outboxRecord{Topic: topic, Key: key, Value: value, Headers: headers, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox record: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		o.path = filepath.Join(o.dir, fmt.Sprintf("%020d%s", time.Now().UnixNano(), outboxFileSuffix))
+		file, err := os.OpenFile(o.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open outbox file: %w", err)
+		}
+		o.file = file
+	}
+
+	if _, err := o.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write outbox record: %w", err)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync outbox file: %w", err)
+	}
+	logger.Warnf(ctx, "Stored message for topic %s in outbox %s", topic, o.path)
+	return nil
+}
+
+// Replay publishes every stored message to target in order and deletes
+// each outbox file once all of its messages have been delivered. It stops
+// at the first failure so no message is skipped.
+func (o *OutboxPublisher) Replay(ctx context.Context, target ports.EventPublisher) (int, error) {
+	// Rotate the current file so writers never append to a file being
+	// replayed; files created after the cutoff are left for the next run.
+	o.mu.Lock()
+	if o.file != nil {
+		o.file.Close()
+		o.file = nil
+	}
+	cutoff := fmt.Sprintf("%020d", time.Now().UnixNano())
+	o.mu.Unlock()
+
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), outboxFileSuffix) && entry.Name() < cutoff {
+			paths = append(paths, filepath.Join(o.dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	replayed := 0
+	for _, path := range paths {
+		n, err := replayFile(ctx, path, target)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+		if err := os.Remove(path); err != nil {
+			return replayed, fmt.Errorf("failed to remove replayed outbox file: %w", err)
+		}
+	}
+	return replayed, nil
+}
+
+// replayFile delivers one outbox file. A partially replayed file is
+// rewritten with only the undelivered records.
+func replayFile(ctx context.Context, path string, target ports.EventPublisher) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read outbox file: %w", err)
+	}
+
+	for i, line := range lines {
+		var record outboxRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			logger.Errorf(ctx, err, "Skipping corrupt outbox record in %s", path)
+			continue
+		}
+		if err := target.Publish(ctx, record.Topic, record.Key, record.Value, record.Headers...); err != nil {
+			remaining := append(bytes.Join(lines[i:], []byte("\n")), '\n')
+			if writeErr := os.WriteFile(path, remaining, 0o644); writeErr != nil {
+				return i, fmt.Errorf("failed to rewrite outbox file: %w", writeErr)
+			}
+			return i, fmt.Errorf("failed to replay outbox record: %w", err)
+		}
+	}
+	return len(lines), nil
+}
+
+// Close closes the current outbox file.
+func (o *OutboxPublisher) Close(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
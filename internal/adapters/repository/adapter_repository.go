@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// AdapterRepository implements ports.AdapterRepository using the adapters
+// table.
+type AdapterRepository struct {
+	db *gorm.DB
+}
+
+func NewAdapterRepository(db *gorm.DB) ports.AdapterRepository {
+	return &AdapterRepository{db: db}
+}
+
+func (r *AdapterRepository) Create(ctx context.Context, adapter *ports.Adapter) error {
+	if err := r.db.WithContext(ctx).Create(adapter).Error; err != nil {
+		if isUniqueViolation(err) {
+			return appError.ErrDuplicateAdapter
+		}
+		return appError.NewCustomError(500, appError.ErrFailedToSaveAdapter.Code, appError.ErrFailedToSaveAdapter.Message, err.Error())
+	}
+	return nil
+}
+
+func (r *AdapterRepository) GetByID(ctx context.Context, id string) (*ports.Adapter, error) {
+	var adapter ports.Adapter
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&adapter).Error; err != nil {
+		return nil, translateAdapterGetError(err)
+	}
+	return &adapter, nil
+}
+
+func (r *AdapterRepository) GetByBppID(ctx context.Context, bppID string) (*ports.Adapter, error) {
+	var adapter ports.Adapter
+	if err := r.db.WithContext(ctx).Where("bpp_id = ?", bppID).First(&adapter).Error; err != nil {
+		return nil, translateAdapterGetError(err)
+	}
+	return &adapter, nil
+}
+
+func (r *AdapterRepository) List(ctx context.Context) ([]ports.Adapter, error) {
+	var adapters []ports.Adapter
+	if err := r.db.WithContext(ctx).Order("name").Find(&adapters).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrFailedToGetAdapter.Code, appError.ErrFailedToGetAdapter.Message, err.Error())
+	}
+	return adapters, nil
+}
+
+func (r *AdapterRepository) Update(ctx context.Context, adapter *ports.Adapter) error {
+	return r.update(ctx, adapter.ID, map[string]any{
+		"name":         adapter.Name,
+		"url":          adapter.URL,
+		"callback_url": adapter.CallbackURL,
+		"api_key":      adapter.APIKey,
+		"bpp_id":       adapter.BppID,
+		"domains":      adapter.Domains,
+		"cities":       adapter.Cities,
+		"categories":   adapter.Categories,
+		"area_codes":   adapter.AreaCodes,
+		"is_active":    adapter.IsActive,
+	})
+}
+
+func (r *AdapterRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&ports.Adapter{})
+	if result.Error != nil {
+		return appError.NewCustomError(500, appError.ErrFailedToDeleteAdapter.Code, appError.ErrFailedToDeleteAdapter.Message, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return appError.ErrAdapterNotFound
+	}
+	return nil
+}
+
+func (r *AdapterRepository) UpdateHealth(ctx context.Context, id, status, healthError string, checkedAt time.Time) error {
+	// Health probes must not bump updated_at, which tracks admin edits
+	result := r.db.WithContext(ctx).Model(&ports.Adapter{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"health_status":     status,
+		"health_error":      healthError,
+		"health_checked_at": checkedAt,
+	})
+	if result.Error != nil {
+		return appError.NewCustomError(500, appError.ErrFailedToSaveAdapter.Code, appError.ErrFailedToSaveAdapter.Message, result.Error.Error())
+	}
+	return nil
+}
+
+func (r *AdapterRepository) update(ctx context.Context, id string, fields map[string]any) error {
+	fields["updated_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	result := r.db.WithContext(ctx).Model(&ports.Adapter{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			return appError.ErrDuplicateAdapter
+		}
+		return appError.NewCustomError(500, appError.ErrFailedToSaveAdapter.Code, appError.ErrFailedToSaveAdapter.Message, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return appError.ErrAdapterNotFound
+	}
+	return nil
+}
+
+func translateAdapterGetError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return appError.ErrAdapterNotFound
+	}
+	return appError.NewCustomError(500, appError.ErrFailedToGetAdapter.Code, appError.ErrFailedToGetAdapter.Message, err.Error())
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// AggregateRepository implements ports.AggregateRepository using the
+// catalog_aggregates table.
+type AggregateRepository struct {
+	db *gorm.DB
+}
+
+func NewAggregateRepository(db *gorm.DB) ports.AggregateRepository {
+	return &AggregateRepository{db: db}
+}
+
+func (r *AggregateRepository) AddPart(ctx context.Context, aggregate *ports.CatalogAggregate) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "transaction_id"}, {Name: "message_id"}, {Name: "bpp_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"parts":      gorm.Expr("catalog_aggregates.parts + 1"),
+			"late_parts": gorm.Expr("catalog_aggregates.late_parts + CASE WHEN catalog_aggregates.status = ? THEN 0 ELSE 1 END", ports.AggregateStatusOpen),
+			"updated_at": aggregate.UpdatedAt,
+		}),
+	}).Create(aggregate).Error
+	if err != nil {
+		return fmt.Errorf("failed to save catalog aggregate: %w", err)
+	}
+	return nil
+}
+
+func (r *AggregateRepository) ClaimDue(ctx context.Context, now, staleClaim time.Time, limit int) ([]ports.CatalogAggregate, error) {
+	// SKIP LOCKED lets several instances claim disjoint batches
+	var aggregates []ports.CatalogAggregate
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE catalog_aggregates SET status = ?, updated_at = ?
+		WHERE (transaction_id, message_id, bpp_id) IN (
+			SELECT transaction_id, message_id, bpp_id FROM catalog_aggregates
+			WHERE (status = ? AND closes_at <= ?) OR (status = ? AND updated_at < ?)
+			ORDER BY closes_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		ports.AggregateStatusClosing, now,
+		ports.AggregateStatusOpen, now, ports.AggregateStatusClosing, staleClaim,
+		limit,
+	).Scan(&aggregates).Error
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return aggregates, nil
+}
+
+func (r *AggregateRepository) Finish(ctx context.Context, aggregate *ports.CatalogAggregate) error {
+	err := r.db.WithContext(ctx).Model(&ports.CatalogAggregate{}).
+		Where("transaction_id = ? AND message_id = ? AND bpp_id = ?", aggregate.TransactionID, aggregate.MessageID, aggregate.BppID).
+		Updates(map[string]any{
+			"status":       aggregate.Status,
+			"bucket":       aggregate.Bucket,
+			"object_key":   aggregate.ObjectKey,
+			"error":        aggregate.Error,
+			"completed_at": aggregate.CompletedAt,
+			"updated_at":   time.Now().UTC(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to finish catalog aggregate: %w", err)
+	}
+	return nil
+}
+
+func (r *AggregateRepository) ListByTransactionID(ctx context.Context, transactionID string) ([]ports.CatalogAggregate, error) {
+	var aggregates []ports.CatalogAggregate
+	if err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("first_part_at, bpp_id").Find(&aggregates).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return aggregates, nil
+}
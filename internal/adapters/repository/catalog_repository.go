@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// catalogItemBatchSize keeps each insert well below the Postgres parameter
+// limit.
+const catalogItemBatchSize = 500
+
+// catalogProviderScanSize bounds the providers loaded at once by
+// ScanProviders.
+const catalogProviderScanSize = 200
+
+// CatalogRepository implements ports.CatalogRepository using the
+// catalog_providers and catalog_items tables.
+type CatalogRepository struct {
+	db *gorm.DB
+}
+
+func NewCatalogRepository(db *gorm.DB) ports.CatalogRepository {
+	return &CatalogRepository{db: db}
+}
+
+func (r *CatalogRepository) SaveProvider(ctx context.Context, provider *ports.CatalogProvider, items []ports.CatalogItem, replace bool) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bpp_id"}, {Name: "provider_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"domain", "city", "bpp_uri", "core_version", "name", "bpp_descriptor",
+				"bpp_fulfillments", "provider", "source_id", "indexed_at",
+			}),
+		}).Create(provider).Error
+		if err != nil {
+			return err
+		}
+
+		if replace {
+			err := tx.Where("bpp_id = ? AND provider_id = ?", provider.BppID, provider.ProviderID).Delete(&ports.CatalogItem{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(items) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "bpp_id"}, {Name: "provider_id"}, {Name: "item_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"name", "category_ids", "item", "indexed_at"}),
+			}).CreateInBatches(items, catalogItemBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// item_count follows whatever the merge left behind
+		return tx.Model(&ports.CatalogProvider{}).
+			Where("bpp_id = ? AND provider_id = ?", provider.BppID, provider.ProviderID).
+			UpdateColumn("item_count", tx.Model(&ports.CatalogItem{}).Select("COUNT(*)").
+				Where("bpp_id = ? AND provider_id = ?", provider.BppID, provider.ProviderID)).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index provider %s of %s: %w", provider.ProviderID, provider.BppID, err)
+	}
+	return nil
+}
+
+func (r *CatalogRepository) ListProviders(ctx context.Context, bppID, domain, city, providerID string) ([]ports.CatalogProvider, error) {
+	query := r.db.WithContext(ctx).Where("bpp_id = ? AND domain = ?", bppID, domain)
+	if city != "" {
+		query = query.Where("city IN ?", []string{city, "*"})
+	}
+	if providerID != "" {
+		query = query.Where("provider_id = ?", providerID)
+	}
+
+	var providers []ports.CatalogProvider
+	if err := query.Order("provider_id").Find(&providers).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return providers, nil
+}
+
+func (r *CatalogRepository) SearchItems(ctx context.Context, q ports.CatalogItemQuery) ([]ports.CatalogItem, error) {
+	query := r.db.WithContext(ctx).Where("bpp_id = ?", q.BppID)
+	if len(q.ProviderIDs) > 0 {
+		query = query.Where("provider_id IN ?", q.ProviderIDs)
+	}
+	if q.Text != "" {
+		query = query.Where("to_tsvector('simple', name) @@ plainto_tsquery('simple', ?)", q.Text)
+	}
+	if q.CategoryID != "" {
+		query = query.Where("(',' || category_ids || ',') LIKE ?", "%,"+q.CategoryID+",%")
+	}
+
+	var items []ports.CatalogItem
+	if err := query.Order("provider_id, item_id").Find(&items).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return items, nil
+}
+
+func (r *CatalogRepository) ScanProviders(ctx context.Context, fn func([]ports.CatalogProvider) error) error {
+	// The key is composite, so batches page on (bpp_id, provider_id)
+	lastBpp, lastProvider := "", ""
+	for {
+		var batch []ports.CatalogProvider
+		err := r.db.WithContext(ctx).
+			Where("(bpp_id, provider_id) > (?, ?)", lastBpp, lastProvider).
+			Order("bpp_id, provider_id").
+			Limit(catalogProviderScanSize).
+			Find(&batch).Error
+		if err != nil {
+			return appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < catalogProviderScanSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		lastBpp, lastProvider = last.BppID, last.ProviderID
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// CatalogVersionRepository implements ports.CatalogVersionRepository using
+// the catalog_versions table.
+type CatalogVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewCatalogVersionRepository(db *gorm.DB) ports.CatalogVersionRepository {
+	return &CatalogVersionRepository{db: db}
+}
+
+func (r *CatalogVersionRepository) Create(ctx context.Context, version *ports.CatalogVersion) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(version).Error; err != nil {
+		return fmt.Errorf("failed to save catalog version: %w", err)
+	}
+	return nil
+}
+
+func (r *CatalogVersionRepository) LatestFull(ctx context.Context, bppID, providerID string) (*ports.CatalogVersion, error) {
+	var versions []ports.CatalogVersion
+	err := r.db.WithContext(ctx).
+		Where("bpp_id = ? AND provider_id = ? AND mode = ? AND base_version_id = ''", bppID, providerID, ports.CatalogModeFull).
+		Order("received_at DESC").
+		Limit(1).
+		Find(&versions).Error
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return &versions[0], nil
+}
+
+func (r *CatalogVersionRepository) ListBasedOn(ctx context.Context, bppID, providerID, baseVersionID string) ([]ports.CatalogVersion, error) {
+	query := r.db.WithContext(ctx).Where("bpp_id = ? AND provider_id = ? AND base_version_id = ?", bppID, providerID, baseVersionID)
+	if baseVersionID == "" {
+		query = query.Where("mode = ?", ports.CatalogModeIncremental)
+	}
+
+	var versions []ports.CatalogVersion
+	if err := query.Order("received_at, id").Find(&versions).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return versions, nil
+}
+
+func (r *CatalogVersionRepository) ListByProvider(ctx context.Context, bppID, providerID string, limit int) ([]ports.CatalogVersion, error) {
+	query := r.db.WithContext(ctx).Where("bpp_id = ? AND provider_id = ?", bppID, providerID).Order("received_at DESC, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var versions []ports.CatalogVersion
+	if err := query.Find(&versions).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return versions, nil
+}
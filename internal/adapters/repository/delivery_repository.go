@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// DeliveryRepository implements ports.DeliveryRepository using the
+// callback_deliveries table.
+type DeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRepository(db *gorm.DB) ports.DeliveryRepository {
+	return &DeliveryRepository{db: db}
+}
+
+func (r *DeliveryRepository) Save(ctx context.Context, delivery *ports.CallbackDelivery) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "attempts", "last_status_code", "last_error", "delivered_at", "updated_at",
+		}),
+	}).Create(delivery).Error
+	if err != nil {
+		return fmt.Errorf("failed to save callback delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *DeliveryRepository) Claim(ctx context.Context, delivery *ports.CallbackDelivery, staleBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim callback delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// The single UPDATE lets only one instance take over an abandoned claim
+	var ids []string
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE callback_deliveries SET status = ?, attempts = 0, last_error = '', updated_at = ?
+		WHERE dispatch_key = ? AND (status = ? OR (status IN (?, ?) AND updated_at < ?))
+		RETURNING id`,
+		ports.DeliveryStatusPending, delivery.UpdatedAt,
+		delivery.DispatchKey, ports.DeliveryStatusFailed, ports.DeliveryStatusPending, ports.DeliveryStatusRetrying, staleBefore,
+	).Scan(&ids).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to take over callback delivery: %w", err)
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	delivery.ID = ids[0]
+	return true, nil
+}
+
+func (r *DeliveryRepository) ListByMessageID(ctx context.Context, messageID string) ([]ports.CallbackDelivery, error) {
+	var deliveries []ports.CallbackDelivery
+	if err := r.db.WithContext(ctx).Where("message_id = ?", messageID).Order("created_at, id").Find(&deliveries).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"adapter/internal/ports"
+)
+
+// RateLimitPolicyRepository implements ports.RateLimitPolicyStore using
+// the rate_limit_policies table.
+type RateLimitPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitPolicyRepository(db *gorm.DB) ports.RateLimitPolicyStore {
+	return &RateLimitPolicyRepository{db: db}
+}
+
+func (r *RateLimitPolicyRepository) GetPolicy(ctx context.Context, subscriberID string) (*ports.RateLimitPolicy, error) {
+	var policy ports.RateLimitPolicy
+	err := r.db.WithContext(ctx).Where("subscriber_id = ?", subscriberID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// MemoryRateLimitPolicyStore implements ports.RateLimitPolicyStore from a
+// static set of overrides, for single-node deployments without Postgres.
+type MemoryRateLimitPolicyStore struct {
+	policies map[string]ports.RateLimitPolicy
+}
+
+// NewMemoryRateLimitPolicyStore parses overrides of the form
+// "subscriber=rps:burst:bytesPerMinute,subscriber2=...".
+func NewMemoryRateLimitPolicyStore(spec string) (ports.RateLimitPolicyStore, error) {
+	policies := make(map[string]ports.RateLimitPolicy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		subscriber, limits, ok := strings.Cut(entry, "=")
+		parts := strings.Split(limits, ":")
+		if !ok || subscriber == "" || len(parts) != 3 {
+			return nil, fmt.Errorf("invalid rate limit override %q, expected subscriber=rps:burst:bytesPerMinute", entry)
+		}
+
+		rps, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requests per second in %q: %w", entry, err)
+		}
+		burst, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid burst in %q: %w", entry, err)
+		}
+		bytesPerMinute, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes per minute in %q: %w", entry, err)
+		}
+
+		policies[subscriber] = ports.RateLimitPolicy{
+			SubscriberID:      subscriber,
+			RequestsPerSecond: rps,
+			Burst:             burst,
+			BytesPerMinute:    bytesPerMinute,
+		}
+	}
+	return &MemoryRateLimitPolicyStore{policies: policies}, nil
+}
+
+func (s *MemoryRateLimitPolicyStore) GetPolicy(ctx context.Context, subscriberID string) (*ports.RateLimitPolicy, error) {
+	policy, ok := s.policies[subscriberID]
+	if !ok {
+		return nil, nil
+	}
+	return &policy, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// ScheduleRepository implements ports.ScheduleRepository using the
+// location_schedules table.
+type ScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) ports.ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+func (r *ScheduleRepository) SaveProvider(ctx context.Context, bppID, providerID string, schedules []ports.LocationSchedule) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("bpp_id = ? AND provider_id = ?", bppID, providerID).Delete(&ports.LocationSchedule{}).Error
+		if err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			return nil
+		}
+		return tx.Create(&schedules).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save schedules of provider %s of %s: %w", providerID, bppID, err)
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) ListByProvider(ctx context.Context, providerID, bppID string) ([]ports.LocationSchedule, error) {
+	query := r.db.WithContext(ctx).Where("provider_id = ?", providerID)
+	if bppID != "" {
+		query = query.Where("bpp_id = ?", bppID)
+	}
+
+	var schedules []ports.LocationSchedule
+	if err := query.Order("bpp_id, location_id").Find(&schedules).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return schedules, nil
+}
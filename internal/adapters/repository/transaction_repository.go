@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// TransactionRepository implements ports.TransactionRepository using the
+// transactions table.
+type TransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) ports.TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+func (r *TransactionRepository) Save(ctx context.Context, record *ports.Transaction) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"bucket", "object_key", "size_bytes", "status", "error", "out_of_order", "violation",
+			"context_timestamp", "ttl", "late", "timing_violation", "catalog_mode", "updated_at",
+		}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("failed to save transaction record: %w", err)
+	}
+	return nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*ports.Transaction, error) {
+	var record ports.Transaction
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appError.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return &record, nil
+}
+
+func (r *TransactionRepository) ListByTransactionID(ctx context.Context, transactionID, bppID string) ([]ports.Transaction, error) {
+	query := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID)
+	if bppID != "" {
+		query = query.Where("bpp_id = ?", bppID)
+	}
+
+	var records []ports.Transaction
+	if err := query.Order("received_at, id").Find(&records).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return records, nil
+}
+
+func (r *TransactionRepository) FindRequest(ctx context.Context, transactionID, messageID, action string) (*ports.Transaction, error) {
+	var records []ports.Transaction
+	err := r.db.WithContext(ctx).
+		Where("transaction_id = ? AND message_id = ? AND action = ?", transactionID, messageID, action).
+		Order("received_at").
+		Limit(1).
+		Find(&records).Error
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"adapter/internal/ports"
+)
+
+// TransactionStateRepository implements ports.TransactionStateRepository
+// using the transaction_states table with optimistic locking on version.
+type TransactionStateRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionStateRepository(db *gorm.DB) ports.TransactionStateRepository {
+	return &TransactionStateRepository{db: db}
+}
+
+func (r *TransactionStateRepository) Get(ctx context.Context, transactionID string) (*ports.TransactionState, error) {
+	var state ports.TransactionState
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *TransactionStateRepository) Save(ctx context.Context, state *ports.TransactionState) error {
+	if state.Version <= 1 {
+		if err := r.db.WithContext(ctx).Create(state).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ports.ErrStateConflict
+			}
+			return fmt.Errorf("failed to create transaction state: %w", err)
+		}
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&ports.TransactionState{}).
+		Where("transaction_id = ? AND version = ?", state.TransactionID, state.Version-1).
+		Updates(map[string]any{
+			"bap_id":      state.BapID,
+			"bpp_id":      state.BppID,
+			"stage":       state.Stage,
+			"last_action": state.LastAction,
+			"actions":     state.Actions,
+			"order_state": state.OrderState,
+			"violations":  state.Violations,
+			"version":     state.Version,
+			"updated_at":  state.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update transaction state: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ports.ErrStateConflict
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+const pgUniqueViolation = "23505"
+
+// UserRepository implements ports.UserRepository using GORM on Postgres.
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) ports.UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *ports.User) error {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		if isUniqueViolation(err) {
+			return appError.ErrDuplicateUser
+		}
+		return appError.NewCustomError(500, appError.ErrFailedToCreateUser.Code, appError.ErrFailedToCreateUser.Message, err.Error())
+	}
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*ports.User, error) {
+	var user ports.User
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translateGetError(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*ports.User, error) {
+	var user ports.User
+	if err := r.db.WithContext(ctx).Where("api_key = ?", hash).First(&user).Error; err != nil {
+		return nil, translateGetError(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]ports.User, error) {
+	var users []ports.User
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&users).Error; err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrFailedToGetUser.Code, appError.ErrFailedToGetUser.Message, err.Error())
+	}
+	return users, nil
+}
+
+func (r *UserRepository) UpdateAPIKeyHash(ctx context.Context, id, hash string) error {
+	return r.update(ctx, id, map[string]any{"api_key": hash})
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, id string, active bool) error {
+	return r.update(ctx, id, map[string]any{"is_active": active})
+}
+
+func (r *UserRepository) update(ctx context.Context, id string, fields map[string]any) error {
+	fields["updated_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	result := r.db.WithContext(ctx).Model(&ports.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return appError.NewCustomError(500, appError.ErrFailedToUpdateUser.Code, appError.ErrFailedToUpdateUser.Message, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return appError.ErrUserNotFound
+	}
+	return nil
+}
+
+func translateGetError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return appError.ErrUserNotFound
+	}
+	return appError.NewCustomError(500, appError.ErrFailedToGetUser.Code, appError.ErrFailedToGetUser.Message, err.Error())
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case stateOpen:
+		return "open"
+	case stateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitOpenError is returned without calling the dependency while its
+// circuit is open.
+type CircuitOpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit breaker is open, retry after %s", e.Name, e.RetryAfter.Round(time.Second))
+}
+
+// CircuitBreaker opens after a run of consecutive failures, rejects calls
+// while open, and lets a single trial call through once the open period
+// has elapsed.
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	openFor   time.Duration
+
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	trialBusy bool
+}
+
+// NewCircuitBreaker returns a closed breaker. A threshold of zero disables it.
+func NewCircuitBreaker(name string, threshold int, openFor time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{name: name, threshold: threshold, openFor: openFor}
+}
+
+// Allow reports whether a call may proceed.
+func (b *CircuitBreaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		elapsed := time.Since(b.openedAt)
+		if elapsed < b.openFor {
+			return &CircuitOpenError{Name: b.name, RetryAfter: b.openFor - elapsed}
+		}
+		b.state = stateHalfOpen
+		b.trialBusy = true
+		return nil
+	case stateHalfOpen:
+		if b.trialBusy {
+			return &CircuitOpenError{Name: b.name, RetryAfter: b.openFor}
+		}
+		b.trialBusy = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the circuit.
+func (b *CircuitBreaker) Success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = stateClosed
+	b.failures = 0
+	b.trialBusy = false
+}
+
+// Failure records a failed call and opens the circuit once the threshold
+// is reached or a half-open trial fails.
+func (b *CircuitBreaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialBusy = false
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the breaker state for diagnostics.
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
@@ -0,0 +1,148 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Classifier reports whether an error is transient and worth retrying.
+type Classifier func(err error) bool
+
+// Policy configures retries and circuit breaking for one dependency.
+type Policy struct {
+	// Name identifies the dependency in logs and errors.
+	Name string
+	// MaxAttempts includes the first call.
+	MaxAttempts int
+	// BackoffBase is the delay before the second attempt.
+	BackoffBase time.Duration
+	// BackoffMax caps the exponential delay.
+	BackoffMax time.Duration
+	// AttemptTimeout bounds each individual attempt; zero disables it.
+	AttemptTimeout time.Duration
+	// MaxElapsed bounds a whole call, attempts and backoff included; zero
+	// leaves only the caller's deadline. No retry is started that the
+	// budget or the deadline cannot wait for.
+	MaxElapsed time.Duration
+	// BreakerFailures is the number of consecutive failed calls that opens
+	// the circuit; zero disables the breaker.
+	BreakerFailures int
+	// BreakerOpen is how long the circuit stays open before a trial call.
+	BreakerOpen time.Duration
+	// Retryable classifies errors. Nil treats every error as retryable.
+	Retryable Classifier
+}
+
+// PermanentError marks an error that must not be retried.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// executor runs operations under a Policy.
+type executor struct {
+	policy  Policy
+	breaker *CircuitBreaker
+}
+
+func newExecutor(policy Policy) *executor {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	return &executor{
+		policy:  policy,
+		breaker: NewCircuitBreaker(policy.Name, policy.BreakerFailures, policy.BreakerOpen),
+	}
+}
+
+// do runs op with retries. The breaker counts the whole call, not each
+// attempt, so one slow request does not trip it on its own.
+func (e *executor) do(ctx context.Context, op func(ctx context.Context) error) error {
+	if err := e.breaker.Allow(); err != nil {
+		return err
+	}
+	if e.policy.MaxElapsed > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.policy.MaxElapsed)
+		defer cancel()
+	}
+
+	var err error
+	for attempt := 1; attempt <= e.policy.MaxAttempts; attempt++ {
+		err = e.attempt(ctx, op)
+		if err == nil {
+			e.breaker.Success()
+			return nil
+		}
+		if ctx.Err() != nil {
+			// The call budget or the caller's deadline ran out during the
+			// attempt, which is how a hung dependency fails.
+			e.breaker.Failure()
+			return fmt.Errorf("%s: %w (last error: %v)", e.policy.Name, ctx.Err(), err)
+		}
+		if !e.retryable(err) {
+			// Permanent errors say nothing about the dependency's health.
+			e.breaker.Success()
+			return &PermanentError{Err: err}
+		}
+		if attempt == e.policy.MaxAttempts {
+			break
+		}
+
+		delay := e.delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			// Sleeping would leave no time for another attempt.
+			e.breaker.Failure()
+			return fmt.Errorf("%s: retry budget exhausted after %d attempts: %w", e.policy.Name, attempt, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			e.breaker.Failure()
+			return fmt.Errorf("%s: %w (last error: %v)", e.policy.Name, ctx.Err(), err)
+		}
+	}
+
+	e.breaker.Failure()
+	return fmt.Errorf("%s failed after %d attempts: %w", e.policy.Name, e.policy.MaxAttempts, err)
+}
+
+func (e *executor) attempt(ctx context.Context, op func(ctx context.Context) error) error {
+	if e.policy.AttemptTimeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.policy.AttemptTimeout)
+	defer cancel()
+	return op(ctx)
+}
+
+func (e *executor) retryable(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	if e.policy.Retryable == nil {
+		return true
+	}
+	return e.policy.Retryable(err)
+}
+
+// delay returns the jittered exponential backoff before the next attempt.
+func (e *executor) delay(attempt int) time.Duration {
+	delay := e.policy.BackoffBase << (attempt - 1)
+	if delay <= 0 || (e.policy.BackoffMax > 0 && delay > e.policy.BackoffMax) {
+		delay = e.policy.BackoffMax
+	}
+	// Full jitter keeps concurrent retries from synchronising.
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
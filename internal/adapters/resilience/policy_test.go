@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"adapter/internal/ports"
+)
+
+var errRejected = errors.New("rejected")
+
+// hang blocks like a dependency that never answers.
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func testPolicy() Policy {
+	return Policy{
+		Name:            "test",
+		MaxAttempts:     3,
+		BackoffBase:     time.Millisecond,
+		BackoffMax:      5 * time.Millisecond,
+		AttemptTimeout:  20 * time.Millisecond,
+		MaxElapsed:      50 * time.Millisecond,
+		BreakerFailures: 1,
+		BreakerOpen:     time.Minute,
+		Retryable:       func(err error) bool { return !errors.Is(err, errRejected) },
+	}
+}
+
+func TestExecutorDo(t *testing.T) {
+	tests := []struct {
+		name          string
+		op            func(ctx context.Context) error
+		callerTimeout time.Duration
+		wantPermanent bool
+		wantDeadline  bool
+		wantBreaker   string
+	}{
+		{name: "success", op: func(ctx context.Context) error { return nil }, wantBreaker: "closed"},
+		{name: "hung dependency exhausts the budget", op: hang, wantDeadline: true, wantBreaker: "open"},
+		{name: "hung dependency outlives the caller", op: hang, callerTimeout: 10 * time.Millisecond, wantDeadline: true, wantBreaker: "open"},
+		{name: "transient errors", op: func(ctx context.Context) error { return errors.New("connection reset") }, wantBreaker: "open"},
+		{name: "rejected error is permanent", op: func(ctx context.Context) error { return errRejected }, wantPermanent: true, wantBreaker: "closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newExecutor(testPolicy())
+			ctx := context.Background()
+			if tt.callerTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerTimeout)
+				defer cancel()
+			}
+
+			err := exec.do(ctx, tt.op)
+			var permanent *PermanentError
+			if errors.As(err, &permanent) != tt.wantPermanent {
+				t.Errorf("err = %v, permanent %v", err, tt.wantPermanent)
+			}
+			if errors.Is(err, context.DeadlineExceeded) != tt.wantDeadline {
+				t.Errorf("err = %v, deadline exceeded %v", err, tt.wantDeadline)
+			}
+			if state := exec.breaker.State(); state != tt.wantBreaker {
+				t.Errorf("breaker is %s, want %s", state, tt.wantBreaker)
+			}
+		})
+	}
+}
+
+func TestExecutorHalfOpenTrialTimesOut(t *testing.T) {
+	policy := testPolicy()
+	policy.BreakerOpen = time.Millisecond
+	exec := newExecutor(policy)
+
+	exec.breaker.Failure()
+	time.Sleep(5 * time.Millisecond)
+	if err := exec.do(context.Background(), hang); err == nil {
+		t.Fatal("hung trial call succeeded")
+	}
+	if state := exec.breaker.State(); state != "open" {
+		t.Fatalf("breaker is %s after a timed out trial, want open", state)
+	}
+}
+
+// recordingPublisher is a ports.EventPublisher that records the values op
+// lets through.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	op     func(ctx context.Context) error
+	values []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	if p.op != nil {
+		if err := p.op(ctx); err != nil {
+			return err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values = append(p.values, string(value))
+	return nil
+}
+
+func TestResilientPublisherFallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		op           func(ctx context.Context) error
+		wantErr      bool
+		wantFallback bool
+	}{
+		{name: "hung broker uses the fallback", op: hang, wantFallback: true},
+		{name: "transient errors use the fallback", op: func(ctx context.Context) error { return errors.New("broker down") }, wantFallback: true},
+		{name: "rejected message is not retried elsewhere", op: func(ctx context.Context) error { return errRejected }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &recordingPublisher{}
+			publisher := NewResilientPublisher(&recordingPublisher{op: tt.op}, fallback, testPolicy())
+
+			err := publisher.Publish(context.Background(), "on_search", []byte("key"), []byte("pointer"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(fallback.values) == 1; got != tt.wantFallback {
+				t.Fatalf("fallback received %q, want fallback %v", fallback.values, tt.wantFallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+)
+
+// ResilientPublisher decorates a ports.EventPublisher with retries and a
+// circuit breaker. When the primary cannot take a message and a fallback
+// is configured, the message is handed to the fallback instead of being
+// dropped.
+type ResilientPublisher struct {
+	inner    ports.EventPublisher
+	fallback ports.EventPublisher
+	exec     *executor
+}
+
+// NewResilientPublisher wraps inner. fallback may be nil.
+func NewResilientPublisher(inner ports.EventPublisher, fallback ports.EventPublisher, policy Policy) ports.EventPublisher {
+	return &ResilientPublisher{inner: inner, fallback: fallback, exec: newExecutor(policy)}
+}
+
+func (p *ResilientPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	err := p.exec.do(ctx, func(ctx context.Context) error {
+		return p.inner.Publish(ctx, topic, key, value, headers...)
+	})
+	if err == nil {
+		return nil
+	}
+
+	var permanent *PermanentError
+	if p.fallback == nil || errors.As(err, &permanent) {
+		return err
+	}
+
+	logger.Warnf(ctx, "Publishing to %s failed, using fallback: %v", topic, err)
+	if fbErr := p.fallback.Publish(ctx, topic, key, value, headers...); fbErr != nil {
+		return fmt.Errorf("fallback publish failed: %v (primary error: %w)", fbErr, err)
+	}
+	return nil
+}
+
+// HealthCheck delegates to the wrapped adapter when it supports probing.
+func (p *ResilientPublisher) HealthCheck(ctx context.Context) error {
+	if checker, ok := p.inner.(ports.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// Close closes the primary, then the fallback.
+func (p *ResilientPublisher) Close(ctx context.Context) error {
+	var errs []error
+	for _, publisher := range []ports.EventPublisher{p.inner, p.fallback} {
+		if closer, ok := publisher.(ports.Closer); ok {
+			errs = append(errs, closer.Close(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Breaker exposes the circuit state for diagnostics.
+func (p *ResilientPublisher) Breaker() *CircuitBreaker {
+	return p.exec.breaker
+}
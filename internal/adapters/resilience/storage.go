@@ -0,0 +1,71 @@
+package resilience
+
+import (
+	"context"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+)
+
+// ResilientStorage decorates a ports.ObjectStorage with retries and a
+// circuit breaker. While the circuit is open calls fail fast with a
+// *CircuitOpenError instead of tying up request goroutines.
+type ResilientStorage struct {
+	inner ports.ObjectStorage
+	exec  *executor
+}
+
+func NewResilientStorage(inner ports.ObjectStorage, policy Policy) ports.ObjectStorage {
+	return &ResilientStorage{inner: inner, exec: newExecutor(policy)}
+}
+
+func (s *ResilientStorage) Upload(ctx context.Context, objectName string, data []byte, contentType string) (string, error) {
+	var key string
+	err := s.exec.do(ctx, func(ctx context.Context) error {
+		var err error
+		key, err = s.inner.Upload(ctx, objectName, data, contentType)
+		if err != nil {
+			logger.Warnf(ctx, "Upload of %s failed: %v", objectName, err)
+		}
+		return err
+	})
+	return key, err
+}
+
+func (s *ResilientStorage) Download(ctx context.Context, objectName string) ([]byte, error) {
+	var data []byte
+	err := s.exec.do(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = s.inner.Download(ctx, objectName)
+		if err != nil {
+			logger.Warnf(ctx, "Download of %s failed: %v", objectName, err)
+		}
+		return err
+	})
+	return data, err
+}
+
+func (s *ResilientStorage) GetBucket() string {
+	return s.inner.GetBucket()
+}
+
+// HealthCheck delegates to the wrapped adapter when it supports probing.
+func (s *ResilientStorage) HealthCheck(ctx context.Context) error {
+	if checker, ok := s.inner.(ports.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// Close delegates to the wrapped adapter when it holds resources.
+func (s *ResilientStorage) Close(ctx context.Context) error {
+	if closer, ok := s.inner.(ports.Closer); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+// Breaker exposes the circuit state for diagnostics.
+func (s *ResilientStorage) Breaker() *CircuitBreaker {
+	return s.exec.breaker
+}
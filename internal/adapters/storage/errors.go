@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// permanentS3Codes are S3 error codes that retrying cannot fix.
+var permanentS3Codes = map[string]bool{
+	"AccessDenied":            true,
+	"InvalidAccessKeyId":      true,
+	"SignatureDoesNotMatch":   true,
+	"NoSuchBucket":            true,
+	"NoSuchKey":               true,
+	"InvalidBucketName":       true,
+	"InvalidObjectName":       true,
+	"EntityTooLarge":          true,
+	"XMinioInvalidObjectName": true,
+}
+
+// IsRetryable reports whether a MinIO error is transient. Server-side and
+// throttling responses are retried; authentication, missing bucket and
+// invalid request errors are not.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var resp minio.ErrorResponse
+	if errors.As(err, &resp) {
+		if permanentS3Codes[resp.Code] {
+			return false
+		}
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return true
+		}
+		return resp.Code == "SlowDown" || resp.Code == "RequestTimeout"
+	}
+	return true
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -22,14 +24,21 @@ type MinIOConfig struct {
 
 // MinIOStorage implements ports.ObjectStorage using MinIO.
 type MinIOStorage struct {
-	client *minio.Client
-	cfg    MinIOConfig
+	client    *minio.Client
+	transport *http.Transport
+	cfg       MinIOConfig
 }
 
 func NewMinIOStorage(cfg MinIOConfig) (ports.ObjectStorage, error) {
+	transport, err := minio.DefaultTransport(cfg.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO transport: %w", err)
+	}
+
 	client, err := minio.New(cfg.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
-		Secure: cfg.UseSSL,
+		Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:    cfg.UseSSL,
+		Transport: transport,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to init MinIO client: %w", err)
@@ -49,8 +58,9 @@ func NewMinIOStorage(cfg MinIOConfig) (ports.ObjectStorage, error) {
 	}
 
 	return &MinIOStorage{
-		client: client,
-		cfg:    cfg,
+		client:    client,
+		transport: transport,
+		cfg:       cfg,
 	}, nil
 }
 
@@ -68,6 +78,39 @@ func (s *MinIOStorage) Upload(ctx context.Context, objectName string, data []byt
 	return objectName, nil
 }
 
+func (s *MinIOStorage) Download(ctx context.Context, objectName string) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, nil
+}
+
 func (s *MinIOStorage) GetBucket() string {
 	return s.cfg.Bucket
 }
+
+// HealthCheck verifies that MinIO is reachable and the bucket exists.
+func (s *MinIOStorage) HealthCheck(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.cfg.Bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.cfg.Bucket)
+	}
+	return nil
+}
+
+// Close releases idle connections held by the MinIO client. Uploads that
+// are still running keep their connections until they complete.
+func (s *MinIOStorage) Close(ctx context.Context) error {
+	s.transport.CloseIdleConnections()
+	return nil
+}
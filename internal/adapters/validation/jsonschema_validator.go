@@ -18,6 +18,23 @@ var ret11OnSearchSchema []byte
 //go:embed schemas/ret18_search.schema.json
 var ret18SearchSchema []byte
 
+//go:embed schemas/ondc.on_search.pointer.v1.schema.json
+var onSearchPointerEventSchema []byte
+
+//go:embed schemas/ondc.transaction.state_changed.v1.schema.json
+var transactionStateChangedEventSchema []byte
+
+// EventSchemas holds the JSON Schemas of events this service publishes,
+// keyed by CloudEvents type, so they can be served to consumers.
+var EventSchemas = map[string][]byte{
+	"ondc.on_search.pointer.v1":         onSearchPointerEventSchema,
+	"ondc.transaction.state_changed.v1": transactionStateChangedEventSchema,
+}
+
+// eventSchemaDomain is the pseudo-domain under which event schemas are
+// registered in the validator.
+const eventSchemaDomain = "event"
+
 // JSONSchemaValidator implements ports.SchemaValidator using compiled
 // JSON Schemas for different ONDC domains and actions.
 type JSONSchemaValidator struct {
@@ -54,6 +71,19 @@ func NewJSONSchemaValidator() (ports.SchemaValidator, error) {
 	}
 	schemas[schemaKey("ONDC:RET18", "search")] = ret18Schema
 
+	// Register outbound event schemas so they are known to compile
+	for eventType, raw := range EventSchemas {
+		resource := eventType + ".schema.json"
+		if err := compiler.AddResource(resource, strings.NewReader(string(raw))); err != nil {
+			return nil, fmt.Errorf("failed to load %s event schema: %w", eventType, err)
+		}
+		eventSchema, err := compiler.Compile(resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile %s event schema: %w", eventType, err)
+		}
+		schemas[schemaKey(eventSchemaDomain, eventType)] = eventSchema
+	}
+
 	return &JSONSchemaValidator{schemas: schemas}, nil
 }
 
@@ -61,7 +91,7 @@ func (v *JSONSchemaValidator) Validate(ctx context.Context, domain, action strin
 	key := schemaKey(domain, action)
 	schema, exists := v.schemas[key]
 	if !exists {
-		return fmt.Errorf("no schema found for domain=%s, action=%s", domain, action)
+		return fmt.Errorf("%w for domain=%s, action=%s", ports.ErrSchemaNotFound, domain, action)
 	}
 
 	// Unmarshal JSON bytes into interface{} for validation
@@ -75,3 +105,11 @@ func (v *JSONSchemaValidator) Validate(ctx context.Context, domain, action strin
 	}
 	return nil
 }
+
+// HealthCheck reports an error if no schemas were compiled.
+func (v *JSONSchemaValidator) HealthCheck(ctx context.Context) error {
+	if len(v.schemas) == 0 {
+		return fmt.Errorf("no schemas loaded")
+	}
+	return nil
+}
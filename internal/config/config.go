@@ -2,6 +2,11 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Store timezones must resolve on hosts without zoneinfo
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -16,8 +21,200 @@ type Config struct {
 	MinIOSecretKey     string `envconfig:"MINIO_SECRET_KEY" required:"true"`
 	MinIOUseSSL        bool   `envconfig:"MINIO_USE_SSL" default:"false"`
 	MinIOBucket        string `envconfig:"MINIO_BUCKET" default:"ondc-payloads"`
-	KafkaBrokers       string `envconfig:"KAFKA_BROKERS" required:"true"`
+	KafkaBrokers       string `envconfig:"KAFKA_BROKERS"`
 	KafkaOnSearchTopic string `envconfig:"KAFKA_ON_SEARCH_TOPIC" default:"ondc.on_search.pointer"`
+	// KafkaStateTopic receives transaction state-change events.
+	KafkaStateTopic string `envconfig:"KAFKA_STATE_TOPIC" default:"ondc.transaction.state"`
+	// EventSource is the CloudEvents "source" attribute of published events.
+	EventSource string `envconfig:"EVENT_SOURCE" default:"urn:gcr-edge-service"`
+
+	// EventBus selects the pointer event transport: kafka, nats or memory.
+	// KAFKA_BROKERS is only required for kafka; memory is meant for local
+	// development and tests.
+	EventBus string `envconfig:"EVENT_BUS" default:"kafka"`
+
+	// NATS JetStream settings, used when EVENT_BUS=nats. Topics are
+	// published as subjects captured by NATSStream.
+	NATSURL              string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	NATSStream           string `envconfig:"NATS_STREAM" default:"ONDC_EVENTS"`
+	NATSUser             string `envconfig:"NATS_USER"`
+	NATSPassword         string `envconfig:"NATS_PASSWORD"`
+	NATSToken            string `envconfig:"NATS_TOKEN"`
+	NATSCredsFile        string `envconfig:"NATS_CREDS_FILE"`
+	NATSAutoCreateStream bool   `envconfig:"NATS_AUTO_CREATE_STREAM" default:"true"`
+	NATSStreamReplicas   int    `envconfig:"NATS_STREAM_REPLICAS" default:"1"`
+	NATSPublishTimeoutMs int    `envconfig:"NATS_PUBLISH_TIMEOUT_MS" default:"5000"`
+
+	// MemoryBusBuffer is the per-subscriber channel size of the in-memory bus.
+	MemoryBusBuffer int `envconfig:"MEMORY_BUS_BUFFER" default:"1000"`
+
+	// Kafka security. KafkaSASLMechanism is empty, plain, scram-sha-256 or
+	// scram-sha-512.
+	KafkaTLSEnabled            bool   `envconfig:"KAFKA_TLS_ENABLED" default:"false"`
+	KafkaTLSCAFile             string `envconfig:"KAFKA_TLS_CA_FILE"`
+	KafkaTLSCertFile           string `envconfig:"KAFKA_TLS_CERT_FILE"`
+	KafkaTLSKeyFile            string `envconfig:"KAFKA_TLS_KEY_FILE"`
+	KafkaTLSInsecureSkipVerify bool   `envconfig:"KAFKA_TLS_INSECURE_SKIP_VERIFY" default:"false"`
+	KafkaSASLMechanism         string `envconfig:"KAFKA_SASL_MECHANISM"`
+	KafkaSASLUsername          string `envconfig:"KAFKA_SASL_USERNAME"`
+	KafkaSASLPassword          string `envconfig:"KAFKA_SASL_PASSWORD"`
+
+	// Kafka producer tuning
+	KafkaCompression      string `envconfig:"KAFKA_COMPRESSION" default:"snappy"`
+	KafkaBalancer         string `envconfig:"KAFKA_BALANCER" default:"hash"`
+	KafkaBatchSize        int    `envconfig:"KAFKA_BATCH_SIZE" default:"100"`
+	KafkaBatchBytes       int64  `envconfig:"KAFKA_BATCH_BYTES" default:"1048576"`
+	KafkaBatchTimeoutMs   int    `envconfig:"KAFKA_BATCH_TIMEOUT_MS" default:"10"`
+	KafkaAsync            bool   `envconfig:"KAFKA_ASYNC" default:"false"`
+	KafkaAutoCreateTopics bool   `envconfig:"KAFKA_AUTO_CREATE_TOPICS" default:"false"`
+	KafkaTopicPartitions  int    `envconfig:"KAFKA_TOPIC_PARTITIONS" default:"6"`
+	KafkaTopicReplication int    `envconfig:"KAFKA_TOPIC_REPLICATION_FACTOR" default:"1"`
+
+	HealthCheckTimeoutMs int `envconfig:"HEALTH_CHECK_TIMEOUT_MS" default:"2000"`
+
+	// AdminAPIKey protects /admin endpoints. Admin routes are disabled when empty.
+	AdminAPIKey           string `envconfig:"ADMIN_API_KEY"`
+	APIKeyCacheTTLSeconds int    `envconfig:"API_KEY_CACHE_TTL_SECONDS" default:"60"`
+
+	// Per-caller rate limits, keyed by API client, verified subscriber_id
+	// or remote IP. RateLimitPolicySource is "postgres" to read overrides
+	// from rate_limit_policies or "memory" to read them from
+	// RateLimitOverrides ("key=rps:burst:bytesPerMinute,...").
+	// RateLimitMaxKeys bounds the callers tracked in memory.
+	RateLimitEnabled            bool    `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+	RateLimitPolicySource       string  `envconfig:"RATE_LIMIT_POLICY_SOURCE" default:"memory"`
+	RateLimitOverrides          string  `envconfig:"RATE_LIMIT_OVERRIDES"`
+	RateLimitRequestsPerSecond  float64 `envconfig:"RATE_LIMIT_REQUESTS_PER_SECOND" default:"10"`
+	RateLimitBurst              int     `envconfig:"RATE_LIMIT_BURST" default:"20"`
+	RateLimitBytesPerMinute     int64   `envconfig:"RATE_LIMIT_BYTES_PER_MINUTE" default:"524288000"`
+	RateLimitPolicyCacheSeconds int     `envconfig:"RATE_LIMIT_POLICY_CACHE_SECONDS" default:"30"`
+	RateLimitMaxKeys            int     `envconfig:"RATE_LIMIT_MAX_KEYS" default:"100000"`
+
+	// Request body limits apply to the decoded body. Route and domain
+	// overrides use the form "key=bytes,key2=bytes"; a domain override wins
+	// over the route limit.
+	BodyLimitDefaultBytes int    `envconfig:"BODY_LIMIT_DEFAULT_BYTES" default:"4194304"`
+	BodyLimitRoutes       string `envconfig:"BODY_LIMIT_ROUTES" default:"/on-search=52428800"`
+	BodyLimitDomains      string `envconfig:"BODY_LIMIT_DOMAINS"`
+	DecompressMaxBytes    int    `envconfig:"DECOMPRESS_MAX_BYTES" default:"104857600"`
+	DecompressMaxRatio    int    `envconfig:"DECOMPRESS_MAX_RATIO" default:"100"`
+
+	// IngestMode is "sync" to store and publish inside the request, or
+	// "async" to ACK immediately and process on a worker pool.
+	IngestMode              string `envconfig:"INGEST_MODE" default:"sync"`
+	IngestQueueSize         int    `envconfig:"INGEST_QUEUE_SIZE" default:"100"`
+	IngestWorkers           int    `envconfig:"INGEST_WORKERS" default:"4"`
+	IngestSpillDir          string `envconfig:"INGEST_SPILL_DIR" default:"/tmp/gcr-ingest-spill"`
+	IngestSpillMaxBytes     int64  `envconfig:"INGEST_SPILL_MAX_BYTES" default:"1073741824"`
+	IngestMaxAttempts       int    `envconfig:"INGEST_MAX_ATTEMPTS" default:"5"`
+	IngestRetryBaseMs       int    `envconfig:"INGEST_RETRY_BASE_MS" default:"500"`
+	IngestJobTimeoutSeconds int    `envconfig:"INGEST_JOB_TIMEOUT_SECONDS" default:"30"`
+	IngestRetryAfterSeconds int    `envconfig:"INGEST_RETRY_AFTER_SECONDS" default:"5"`
+
+	// Retry and circuit breaker settings for MinIO uploads. A breaker
+	// threshold of 0 disables the breaker. StorageMaxElapsedMs and
+	// PublisherMaxElapsedMs bound each call including retries; together
+	// they must stay below the 30s on_search handler timeout.
+	StorageMaxAttempts        int `envconfig:"STORAGE_MAX_ATTEMPTS" default:"3"`
+	StorageBackoffBaseMs      int `envconfig:"STORAGE_BACKOFF_BASE_MS" default:"200"`
+	StorageBackoffMaxMs       int `envconfig:"STORAGE_BACKOFF_MAX_MS" default:"5000"`
+	StorageAttemptTimeoutMs   int `envconfig:"STORAGE_ATTEMPT_TIMEOUT_MS" default:"10000"`
+	StorageMaxElapsedMs       int `envconfig:"STORAGE_MAX_ELAPSED_MS" default:"12000"`
+	StorageBreakerFailures    int `envconfig:"STORAGE_BREAKER_FAILURES" default:"5"`
+	StorageBreakerOpenSeconds int `envconfig:"STORAGE_BREAKER_OPEN_SECONDS" default:"30"`
+
+	// Retry and circuit breaker settings for Kafka publishing. When
+	// PublisherOutboxDir is set, messages that cannot be published are
+	// written there and replayed once Kafka recovers.
+	PublisherMaxAttempts        int    `envconfig:"PUBLISHER_MAX_ATTEMPTS" default:"3"`
+	PublisherBackoffBaseMs      int    `envconfig:"PUBLISHER_BACKOFF_BASE_MS" default:"200"`
+	PublisherBackoffMaxMs       int    `envconfig:"PUBLISHER_BACKOFF_MAX_MS" default:"5000"`
+	PublisherAttemptTimeoutMs   int    `envconfig:"PUBLISHER_ATTEMPT_TIMEOUT_MS" default:"10000"`
+	PublisherMaxElapsedMs       int    `envconfig:"PUBLISHER_MAX_ELAPSED_MS" default:"12000"`
+	PublisherBreakerFailures    int    `envconfig:"PUBLISHER_BREAKER_FAILURES" default:"5"`
+	PublisherBreakerOpenSeconds int    `envconfig:"PUBLISHER_BREAKER_OPEN_SECONDS" default:"30"`
+	PublisherOutboxDir          string `envconfig:"PUBLISHER_OUTBOX_DIR"`
+
+	// Callback timing enforcement. CallbackTimingMode is off, flag (accept
+	// and tag late callbacks) or reject; CallbackTimingDomains overrides it
+	// per domain as "ONDC:RET11=reject:60", the number being the allowed
+	// clock skew in seconds. CallbackDefaultRequestTTL applies when the
+	// original request carried no context.ttl.
+	CallbackTimingMode        string `envconfig:"CALLBACK_TIMING_MODE" default:"flag"`
+	CallbackTimingDomains     string `envconfig:"CALLBACK_TIMING_DOMAINS"`
+	CallbackMaxSkewSeconds    int    `envconfig:"CALLBACK_MAX_SKEW_SECONDS" default:"30"`
+	CallbackDefaultRequestTTL string `envconfig:"CALLBACK_DEFAULT_REQUEST_TTL" default:"PT30S"`
+
+	// Seller adapter health probes GET AdapterHealthPath on each active
+	// adapter. An interval of 0 disables probing.
+	AdapterHealthPath            string `envconfig:"ADAPTER_HEALTH_PATH" default:"/health"`
+	AdapterHealthIntervalSeconds int    `envconfig:"ADAPTER_HEALTH_INTERVAL_SECONDS" default:"30"`
+	AdapterHealthTimeoutMs       int    `envconfig:"ADAPTER_HEALTH_TIMEOUT_MS" default:"2000"`
+
+	// Search fan-out. Each adapter call times out after the search's
+	// context.ttl, capped at SearchFanOutMaxTimeoutMs.
+	SearchFanOutMaxTimeoutMs int `envconfig:"SEARCH_FANOUT_MAX_TIMEOUT_MS" default:"10000"`
+	SearchFanOutConcurrency  int `envconfig:"SEARCH_FANOUT_CONCURRENCY" default:"16"`
+
+	// Catalog index. Received on_search catalogs are indexed when
+	// CatalogIndexEnabled is set. With SearchLocalEnabled, searches for a
+	// BPP whose catalog was indexed within CatalogMaxStalenessSeconds are
+	// answered from the index; this requires DISPATCH_ENABLED to send the
+	// signed on_search responses.
+	CatalogIndexEnabled        bool `envconfig:"CATALOG_INDEX_ENABLED" default:"false"`
+	SearchLocalEnabled         bool `envconfig:"SEARCH_LOCAL_ENABLED" default:"false"`
+	CatalogMaxStalenessSeconds int  `envconfig:"CATALOG_MAX_STALENESS_SECONDS" default:"900"`
+
+	// Serviceability index. Provider service areas from received catalogs
+	// are kept in memory when ServiceabilityIndexEnabled is set, and loaded
+	// from the catalog index at startup when that is enabled too.
+	ServiceabilityIndexEnabled bool `envconfig:"SERVICEABILITY_INDEX_ENABLED" default:"false"`
+
+	// Store schedules. Timing tags and holidays of provider locations are
+	// kept when StoreScheduleEnabled is set; their times are read in
+	// StoreTimezone.
+	StoreScheduleEnabled bool   `envconfig:"STORE_SCHEDULE_ENABLED" default:"false"`
+	StoreTimezone        string `envconfig:"STORE_TIMEZONE" default:"Asia/Kolkata"`
+
+	// Multi-part on_search aggregation. Parts of one search are merged once
+	// the search's TTL (CALLBACK_DEFAULT_REQUEST_TTL when absent, capped at
+	// AggregationMaxWindowSeconds) plus AggregationGraceMs has passed.
+	AggregationEnabled             bool `envconfig:"AGGREGATION_ENABLED" default:"false"`
+	AggregationGraceMs             int  `envconfig:"AGGREGATION_GRACE_MS" default:"2000"`
+	AggregationMaxWindowSeconds    int  `envconfig:"AGGREGATION_MAX_WINDOW_SECONDS" default:"300"`
+	AggregationScanIntervalMs      int  `envconfig:"AGGREGATION_SCAN_INTERVAL_MS" default:"1000"`
+	AggregationClaimTimeoutSeconds int  `envconfig:"AGGREGATION_CLAIM_TIMEOUT_SECONDS" default:"300"`
+	AggregationBatchSize           int  `envconfig:"AGGREGATION_BATCH_SIZE" default:"20"`
+
+	// ONDC identity of the edge, used to sign outbound requests.
+	// OndcSigningPrivateKey is the base64 ed25519 seed or private key.
+	OndcSubscriberID             string `envconfig:"ONDC_SUBSCRIBER_ID"`
+	OndcUniqueKeyID              string `envconfig:"ONDC_UNIQUE_KEY_ID"`
+	OndcSigningPrivateKey        string `envconfig:"ONDC_SIGNING_PRIVATE_KEY"`
+	OndcSignatureValiditySeconds int    `envconfig:"ONDC_SIGNATURE_VALIDITY_SECONDS" default:"300"`
+
+	// Registry onboarding. With OndcEncryptionPrivateKey set, /on_subscribe
+	// answers the registry's challenge, encrypted for the X25519 key shared
+	// with OndcRegistryEncryptionPublicKey. With OndcSubscribeRequestID set,
+	// /ondc-site-verification.html serves it signed with the signing key.
+	// cmd/keygen prints the keys in the expected base64 form.
+	OndcEncryptionPrivateKey        string `envconfig:"ONDC_ENCRYPTION_PRIVATE_KEY"`
+	OndcRegistryEncryptionPublicKey string `envconfig:"ONDC_REGISTRY_ENCRYPTION_PUBLIC_KEY"`
+	OndcSubscribeRequestID          string `envconfig:"ONDC_SUBSCRIBE_REQUEST_ID"`
+
+	// Callback dispatch forwards received on_* callbacks to the buyer app's
+	// bap_uri. It requires the ONDC signing settings above.
+	DispatchEnabled           bool `envconfig:"DISPATCH_ENABLED" default:"false"`
+	DispatchMaxAttempts       int  `envconfig:"DISPATCH_MAX_ATTEMPTS" default:"5"`
+	DispatchBackoffBaseMs     int  `envconfig:"DISPATCH_BACKOFF_BASE_MS" default:"500"`
+	DispatchBackoffMaxMs      int  `envconfig:"DISPATCH_BACKOFF_MAX_MS" default:"30000"`
+	DispatchTimeoutMs         int  `envconfig:"DISPATCH_TIMEOUT_MS" default:"10000"`
+	DispatchPerBAPConcurrency int  `envconfig:"DISPATCH_PER_BAP_CONCURRENCY" default:"8"`
+	DispatchMaxPending        int  `envconfig:"DISPATCH_MAX_PENDING" default:"1000"`
+	// DispatchClaimTimeoutMs is how long a delivery may go without progress
+	// before a reprocessed callback dispatches it again. It must exceed
+	// DISPATCH_TIMEOUT_MS plus DISPATCH_BACKOFF_MAX_MS.
+	DispatchClaimTimeoutMs int `envconfig:"DISPATCH_CLAIM_TIMEOUT_MS" default:"120000"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -33,5 +230,94 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("error processing envconfig: %w", err)
 	}
 
+	for name, spec := range map[string]string{"BODY_LIMIT_ROUTES": config.BodyLimitRoutes, "BODY_LIMIT_DOMAINS": config.BodyLimitDomains} {
+		if _, err := ParseSizeMap(spec); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	switch config.EventBus {
+	case "kafka":
+		if config.KafkaBrokers == "" {
+			return nil, fmt.Errorf("KAFKA_BROKERS is required when EVENT_BUS=kafka")
+		}
+	case "nats", "memory":
+	default:
+		return nil, fmt.Errorf("invalid EVENT_BUS %q, expected kafka, nats or memory", config.EventBus)
+	}
+
+	if config.DispatchEnabled && (config.OndcSubscriberID == "" || config.OndcUniqueKeyID == "" || config.OndcSigningPrivateKey == "") {
+		return nil, fmt.Errorf("ONDC_SUBSCRIBER_ID, ONDC_UNIQUE_KEY_ID and ONDC_SIGNING_PRIVATE_KEY are required when DISPATCH_ENABLED=true")
+	}
+	if config.DispatchEnabled && config.DispatchClaimTimeoutMs <= config.DispatchTimeoutMs+config.DispatchBackoffMaxMs {
+		return nil, fmt.Errorf("DISPATCH_CLAIM_TIMEOUT_MS must exceed DISPATCH_TIMEOUT_MS plus DISPATCH_BACKOFF_MAX_MS")
+	}
+
+	if config.SearchLocalEnabled && (!config.CatalogIndexEnabled || !config.DispatchEnabled) {
+		return nil, fmt.Errorf("SEARCH_LOCAL_ENABLED=true requires CATALOG_INDEX_ENABLED=true and DISPATCH_ENABLED=true")
+	}
+
+	if config.OndcEncryptionPrivateKey != "" && (config.OndcSubscriberID == "" || config.OndcRegistryEncryptionPublicKey == "") {
+		return nil, fmt.Errorf("ONDC_SUBSCRIBER_ID and ONDC_REGISTRY_ENCRYPTION_PUBLIC_KEY are required with ONDC_ENCRYPTION_PRIVATE_KEY")
+	}
+	if config.OndcSubscribeRequestID != "" && config.OndcSigningPrivateKey == "" {
+		return nil, fmt.Errorf("ONDC_SIGNING_PRIVATE_KEY is required with ONDC_SUBSCRIBE_REQUEST_ID")
+	}
+
+	if _, err := time.LoadLocation(config.StoreTimezone); err != nil {
+		return nil, fmt.Errorf("invalid STORE_TIMEZONE %q: %w", config.StoreTimezone, err)
+	}
+
 	return config, nil
 }
+
+// ParseSizeMap parses "key=bytes,key2=bytes" into a map. Keys may contain
+// colons (e.g. ONDC:RET11).
+func ParseSizeMap(spec string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid size entry %q, expected key=bytes", entry)
+		}
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid size in %q", entry)
+		}
+		sizes[key] = size
+	}
+	return sizes, nil
+}
+
+// RouteBodyLimit returns the decoded body limit for a route.
+func (c *Config) RouteBodyLimit(path string) int {
+	routes, err := ParseSizeMap(c.BodyLimitRoutes)
+	if err == nil {
+		if limit, ok := routes[path]; ok {
+			return limit
+		}
+	}
+	return c.BodyLimitDefaultBytes
+}
+
+// MaxRequestBodyBytes is the largest body any route accepts. It is used as
+// the server-wide ceiling; routes enforce their own tighter limits.
+func (c *Config) MaxRequestBodyBytes() int {
+	max := c.BodyLimitDefaultBytes
+	for _, spec := range []string{c.BodyLimitRoutes, c.BodyLimitDomains} {
+		sizes, err := ParseSizeMap(spec)
+		if err != nil {
+			continue
+		}
+		for _, size := range sizes {
+			if size > max {
+				max = size
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/joho/godotenv"
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ConsumerConfig configures cmd/consumer, the reference on_search pointer
+// consumer. Broker, MinIO and security variables share their names with
+// the producer's Config.
+type ConsumerConfig struct {
+	LogLevel       string `envconfig:"LOG_LEVEL" default:"info"`
+	MinIOEndpoint  string `envconfig:"MINIO_ENDPOINT" required:"true"`
+	MinIOAccessKey string `envconfig:"MINIO_ACCESS_KEY" required:"true"`
+	MinIOSecretKey string `envconfig:"MINIO_SECRET_KEY" required:"true"`
+	MinIOUseSSL    bool   `envconfig:"MINIO_USE_SSL" default:"false"`
+	MinIOBucket    string `envconfig:"MINIO_BUCKET" default:"ondc-payloads"`
+
+	// EventBus is kafka or nats; the in-memory bus cannot be shared across
+	// processes.
+	EventBus           string `envconfig:"EVENT_BUS" default:"kafka"`
+	KafkaBrokers       string `envconfig:"KAFKA_BROKERS"`
+	KafkaOnSearchTopic string `envconfig:"KAFKA_ON_SEARCH_TOPIC" default:"ondc.on_search.pointer"`
+
+	KafkaTLSEnabled            bool   `envconfig:"KAFKA_TLS_ENABLED" default:"false"`
+	KafkaTLSCAFile             string `envconfig:"KAFKA_TLS_CA_FILE"`
+	KafkaTLSCertFile           string `envconfig:"KAFKA_TLS_CERT_FILE"`
+	KafkaTLSKeyFile            string `envconfig:"KAFKA_TLS_KEY_FILE"`
+	KafkaTLSInsecureSkipVerify bool   `envconfig:"KAFKA_TLS_INSECURE_SKIP_VERIFY" default:"false"`
+	KafkaSASLMechanism         string `envconfig:"KAFKA_SASL_MECHANISM"`
+	KafkaSASLUsername          string `envconfig:"KAFKA_SASL_USERNAME"`
+	KafkaSASLPassword          string `envconfig:"KAFKA_SASL_PASSWORD"`
+
+	NATSURL       string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	NATSStream    string `envconfig:"NATS_STREAM" default:"ONDC_EVENTS"`
+	NATSUser      string `envconfig:"NATS_USER"`
+	NATSPassword  string `envconfig:"NATS_PASSWORD"`
+	NATSToken     string `envconfig:"NATS_TOKEN"`
+	NATSCredsFile string `envconfig:"NATS_CREDS_FILE"`
+
+	// GroupID is the Kafka consumer group or the NATS durable consumer.
+	GroupID     string `envconfig:"CONSUMER_GROUP_ID" default:"gcr-pointer-consumer"`
+	StartOffset string `envconfig:"CONSUMER_START_OFFSET" default:"earliest"`
+	// DLQTopic receives pointers that failed ConsumerMaxAttempts times.
+	// Empty disables dead-lettering. With NATS the stream must capture it.
+	DLQTopic            string `envconfig:"CONSUMER_DLQ_TOPIC" default:"ondc.on_search.pointer.dlq"`
+	MaxAttempts         int    `envconfig:"CONSUMER_MAX_ATTEMPTS" default:"5"`
+	BackoffBaseMs       int    `envconfig:"CONSUMER_BACKOFF_BASE_MS" default:"500"`
+	BackoffMaxMs        int    `envconfig:"CONSUMER_BACKOFF_MAX_MS" default:"30000"`
+	MaxPayloadBytes     int64  `envconfig:"CONSUMER_MAX_PAYLOAD_BYTES" default:"268435456"`
+	ShutdownTimeoutSecs int    `envconfig:"CONSUMER_SHUTDOWN_TIMEOUT_SECONDS" default:"15"`
+}
+
+func LoadConsumerConfig() (*ConsumerConfig, error) {
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("Warning: error loading .env file: %v\n", err)
+	}
+
+	config := &ConsumerConfig{}
+	if err := envconfig.Process("", config); err != nil {
+		return nil, fmt.Errorf("error processing envconfig: %w", err)
+	}
+
+	switch config.EventBus {
+	case "kafka":
+		if config.KafkaBrokers == "" {
+			return nil, fmt.Errorf("KAFKA_BROKERS is required when EVENT_BUS=kafka")
+		}
+	case "nats":
+	default:
+		return nil, fmt.Errorf("invalid EVENT_BUS %q for the consumer, expected kafka or nats", config.EventBus)
+	}
+
+	return config, nil
+}
@@ -3,30 +3,58 @@ package di
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
 	"adapter/internal/adapters/messaging"
+	"adapter/internal/adapters/repository"
+	"adapter/internal/adapters/resilience"
+	"adapter/internal/adapters/storage"
 	"adapter/internal/adapters/validation"
 	"adapter/internal/config"
 	"adapter/internal/domain"
+	"adapter/internal/ports"
 	db "adapter/internal/shared/database"
+	"adapter/internal/shared/health"
+	"adapter/internal/shared/lifecycle"
 	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
 )
 
 type Container struct {
 	Config          *config.Config
 	DB              *gorm.DB
 	OnSearchService *domain.OnSearchService
+	IngestionQueue  *domain.IngestionQueue
+	UserService     *domain.UserService
+	Transactions    *domain.TransactionService
+	Adapters        *domain.AdapterRegistry
+	SearchService   *domain.SearchService
+	Dispatcher      *domain.CallbackDispatcher
+	Catalogs        *domain.CatalogMaterializer
+	Aggregator      *domain.CatalogAggregator
+	Serviceability  *domain.ServiceabilityIndex
+	Schedules       *domain.StoreSchedules
+	Subscription    *domain.SubscriptionService
+	RateLimiter     *domain.RateLimiter
+	Health          *health.Registry
+	Lifecycle       *lifecycle.Manager
+
+	// MemoryBus is set when EVENT_BUS=memory so tests can subscribe to
+	// published events.
+	MemoryBus *messaging.MemoryBus
 }
 
+// Shutdown releases every registered resource in lifecycle order:
+// HTTP drain, outbound flush, storage, then database.
 func (c *Container) Shutdown(ctx context.Context) error {
 	logger.Info(ctx, "Shutting down container resources...")
 
-	if c.DB != nil {
-		if err := db.Close(); err != nil {
-			logger.Error(ctx, err, "Failed to close database connection")
-		}
+	err := c.Lifecycle.Shutdown(ctx)
+	if err != nil {
+		logger.Error(ctx, err, "Container shutdown completed with errors")
+		return err
 	}
 
 	logger.Info(ctx, "Container shutdown complete")
@@ -60,16 +88,15 @@ func InitContainer() (*Container, error) {
 	}
 	logger.Info(ctx, "Database migrations completed successfully")
 
-	// Kafka publisher adapter
-	fmt.Printf("[DEBUG] Initializing Kafka publisher...\n")
-	kafkaPublisher, err := messaging.NewKafkaPublisher(messaging.KafkaConfig{
-		Brokers: cfg.KafkaBrokers,
-	})
+	// Event publisher adapter (Kafka, NATS JetStream or in-memory)
+	fmt.Printf("[DEBUG] Initializing %s event publisher...\n", cfg.EventBus)
+	eventPublisher, memoryBus, err := newEventPublisher(cfg, []string{cfg.KafkaOnSearchTopic, cfg.KafkaStateTopic})
 	if err != nil {
-		fmt.Printf("[DEBUG] Kafka init failed: %v\n", err)
-		logger.Fatal(ctx, fmt.Errorf("failed to initialize Kafka publisher: %w", err), "Kafka initialization error")
+		fmt.Printf("[DEBUG] Event publisher init failed: %v\n", err)
+		logger.Fatal(ctx, fmt.Errorf("failed to initialize %s event publisher: %w", cfg.EventBus, err), "Event publisher initialization error")
+		return nil, err
 	}
-	fmt.Printf("[DEBUG] Kafka publisher initialized successfully\n")
+	fmt.Printf("[DEBUG] Event publisher initialized successfully\n")
 
 	// JSON schema validator for ONDC RET11 on_search
 	fmt.Printf("[DEBUG] Initializing schema validator...\n")
@@ -80,19 +107,451 @@ func InitContainer() (*Container, error) {
 	}
 	fmt.Printf("[DEBUG] Schema validator initialized successfully\n")
 
+	// MinIO object storage adapter
+	fmt.Printf("[DEBUG] Initializing MinIO storage...\n")
+	minioStorage, err := storage.NewMinIOStorage(storage.MinIOConfig{
+		Endpoint:  cfg.MinIOEndpoint,
+		AccessKey: cfg.MinIOAccessKey,
+		SecretKey: cfg.MinIOSecretKey,
+		UseSSL:    cfg.MinIOUseSSL,
+		Bucket:    cfg.MinIOBucket,
+	})
+	if err != nil {
+		fmt.Printf("[DEBUG] MinIO init failed: %v\n", err)
+		logger.Fatal(ctx, fmt.Errorf("failed to initialize MinIO storage: %w", err), "MinIO initialization error")
+	}
+	fmt.Printf("[DEBUG] MinIO storage initialized successfully\n")
+
+	// Retries and circuit breakers around outbound dependencies
+	objectStorage := resilience.NewResilientStorage(minioStorage, resilience.Policy{
+		Name:            "minio",
+		MaxAttempts:     cfg.StorageMaxAttempts,
+		BackoffBase:     time.Duration(cfg.StorageBackoffBaseMs) * time.Millisecond,
+		BackoffMax:      time.Duration(cfg.StorageBackoffMaxMs) * time.Millisecond,
+		AttemptTimeout:  time.Duration(cfg.StorageAttemptTimeoutMs) * time.Millisecond,
+		MaxElapsed:      time.Duration(cfg.StorageMaxElapsedMs) * time.Millisecond,
+		BreakerFailures: cfg.StorageBreakerFailures,
+		BreakerOpen:     time.Duration(cfg.StorageBreakerOpenSeconds) * time.Second,
+		Retryable:       storage.IsRetryable,
+	})
+
+	var outbox *messaging.OutboxPublisher
+	var publisherFallback ports.EventPublisher
+	if cfg.PublisherOutboxDir != "" {
+		outbox, err = messaging.NewOutboxPublisher(cfg.PublisherOutboxDir)
+		if err != nil {
+			logger.Fatal(ctx, fmt.Errorf("failed to initialize publisher outbox: %w", err), "Outbox initialization error")
+			return nil, err
+		}
+		publisherFallback = outbox
+	}
+	publisher := resilience.NewResilientPublisher(eventPublisher, publisherFallback, resilience.Policy{
+		Name:            cfg.EventBus,
+		MaxAttempts:     cfg.PublisherMaxAttempts,
+		BackoffBase:     time.Duration(cfg.PublisherBackoffBaseMs) * time.Millisecond,
+		BackoffMax:      time.Duration(cfg.PublisherBackoffMaxMs) * time.Millisecond,
+		AttemptTimeout:  time.Duration(cfg.PublisherAttemptTimeoutMs) * time.Millisecond,
+		MaxElapsed:      time.Duration(cfg.PublisherMaxElapsedMs) * time.Millisecond,
+		BreakerFailures: cfg.PublisherBreakerFailures,
+		BreakerOpen:     time.Duration(cfg.PublisherBreakerOpenSeconds) * time.Second,
+		Retryable:       messaging.IsRetryable,
+	})
+
+	transactionRepository := repository.NewTransactionRepository(database)
+	transactionStateRepository := repository.NewTransactionStateRepository(database)
+	stateMachine := domain.NewTransactionStateMachine(
+		transactionStateRepository,
+		publisher,
+		cfg.KafkaStateTopic,
+		cfg.EventSource,
+	)
+	timingPolicy, err := domain.NewTimingPolicy(
+		cfg.CallbackTimingMode,
+		time.Duration(cfg.CallbackMaxSkewSeconds)*time.Second,
+		cfg.CallbackTimingDomains,
+		cfg.CallbackDefaultRequestTTL,
+		transactionRepository,
+	)
+	if err != nil {
+		logger.Fatal(ctx, fmt.Errorf("invalid callback timing policy: %w", err), "Timing policy initialization error")
+	}
+	var dispatcher *domain.CallbackDispatcher
+	if cfg.DispatchEnabled {
+		signer, err := ondc.NewSigner(
+			cfg.OndcSubscriberID,
+			cfg.OndcUniqueKeyID,
+			cfg.OndcSigningPrivateKey,
+			time.Duration(cfg.OndcSignatureValiditySeconds)*time.Second,
+		)
+		if err != nil {
+			logger.Fatal(ctx, fmt.Errorf("invalid ONDC signing key: %w", err), "Callback dispatcher initialization error")
+			return nil, err
+		}
+		dispatcher = domain.NewCallbackDispatcher(signer, repository.NewDeliveryRepository(database), domain.CallbackDispatcherConfig{
+			MaxAttempts:       cfg.DispatchMaxAttempts,
+			BackoffBase:       time.Duration(cfg.DispatchBackoffBaseMs) * time.Millisecond,
+			BackoffMax:        time.Duration(cfg.DispatchBackoffMaxMs) * time.Millisecond,
+			AttemptTimeout:    time.Duration(cfg.DispatchTimeoutMs) * time.Millisecond,
+			PerBAPConcurrency: cfg.DispatchPerBAPConcurrency,
+			MaxPending:        cfg.DispatchMaxPending,
+			ClaimTimeout:      time.Duration(cfg.DispatchClaimTimeoutMs) * time.Millisecond,
+		})
+		logger.Infof(ctx, "Callback dispatch enabled as %s", cfg.OndcSubscriberID)
+	}
+	var subscription *domain.SubscriptionService
+	if cfg.OndcEncryptionPrivateKey != "" || cfg.OndcSubscribeRequestID != "" {
+		subscription, err = domain.NewSubscriptionService(
+			cfg.OndcSubscriberID,
+			cfg.OndcEncryptionPrivateKey,
+			cfg.OndcRegistryEncryptionPublicKey,
+			cfg.OndcSigningPrivateKey,
+			cfg.OndcSubscribeRequestID,
+		)
+		if err != nil {
+			logger.Fatal(ctx, fmt.Errorf("invalid ONDC subscription keys: %w", err), "Subscription initialization error")
+			return nil, err
+		}
+		logger.Infof(ctx, "Registry onboarding enabled for %s", cfg.OndcSubscriberID)
+	}
+	catalogRepository := repository.NewCatalogRepository(database)
+	var catalogIndex *domain.CatalogIndex
+	if cfg.CatalogIndexEnabled {
+		catalogIndex = domain.NewCatalogIndex(
+			catalogRepository,
+			transactionRepository,
+			time.Duration(cfg.CatalogMaxStalenessSeconds)*time.Second,
+		)
+		logger.Info(ctx, "Catalog indexing enabled")
+	}
+	var serviceability *domain.ServiceabilityIndex
+	if cfg.ServiceabilityIndexEnabled {
+		serviceability = domain.NewServiceabilityIndex()
+		if cfg.CatalogIndexEnabled {
+			// A failed load only leaves the index to fill as catalogs arrive
+			if err := serviceability.Load(ctx, catalogRepository); err != nil {
+				logger.Warnf(ctx, "Failed to load serviceability from the catalog index: %v", err)
+			}
+		}
+		logger.Info(ctx, "Serviceability index enabled")
+	}
+	var schedules *domain.StoreSchedules
+	if cfg.StoreScheduleEnabled {
+		// Validated by config.Load
+		storeTimezone, _ := time.LoadLocation(cfg.StoreTimezone)
+		schedules = domain.NewStoreSchedules(repository.NewScheduleRepository(database), storeTimezone)
+		logger.Infof(ctx, "Store timing enabled in %s", cfg.StoreTimezone)
+	}
+	catalogMaterializer := domain.NewCatalogMaterializer(
+		repository.NewCatalogVersionRepository(database),
+		objectStorage,
+		transactionRepository,
+	)
+	var aggregator *domain.CatalogAggregator
+	if cfg.AggregationEnabled {
+		// Validated by NewTimingPolicy above
+		defaultWindow, _ := ondc.ParseDuration(cfg.CallbackDefaultRequestTTL)
+		aggregator = domain.NewCatalogAggregator(
+			repository.NewAggregateRepository(database),
+			transactionRepository,
+			objectStorage,
+			publisher,
+			cfg.KafkaOnSearchTopic,
+			cfg.EventSource,
+			domain.CatalogAggregatorConfig{
+				DefaultWindow: defaultWindow,
+				MaxWindow:     time.Duration(cfg.AggregationMaxWindowSeconds) * time.Second,
+				Grace:         time.Duration(cfg.AggregationGraceMs) * time.Millisecond,
+				ClaimTimeout:  time.Duration(cfg.AggregationClaimTimeoutSeconds) * time.Second,
+				BatchSize:     cfg.AggregationBatchSize,
+			},
+		)
+		logger.Info(ctx, "Multi-part on_search aggregation enabled")
+	}
 	onSearchService, err := domain.NewOnSearchService(
 		schemaValidator,
-		kafkaPublisher,
+		objectStorage,
+		publisher,
+		transactionRepository,
+		stateMachine,
+		timingPolicy,
+		dispatcher,
+		catalogIndex,
+		catalogMaterializer,
+		aggregator,
+		serviceability,
+		schedules,
 		cfg.KafkaOnSearchTopic,
-		cfg,
+		cfg.EventSource,
 	)
 	if err != nil {
 		logger.Fatal(ctx, fmt.Errorf("failed to create OnSearchService: %w", err), "OnSearchService initialization error")
 	}
 
+	var ingestionQueue *domain.IngestionQueue
+	switch cfg.IngestMode {
+	case "sync":
+	case "async":
+		ingestionQueue, err = domain.NewIngestionQueue(onSearchService, domain.IngestionQueueConfig{
+			QueueSize:      cfg.IngestQueueSize,
+			Workers:        cfg.IngestWorkers,
+			SpillDir:       cfg.IngestSpillDir,
+			SpillMaxBytes:  cfg.IngestSpillMaxBytes,
+			MaxAttempts:    cfg.IngestMaxAttempts,
+			RetryBaseDelay: time.Duration(cfg.IngestRetryBaseMs) * time.Millisecond,
+			JobTimeout:     time.Duration(cfg.IngestJobTimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			logger.Fatal(ctx, fmt.Errorf("failed to create ingestion queue: %w", err), "Ingestion queue initialization error")
+			return nil, err
+		}
+		ingestionQueue.Start()
+		logger.Infof(ctx, "Async ingestion enabled with %d workers", cfg.IngestWorkers)
+	default:
+		err = fmt.Errorf("unknown ingest mode %q", cfg.IngestMode)
+		logger.Fatal(ctx, err, "Configuration error")
+		return nil, err
+	}
+
+	userService := domain.NewUserService(
+		repository.NewUserRepository(database),
+		time.Duration(cfg.APIKeyCacheTTLSeconds)*time.Second,
+	)
+
+	adapterRegistry := domain.NewAdapterRegistry(
+		repository.NewAdapterRepository(database),
+		cfg.AdapterHealthPath,
+		time.Duration(cfg.AdapterHealthTimeoutMs)*time.Millisecond,
+	)
+
+	// Validated by NewTimingPolicy above
+	defaultRequestTTL, _ := ondc.ParseDuration(cfg.CallbackDefaultRequestTTL)
+	var localCatalog *domain.CatalogIndex
+	if cfg.SearchLocalEnabled {
+		localCatalog = catalogIndex
+	}
+	searchService := domain.NewSearchService(schemaValidator, adapterRegistry, transactionRepository, localCatalog, serviceability, dispatcher, domain.SearchServiceConfig{
+		DefaultTTL:  defaultRequestTTL,
+		MaxTimeout:  time.Duration(cfg.SearchFanOutMaxTimeoutMs) * time.Millisecond,
+		Concurrency: cfg.SearchFanOutConcurrency,
+	})
+
+	rateLimiter, err := newRateLimiter(cfg, database)
+	if err != nil {
+		logger.Fatal(ctx, err, "Rate limiter initialization error")
+		return nil, err
+	}
+
+	// Readiness checks for every external dependency
+	healthRegistry := health.NewRegistry(time.Duration(cfg.HealthCheckTimeoutMs) * time.Millisecond)
+	healthRegistry.Register("postgres", health.CheckerFunc(db.Ping))
+	registerHealthChecker(healthRegistry, "minio", minioStorage)
+	registerHealthChecker(healthRegistry, cfg.EventBus, eventPublisher)
+	registerHealthChecker(healthRegistry, "schema_validator", schemaValidator)
+	if ingestionQueue != nil {
+		registerHealthChecker(healthRegistry, "ingestion_queue", ingestionQueue)
+	}
+	if dispatcher != nil {
+		registerHealthChecker(healthRegistry, "callback_dispatcher", dispatcher)
+	}
+
+	// Closers run in phase order during graceful shutdown
+	lifecycleManager := lifecycle.NewManager()
+	registerCloser(lifecycleManager, lifecycle.PhaseOutbound, cfg.EventBus, publisher)
+	if outbox != nil {
+		stopReplay := startOutboxReplay(outbox, eventPublisher, time.Minute)
+		lifecycleManager.Register(lifecycle.PhaseOutbound, "outbox_replay", func(ctx context.Context) error {
+			stopReplay()
+			return nil
+		})
+	}
+	if dispatcher != nil {
+		// Registered before the ingestion queue, whose workers still dispatch
+		lifecycleManager.Register(lifecycle.PhaseOutbound, "callback_dispatcher", dispatcher.Close)
+	}
+	if ingestionQueue != nil {
+		// Registered after Kafka so it drains first within the phase
+		lifecycleManager.Register(lifecycle.PhaseOutbound, "ingestion_queue", ingestionQueue.Close)
+	}
+	lifecycleManager.Register(lifecycle.PhaseOutbound, "search_fanout", searchService.Close)
+	if cfg.AdapterHealthIntervalSeconds > 0 {
+		stopProbes := startAdapterHealthChecks(adapterRegistry, time.Duration(cfg.AdapterHealthIntervalSeconds)*time.Second)
+		lifecycleManager.Register(lifecycle.PhaseOutbound, "adapter_health", func(ctx context.Context) error {
+			stopProbes()
+			return nil
+		})
+	}
+	if aggregator != nil {
+		// Registered after Kafka so scans stop before the publisher closes
+		stopAggregation := startAggregationCloser(aggregator, time.Duration(cfg.AggregationScanIntervalMs)*time.Millisecond)
+		lifecycleManager.Register(lifecycle.PhaseOutbound, "catalog_aggregation", func(ctx context.Context) error {
+			stopAggregation()
+			return nil
+		})
+	}
+	registerCloser(lifecycleManager, lifecycle.PhaseStorage, "minio", objectStorage)
+	lifecycleManager.Register(lifecycle.PhaseDatabase, "postgres", func(ctx context.Context) error {
+		return db.Close()
+	})
+
 	return &Container{
 		Config:          cfg,
 		DB:              database,
 		OnSearchService: onSearchService,
+		IngestionQueue:  ingestionQueue,
+		MemoryBus:       memoryBus,
+		UserService:     userService,
+		Transactions:    domain.NewTransactionService(transactionRepository, transactionStateRepository, objectStorage),
+		Adapters:        adapterRegistry,
+		SearchService:   searchService,
+		Dispatcher:      dispatcher,
+		Catalogs:        catalogMaterializer,
+		Aggregator:      aggregator,
+		Serviceability:  serviceability,
+		Schedules:       schedules,
+		Subscription:    subscription,
+		RateLimiter:     rateLimiter,
+		Health:          healthRegistry,
+		Lifecycle:       lifecycleManager,
 	}, err
 }
+
+// registerHealthChecker adds the adapter to the registry when it knows how
+// to probe its own dependency.
+func registerHealthChecker(registry *health.Registry, name string, adapter any) {
+	if checker, ok := adapter.(ports.HealthChecker); ok {
+		registry.Register(name, health.CheckerFunc(checker.HealthCheck))
+	}
+}
+
+// registerCloser adds the adapter to the shutdown sequence when it holds
+// resources that need releasing.
+func registerCloser(manager *lifecycle.Manager, phase lifecycle.Phase, name string, adapter any) {
+	if closer, ok := adapter.(ports.Closer); ok {
+		manager.Register(phase, name, closer.Close)
+	}
+}
+
+// newRateLimiter builds the per-seller rate limiter, or returns nil when rate
+// limiting is disabled.
+func newRateLimiter(cfg *config.Config, database *gorm.DB) (*domain.RateLimiter, error) {
+	if !cfg.RateLimitEnabled {
+		return nil, nil
+	}
+
+	var store ports.RateLimitPolicyStore
+	switch cfg.RateLimitPolicySource {
+	case "postgres":
+		store = repository.NewRateLimitPolicyRepository(database)
+	case "memory":
+		memoryStore, err := repository.NewMemoryRateLimitPolicyStore(cfg.RateLimitOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rate limit overrides: %w", err)
+		}
+		store = memoryStore
+	default:
+		return nil, fmt.Errorf("unknown rate limit policy source %q", cfg.RateLimitPolicySource)
+	}
+
+	defaults := ports.RateLimitPolicy{
+		RequestsPerSecond: cfg.RateLimitRequestsPerSecond,
+		Burst:             cfg.RateLimitBurst,
+		BytesPerMinute:    cfg.RateLimitBytesPerMinute,
+	}
+	return domain.NewRateLimiter(store, defaults, time.Duration(cfg.RateLimitPolicyCacheSeconds)*time.Second, cfg.RateLimitMaxKeys), nil
+}
+
+// startOutboxReplay periodically forwards messages parked in the outbox to
+// the primary publisher. The returned function stops the loop.
+func startOutboxReplay(outbox *messaging.OutboxPublisher, target ports.EventPublisher, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			replayed, err := outbox.Replay(ctx, target)
+			if replayed > 0 {
+				logger.Infof(ctx, "Replayed %d messages from publisher outbox", replayed)
+			}
+			if err != nil && ctx.Err() == nil {
+				logger.Warnf(ctx, "Outbox replay incomplete: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startAdapterHealthChecks probes the registered adapters every interval
+// until the returned stop function is called.
+func startAdapterHealthChecks(registry *domain.AdapterRegistry, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := registry.CheckHealth(ctx); err != nil && ctx.Err() == nil {
+				logger.Warnf(ctx, "Adapter health check failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startAggregationCloser closes expired on_search aggregates every interval
+// until the returned stop function is called. Scans repeat straight away
+// while they find aggregates to close.
+func startAggregationCloser(aggregator *domain.CatalogAggregator, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			closed, err := aggregator.CloseDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				logger.Warnf(ctx, "Closing catalog aggregates failed: %v", err)
+			}
+			if closed > 0 && err == nil && ctx.Err() == nil {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,61 @@
+package di
+
+import (
+	"fmt"
+	"time"
+
+	"adapter/internal/adapters/messaging"
+	"adapter/internal/config"
+	"adapter/internal/ports"
+)
+
+// newEventPublisher builds the publisher selected by EVENT_BUS. The memory
+// bus is returned separately so it can be exposed on the container.
+func newEventPublisher(cfg *config.Config, topics []string) (ports.EventPublisher, *messaging.MemoryBus, error) {
+	switch cfg.EventBus {
+	case "kafka":
+		publisher, err := messaging.NewKafkaPublisher(messaging.KafkaConfig{
+			Brokers: cfg.KafkaBrokers,
+			Topics:  topics,
+			Security: messaging.KafkaSecurityConfig{
+				TLSEnabled:            cfg.KafkaTLSEnabled,
+				TLSCAFile:             cfg.KafkaTLSCAFile,
+				TLSCertFile:           cfg.KafkaTLSCertFile,
+				TLSKeyFile:            cfg.KafkaTLSKeyFile,
+				TLSInsecureSkipVerify: cfg.KafkaTLSInsecureSkipVerify,
+				SASLMechanism:         cfg.KafkaSASLMechanism,
+				SASLUsername:          cfg.KafkaSASLUsername,
+				SASLPassword:          cfg.KafkaSASLPassword,
+			},
+			Compression:            cfg.KafkaCompression,
+			Balancer:               cfg.KafkaBalancer,
+			BatchSize:              cfg.KafkaBatchSize,
+			BatchBytes:             cfg.KafkaBatchBytes,
+			BatchTimeout:           time.Duration(cfg.KafkaBatchTimeoutMs) * time.Millisecond,
+			Async:                  cfg.KafkaAsync,
+			AutoCreateTopics:       cfg.KafkaAutoCreateTopics,
+			TopicPartitions:        cfg.KafkaTopicPartitions,
+			TopicReplicationFactor: cfg.KafkaTopicReplication,
+		})
+		return publisher, nil, err
+	case "nats":
+		publisher, err := messaging.NewNATSPublisher(messaging.NATSConfig{
+			URL:              cfg.NATSURL,
+			Stream:           cfg.NATSStream,
+			Topics:           topics,
+			Username:         cfg.NATSUser,
+			Password:         cfg.NATSPassword,
+			Token:            cfg.NATSToken,
+			CredsFile:        cfg.NATSCredsFile,
+			AutoCreateStream: cfg.NATSAutoCreateStream,
+			Replicas:         cfg.NATSStreamReplicas,
+			PublishTimeout:   time.Duration(cfg.NATSPublishTimeoutMs) * time.Millisecond,
+		})
+		return publisher, nil, err
+	case "memory":
+		bus := messaging.NewMemoryBus(cfg.MemoryBusBuffer)
+		return bus, bus, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported event bus %q", cfg.EventBus)
+	}
+}
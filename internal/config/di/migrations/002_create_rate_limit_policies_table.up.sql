@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rate_limit_policies (
+    subscriber_id VARCHAR(255) PRIMARY KEY,
+    requests_per_second DOUBLE PRECISION NOT NULL,
+    burst INTEGER NOT NULL,
+    bytes_per_minute BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
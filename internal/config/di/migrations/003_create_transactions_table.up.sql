@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id VARCHAR(40) PRIMARY KEY,
+    transaction_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    domain VARCHAR(64) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    bap_id VARCHAR(255) NOT NULL DEFAULT '',
+    bpp_id VARCHAR(255) NOT NULL DEFAULT '',
+    bucket VARCHAR(255) NOT NULL DEFAULT '',
+    object_key TEXT NOT NULL DEFAULT '',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_transaction_id ON transactions(transaction_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_bpp_id ON transactions(bpp_id, received_at);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS violation;
+ALTER TABLE transactions DROP COLUMN IF EXISTS out_of_order;
+DROP TABLE IF EXISTS transaction_states;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS transaction_states (
+    transaction_id VARCHAR(255) PRIMARY KEY,
+    domain VARCHAR(64) NOT NULL,
+    bap_id VARCHAR(255) NOT NULL DEFAULT '',
+    bpp_id VARCHAR(255) NOT NULL DEFAULT '',
+    stage VARCHAR(32) NOT NULL,
+    last_action VARCHAR(64) NOT NULL,
+    actions TEXT NOT NULL DEFAULT '',
+    order_state VARCHAR(32) NOT NULL DEFAULT '',
+    violations INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_states_order_state ON transaction_states(order_state);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS out_of_order BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS violation TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_request;
+ALTER TABLE transactions DROP COLUMN IF EXISTS timing_violation;
+ALTER TABLE transactions DROP COLUMN IF EXISTS late;
+ALTER TABLE transactions DROP COLUMN IF EXISTS ttl;
+ALTER TABLE transactions DROP COLUMN IF EXISTS context_timestamp;
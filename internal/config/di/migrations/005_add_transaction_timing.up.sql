@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS context_timestamp TIMESTAMP NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS ttl VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS late BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS timing_violation TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_request ON transactions(transaction_id, message_id, action);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS adapters (
+    id VARCHAR(40) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    url VARCHAR(500) NOT NULL,
+    callback_url VARCHAR(500) NOT NULL,
+    api_key VARCHAR(100) NOT NULL UNIQUE,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Databases initialised by the old scripts/migrations setup carry a trigger
+-- on updated_at; the repository sets it explicitly.
+DROP TRIGGER IF EXISTS update_adapters_updated_at ON adapters;
+
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS bpp_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS domains TEXT NOT NULL DEFAULT '';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS cities TEXT NOT NULL DEFAULT '';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS health_status VARCHAR(16) NOT NULL DEFAULT 'unknown';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS health_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS health_checked_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_adapters_api_key ON adapters(api_key);
+CREATE INDEX IF NOT EXISTS idx_adapters_is_active ON adapters(is_active);
+CREATE INDEX IF NOT EXISTS idx_adapters_name ON adapters(name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_adapters_bpp_id ON adapters(bpp_id) WHERE bpp_id <> '';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS callback_deliveries (
+    id VARCHAR(40) PRIMARY KEY,
+    transaction_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    bap_id VARCHAR(255) NOT NULL DEFAULT '',
+    bap_uri TEXT NOT NULL DEFAULT '',
+    bpp_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_deliveries_message_id ON callback_deliveries(message_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_callback_deliveries_status ON callback_deliveries(status);
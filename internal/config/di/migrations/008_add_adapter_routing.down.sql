@@ -0,0 +1,2 @@
+ALTER TABLE adapters DROP COLUMN IF EXISTS area_codes;
+ALTER TABLE adapters DROP COLUMN IF EXISTS categories;
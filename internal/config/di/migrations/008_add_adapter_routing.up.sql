@@ -0,0 +1,2 @@
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS categories TEXT NOT NULL DEFAULT '';
+ALTER TABLE adapters ADD COLUMN IF NOT EXISTS area_codes TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS catalog_providers (
+    bpp_id VARCHAR(255) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    domain VARCHAR(64) NOT NULL,
+    city VARCHAR(64) NOT NULL DEFAULT '',
+    bpp_uri TEXT NOT NULL DEFAULT '',
+    core_version VARCHAR(32) NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    bpp_descriptor TEXT NOT NULL DEFAULT '',
+    bpp_fulfillments TEXT NOT NULL DEFAULT '',
+    provider TEXT NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    source_id VARCHAR(40) NOT NULL DEFAULT '',
+    indexed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bpp_id, provider_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_providers_domain_city ON catalog_providers(domain, city);
+
+CREATE TABLE IF NOT EXISTS catalog_items (
+    bpp_id VARCHAR(255) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    item_id VARCHAR(255) NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    category_ids TEXT NOT NULL DEFAULT '',
+    item TEXT NOT NULL,
+    indexed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bpp_id, provider_id, item_id),
+    FOREIGN KEY (bpp_id, provider_id) REFERENCES catalog_providers(bpp_id, provider_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_items_name_fts ON catalog_items USING GIN (to_tsvector('simple', name));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS catalog_versions;
+ALTER TABLE transactions DROP COLUMN IF EXISTS catalog_mode;
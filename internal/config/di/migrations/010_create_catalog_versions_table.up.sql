@@ -0,0 +1,20 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS catalog_mode VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS catalog_versions (
+    id VARCHAR(40) PRIMARY KEY,
+    bpp_id VARCHAR(255) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    domain VARCHAR(64) NOT NULL,
+    city VARCHAR(64) NOT NULL DEFAULT '',
+    mode VARCHAR(16) NOT NULL,
+    base_version_id VARCHAR(40) NOT NULL DEFAULT '',
+    callback_id VARCHAR(40) NOT NULL,
+    transaction_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    object_key TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_versions_provider ON catalog_versions(bpp_id, provider_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_catalog_versions_base ON catalog_versions(base_version_id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS catalog_aggregates (
+    transaction_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    bpp_id VARCHAR(255) NOT NULL,
+    domain VARCHAR(64) NOT NULL,
+    city VARCHAR(64) NOT NULL DEFAULT '',
+    bap_id VARCHAR(255) NOT NULL DEFAULT '',
+    core_version VARCHAR(32) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,
+    parts INTEGER NOT NULL DEFAULT 0,
+    late_parts INTEGER NOT NULL DEFAULT 0,
+    first_part_at TIMESTAMP NOT NULL,
+    closes_at TIMESTAMP NOT NULL,
+    bucket VARCHAR(255) NOT NULL DEFAULT '',
+    object_key TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (transaction_id, message_id, bpp_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_aggregates_due ON catalog_aggregates(status, closes_at);
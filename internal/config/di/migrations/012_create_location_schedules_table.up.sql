@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS location_schedules (
+    bpp_id VARCHAR(255) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    location_id VARCHAR(255) NOT NULL,
+    domain VARCHAR(64) NOT NULL,
+    city VARCHAR(64) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    windows TEXT NOT NULL DEFAULT '[]',
+    holidays TEXT NOT NULL DEFAULT '',
+    warnings TEXT NOT NULL DEFAULT '[]',
+    source_id VARCHAR(40) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bpp_id, provider_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_schedules_provider ON location_schedules(provider_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_callback_deliveries_dispatch_key;
+ALTER TABLE callback_deliveries DROP COLUMN IF EXISTS dispatch_key;
@@ -0,0 +1,3 @@
+ALTER TABLE callback_deliveries ADD COLUMN IF NOT EXISTS dispatch_key VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_callback_deliveries_dispatch_key ON callback_deliveries(dispatch_key) WHERE dispatch_key <> '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_catalog_versions_callback;
+ALTER TABLE catalog_versions DROP COLUMN IF EXISTS checksum;
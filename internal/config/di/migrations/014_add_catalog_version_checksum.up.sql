@@ -0,0 +1,3 @@
+ALTER TABLE catalog_versions ADD COLUMN IF NOT EXISTS checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_versions_callback ON catalog_versions(bpp_id, provider_id, message_id, checksum) WHERE checksum <> '';
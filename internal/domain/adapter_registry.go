@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+)
+
+// AdapterRegistry manages the backend seller systems behind the edge and
+// resolves which of them owns a bpp_id or serves a domain and city.
+type AdapterRegistry struct {
+	repo         ports.AdapterRepository
+	client       *http.Client
+	healthPath   string
+	probeTimeout time.Duration
+}
+
+// AdapterInput carries the fields of a create or update request. Nil
+// fields are left unchanged on update.
+type AdapterInput struct {
+	Name        *string  `json:"name"`
+	URL         *string  `json:"url"`
+	CallbackURL *string  `json:"callback_url"`
+	APIKey      *string  `json:"api_key"`
+	BppID       *string  `json:"bpp_id"`
+	Domains     []string `json:"domains"`
+	Cities      []string `json:"cities"`
+	Categories  []string `json:"categories"`
+	AreaCodes   []string `json:"area_codes"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// IssuedAdapter is returned when an adapter is created. APIKey is only set
+// when the registry generated the key.
+type IssuedAdapter struct {
+	*ports.Adapter
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// NewAdapterRegistry constructs a new AdapterRegistry. Health probes GET
+// healthPath on each adapter's URL.
+func NewAdapterRegistry(repo ports.AdapterRepository, healthPath string, probeTimeout time.Duration) *AdapterRegistry {
+	return &AdapterRegistry{
+		repo:         repo,
+		client:       &http.Client{Timeout: probeTimeout},
+		healthPath:   healthPath,
+		probeTimeout: probeTimeout,
+	}
+}
+
+// Create registers an adapter. A key is generated when none is given.
+func (r *AdapterRegistry) Create(ctx context.Context, input AdapterInput) (*IssuedAdapter, error) {
+	adapter := &ports.Adapter{
+		ID:           uuid.NewString(),
+		IsActive:     true,
+		HealthStatus: ports.AdapterHealthUnknown,
+	}
+	if err := applyAdapterInput(adapter, input); err != nil {
+		return nil, err
+	}
+	if adapter.Name == "" || adapter.URL == "" || adapter.CallbackURL == "" {
+		return nil, appError.NewCustomError(400, appError.ErrMissingRequiredField.Code, appError.ErrMissingRequiredField.Message, "name, url and callback_url are required")
+	}
+
+	var generated string
+	if adapter.APIKey == "" {
+		key, _, err := generateAPIKey()
+		if err != nil {
+			return nil, appError.NewCustomError(500, appError.ErrFailedToSaveAdapter.Code, appError.ErrFailedToSaveAdapter.Message, err.Error())
+		}
+		adapter.APIKey = key
+		generated = key
+	}
+
+	if err := r.repo.Create(ctx, adapter); err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "Registered adapter %s (%s) for bpp_id=%s", adapter.Name, adapter.ID, adapter.BppID)
+	return &IssuedAdapter{Adapter: adapter, APIKey: generated}, nil
+}
+
+// Update changes the fields set in input.
+func (r *AdapterRegistry) Update(ctx context.Context, id string, input AdapterInput) (*ports.Adapter, error) {
+	adapter, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAdapterInput(adapter, input); err != nil {
+		return nil, err
+	}
+	if adapter.Name == "" || adapter.URL == "" || adapter.CallbackURL == "" || adapter.APIKey == "" {
+		return nil, appError.NewCustomError(400, appError.ErrMissingRequiredField.Code, appError.ErrMissingRequiredField.Message, "name, url, callback_url and api_key cannot be empty")
+	}
+	if err := r.repo.Update(ctx, adapter); err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "Updated adapter %s (%s)", adapter.Name, adapter.ID)
+	return r.repo.GetByID(ctx, id)
+}
+
+// Delete removes an adapter from the registry.
+func (r *AdapterRegistry) Delete(ctx context.Context, id string) error {
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Deleted adapter %s", id)
+	return nil
+}
+
+// Get returns one adapter.
+func (r *AdapterRegistry) Get(ctx context.Context, id string) (*ports.Adapter, error) {
+	return r.repo.GetByID(ctx, id)
+}
+
+// List returns every registered adapter.
+func (r *AdapterRegistry) List(ctx context.Context) ([]ports.Adapter, error) {
+	return r.repo.List(ctx)
+}
+
+// ForBPP returns the active adapter that owns bppID's catalogs.
+func (r *AdapterRegistry) ForBPP(ctx context.Context, bppID string) (*ports.Adapter, error) {
+	adapter, err := r.repo.GetByBppID(ctx, bppID)
+	if err != nil {
+		return nil, err
+	}
+	if !adapter.IsActive {
+		return nil, appError.ErrAdapterNotFound
+	}
+	return adapter, nil
+}
+
+// Serving returns the active adapters that serve domain and city and are
+// not known to be down.
+func (r *AdapterRegistry) Serving(ctx context.Context, domain, city string) ([]ports.Adapter, error) {
+	adapters, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var serving []ports.Adapter
+	for _, adapter := range adapters {
+		if adapter.IsActive && adapter.HealthStatus != ports.AdapterHealthDown && adapter.Serves(domain, city) {
+			serving = append(serving, adapter)
+		}
+	}
+	return serving, nil
+}
+
+// CheckHealth probes every active adapter and records the result.
+func (r *AdapterRegistry) CheckHealth(ctx context.Context) error {
+	adapters, err := r.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, adapter := range adapters {
+		if !adapter.IsActive {
+			continue
+		}
+		status, healthError := ports.AdapterHealthUp, ""
+		if err := r.probe(ctx, &adapter); err != nil {
+			status, healthError = ports.AdapterHealthDown, err.Error()
+		}
+		if status != adapter.HealthStatus {
+			logger.Infof(ctx, "Adapter %s (%s) is now %s", adapter.Name, adapter.ID, status)
+		}
+		if err := r.repo.UpdateHealth(ctx, adapter.ID, status, healthError, time.Now().UTC()); err != nil {
+			logger.Warnf(ctx, "Failed to record health of adapter %s: %v", adapter.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r *AdapterRegistry) probe(ctx context.Context, adapter *ports.Adapter) error {
+	ctx, cancel := context.WithTimeout(ctx, r.probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(adapter.URL, "/")+r.healthPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", adapter.APIKey)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+func applyAdapterInput(adapter *ports.Adapter, input AdapterInput) error {
+	if input.Name != nil {
+		adapter.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.URL != nil {
+		if err := validateAdapterURL("url", *input.URL); err != nil {
+			return err
+		}
+		adapter.URL = strings.TrimSpace(*input.URL)
+	}
+	if input.CallbackURL != nil {
+		if err := validateAdapterURL("callback_url", *input.CallbackURL); err != nil {
+			return err
+		}
+		adapter.CallbackURL = strings.TrimSpace(*input.CallbackURL)
+	}
+	if input.APIKey != nil {
+		adapter.APIKey = strings.TrimSpace(*input.APIKey)
+	}
+	if input.BppID != nil {
+		adapter.BppID = strings.TrimSpace(*input.BppID)
+	}
+	if input.Domains != nil {
+		adapter.Domains = joinList(input.Domains)
+	}
+	if input.Cities != nil {
+		adapter.Cities = joinList(input.Cities)
+	}
+	if input.Categories != nil {
+		adapter.Categories = joinList(input.Categories)
+	}
+	if input.AreaCodes != nil {
+		adapter.AreaCodes = joinList(input.AreaCodes)
+	}
+	if input.IsActive != nil {
+		adapter.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+func validateAdapterURL(field, value string) error {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, appError.ErrInvalidFieldFormat.Message, fmt.Sprintf("%s must be an absolute http(s) URL", field))
+	}
+	return nil
+}
+
+func joinList(values []string) string {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !strings.Contains(value, ",") {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
@@ -0,0 +1,310 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fastjson"
+
+	"adapter/internal/ports"
+	"adapter/internal/shared/ack"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// CallbackDispatcherConfig tunes delivery to buyer apps.
+type CallbackDispatcherConfig struct {
+	MaxAttempts    int
+	BackoffBase    time.Duration
+	BackoffMax     time.Duration
+	AttemptTimeout time.Duration
+	// PerBAPConcurrency bounds the requests in flight to one buyer app.
+	PerBAPConcurrency int
+	// MaxPending bounds the deliveries waiting or in flight; beyond it new
+	// deliveries fail immediately rather than holding more payloads in
+	// memory.
+	MaxPending int
+	// ClaimTimeout is how long a pending or retrying delivery may go
+	// without an update before a reprocessed callback takes it over, e.g.
+	// after the instance delivering it died. It must exceed AttemptTimeout
+	// plus BackoffMax.
+	ClaimTimeout time.Duration
+}
+
+// CallbackDispatcher forwards received on_* callbacks to the buyer app at
+// context.bap_uri + "/" + action, signed with the edge's ONDC key. Each
+// delivery is retried with exponential backoff and its status persisted.
+type CallbackDispatcher struct {
+	signer     *ondc.Signer
+	deliveries ports.DeliveryRepository
+	client     *http.Client
+	cfg        CallbackDispatcherConfig
+
+	mu       sync.Mutex
+	bapSlots map[string]chan struct{}
+
+	pending atomic.Int64
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	// closeMu orders Dispatch's wg.Add before Close's wg.Wait.
+	closeMu sync.Mutex
+	closed  bool
+}
+
+// NewCallbackDispatcher constructs a new CallbackDispatcher.
+func NewCallbackDispatcher(signer *ondc.Signer, deliveries ports.DeliveryRepository, cfg CallbackDispatcherConfig) *CallbackDispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.PerBAPConcurrency <= 0 {
+		cfg.PerBAPConcurrency = 1
+	}
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = 2 * (cfg.AttemptTimeout + cfg.BackoffMax)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CallbackDispatcher{
+		signer:     signer,
+		deliveries: deliveries,
+		client:     &http.Client{},
+		cfg:        cfg,
+		bapSlots:   make(map[string]chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Dispatch schedules delivery of callback to its buyer app and returns
+// immediately. A callback reprocessed after a restart is skipped when it
+// was delivered or is being delivered, and dispatched again otherwise.
+func (d *CallbackDispatcher) Dispatch(ctx context.Context, callback *OnSearchCallback) {
+	delivery := &ports.CallbackDelivery{
+		ID:            callback.ID,
+		TransactionID: callback.TransactionID,
+		MessageID:     callback.MessageID,
+		Action:        callback.Action,
+		BapID:         callback.BapID,
+		BapURI:        callback.BapURI,
+		BppID:         callback.BppID,
+		Status:        ports.DeliveryStatusPending,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	if callback.BapURI == "" {
+		d.fail(ctx, delivery, "context.bap_uri is missing")
+		return
+	}
+	if d.cfg.MaxPending > 0 && d.pending.Load() >= int64(d.cfg.MaxPending) {
+		d.fail(ctx, delivery, fmt.Sprintf("dispatch backlog full (%d pending)", d.cfg.MaxPending))
+		return
+	}
+
+	delivery.DispatchKey = dispatchKey(callback)
+	if !d.claim(ctx, delivery) {
+		logger.Infof(ctx, "Skipping %s for transaction_id=%s message_id=%s, already delivered or in progress", delivery.Action, delivery.TransactionID, delivery.MessageID)
+		return
+	}
+
+	d.closeMu.Lock()
+	if d.closed {
+		d.closeMu.Unlock()
+		d.fail(ctx, delivery, "dispatcher is shutting down")
+		return
+	}
+	d.pending.Add(1)
+	d.wg.Add(1)
+	d.closeMu.Unlock()
+
+	// The caller's payload may live in a request buffer that is reused
+	// once it returns.
+	payload := bytes.Clone(callback.Payload)
+	go func() {
+		defer d.wg.Done()
+		defer d.pending.Add(-1)
+		d.deliver(d.ctx, delivery, payload)
+	}()
+}
+
+// dispatchKey identifies a callback by context.message_id and action, and
+// by seller app and content since every seller answers a search, possibly
+// in several parts, under the same message_id.
+func dispatchKey(callback *OnSearchCallback) string {
+	hash := sha256.New()
+	for _, part := range []string{callback.MessageID, callback.Action, callback.BppID} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(callback.Payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// claim records the pending delivery, reporting false when the callback
+// was delivered or another delivery of it is still in progress. Without a
+// repository, or when it fails, the callback is dispatched anyway.
+func (d *CallbackDispatcher) claim(ctx context.Context, delivery *ports.CallbackDelivery) bool {
+	if d.deliveries == nil {
+		return true
+	}
+	delivery.UpdatedAt = time.Now().UTC()
+	claimed, err := d.deliveries.Claim(context.WithoutCancel(ctx), delivery, delivery.UpdatedAt.Add(-d.cfg.ClaimTimeout))
+	if err != nil {
+		logger.Warnf(ctx, "Failed to record delivery %s: %v", delivery.ID, err)
+		return true
+	}
+	return claimed
+}
+
+func (d *CallbackDispatcher) deliver(ctx context.Context, delivery *ports.CallbackDelivery, payload []byte) {
+	target := strings.TrimRight(delivery.BapURI, "/") + "/" + delivery.Action
+	for attempt := 1; ; attempt++ {
+		statusCode, err := d.attempt(ctx, delivery.BapID, target, payload)
+		delivery.Attempts = attempt
+		delivery.LastStatusCode = statusCode
+		if err == nil {
+			now := time.Now().UTC()
+			delivery.Status = ports.DeliveryStatusDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			d.save(ctx, delivery)
+			logger.Infof(ctx, "Delivered %s for transaction_id=%s to %s after %d attempts", delivery.Action, delivery.TransactionID, target, attempt)
+			return
+		}
+
+		delivery.LastError = err.Error()
+		if attempt >= d.cfg.MaxAttempts || !retryableStatus(statusCode) || ctx.Err() != nil {
+			d.fail(ctx, delivery, err.Error())
+			return
+		}
+		delivery.Status = ports.DeliveryStatusRetrying
+		d.save(ctx, delivery)
+
+		delay := backoffDelay(d.cfg.BackoffBase, attempt)
+		if d.cfg.BackoffMax > 0 && delay > d.cfg.BackoffMax {
+			delay = d.cfg.BackoffMax
+		}
+		logger.Warnf(ctx, "Delivery of %s for transaction_id=%s to %s failed (attempt %d/%d), retrying in %s: %v", delivery.Action, delivery.TransactionID, target, attempt, d.cfg.MaxAttempts, delay, err)
+		select {
+		case <-ctx.Done():
+			d.fail(ctx, delivery, fmt.Sprintf("dispatcher stopped: %v", err))
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt makes one signed POST while holding a slot for the buyer app.
+func (d *CallbackDispatcher) attempt(ctx context.Context, bapID, target string, payload []byte) (int, error) {
+	slots := d.slots(bapID)
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	defer func() { <-slots }()
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.AttemptTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", d.signer.Sign(payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("buyer app returned %s", resp.Status)
+	}
+	if fastjson.GetString(body, "message", "ack", "status") == ack.StatusNack {
+		// A NACK is the buyer app's answer, not a transient failure
+		return http.StatusBadRequest, fmt.Errorf("buyer app returned NACK: %s", fastjson.GetString(body, "error", "message"))
+	}
+	return resp.StatusCode, nil
+}
+
+// retryableStatus reports whether a failed attempt may succeed later:
+// network errors, timeouts, throttling and server errors.
+func retryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func (d *CallbackDispatcher) slots(bapID string) chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	slots, ok := d.bapSlots[bapID]
+	if !ok {
+		slots = make(chan struct{}, d.cfg.PerBAPConcurrency)
+		d.bapSlots[bapID] = slots
+	}
+	return slots
+}
+
+func (d *CallbackDispatcher) fail(ctx context.Context, delivery *ports.CallbackDelivery, reason string) {
+	delivery.Status = ports.DeliveryStatusFailed
+	delivery.LastError = reason
+	d.save(ctx, delivery)
+	logger.Warnf(ctx, "Gave up delivering %s for transaction_id=%s to bap_id=%s: %s", delivery.Action, delivery.TransactionID, delivery.BapID, reason)
+}
+
+func (d *CallbackDispatcher) save(ctx context.Context, delivery *ports.CallbackDelivery) {
+	if d.deliveries == nil {
+		return
+	}
+	delivery.UpdatedAt = time.Now().UTC()
+	if err := d.deliveries.Save(context.WithoutCancel(ctx), delivery); err != nil {
+		logger.Warnf(ctx, "Failed to record delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// Deliveries returns the delivery status of every callback for messageID.
+func (d *CallbackDispatcher) Deliveries(ctx context.Context, messageID string) ([]ports.CallbackDelivery, error) {
+	return d.deliveries.ListByMessageID(ctx, messageID)
+}
+
+// Close stops accepting deliveries and waits for pending ones. Deliveries
+// still retrying when ctx expires are abandoned and marked failed.
+func (d *CallbackDispatcher) Close(ctx context.Context) error {
+	d.closeMu.Lock()
+	d.closed = true
+	d.closeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return fmt.Errorf("callback dispatcher stopped with pending deliveries: %w", ctx.Err())
+	}
+}
+
+// HealthCheck fails while the dispatch backlog is full.
+func (d *CallbackDispatcher) HealthCheck(ctx context.Context) error {
+	if d.cfg.MaxPending > 0 && d.pending.Load() >= int64(d.cfg.MaxPending) {
+		return fmt.Errorf("callback dispatch backlog full (%d pending)", d.cfg.MaxPending)
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+package domain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"adapter/internal/ports"
+	"adapter/pkg/ondc"
+)
+
+const testAck = `{"message":{"ack":{"status":"ACK"}}}`
+
+// memoryDeliveryRepository is an in-memory ports.DeliveryRepository.
+type memoryDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[string]ports.CallbackDelivery
+	keys       map[string]string
+}
+
+func newMemoryDeliveryRepository() *memoryDeliveryRepository {
+	return &memoryDeliveryRepository{deliveries: make(map[string]ports.CallbackDelivery), keys: make(map[string]string)}
+}
+
+func (r *memoryDeliveryRepository) Save(ctx context.Context, delivery *ports.CallbackDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryDeliveryRepository) Claim(ctx context.Context, delivery *ports.CallbackDelivery, staleBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.keys[delivery.DispatchKey]; ok {
+		existing := r.deliveries[id]
+		abandoned := (existing.Status == ports.DeliveryStatusPending || existing.Status == ports.DeliveryStatusRetrying) &&
+			existing.UpdatedAt.Before(staleBefore)
+		if existing.Status != ports.DeliveryStatusFailed && !abandoned {
+			return false, nil
+		}
+		delivery.ID = id
+	}
+	r.deliveries[delivery.ID] = *delivery
+	r.keys[delivery.DispatchKey] = delivery.ID
+	return true, nil
+}
+
+func (r *memoryDeliveryRepository) ListByMessageID(ctx context.Context, messageID string) ([]ports.CallbackDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []ports.CallbackDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.MessageID == messageID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *memoryDeliveryRepository) get(id string) ports.CallbackDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deliveries[id]
+}
+
+// stubBAP is a buyer app answering each request with the next response,
+// repeating the last one.
+type stubBAP struct {
+	*httptest.Server
+	responses []stubResponse
+	// release, when set, holds every request until it is closed.
+	release chan struct{}
+
+	mu          sync.Mutex
+	requests    []stubRequest
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+type stubRequest struct {
+	path          string
+	body          []byte
+	authorization string
+}
+
+func newStubBAP(t *testing.T, responses ...stubResponse) *stubBAP {
+	t.Helper()
+	bap := &stubBAP{responses: responses}
+	bap.Server = httptest.NewServer(http.HandlerFunc(bap.serve))
+	t.Cleanup(bap.Close)
+	return bap
+}
+
+func (b *stubBAP) serve(w http.ResponseWriter, r *http.Request) {
+	current := b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+	for {
+		seen := b.maxInFlight.Load()
+		if current <= seen || b.maxInFlight.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	b.mu.Lock()
+	b.requests = append(b.requests, stubRequest{path: r.URL.Path, body: body, authorization: r.Header.Get("Authorization")})
+	response := b.responses[min(len(b.requests), len(b.responses))-1]
+	b.mu.Unlock()
+
+	if b.release != nil {
+		<-b.release
+	}
+	w.WriteHeader(response.status)
+	io.WriteString(w, response.body)
+}
+
+func (b *stubBAP) received() []stubRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]stubRequest(nil), b.requests...)
+}
+
+func newTestSigner(t *testing.T) *ondc.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	signer, err := ondc.NewSigner("edge.example.com", "key-1", base64.StdEncoding.EncodeToString(key.Seed()), time.Minute)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer
+}
+
+func newTestDispatcher(t *testing.T, repo ports.DeliveryRepository, cfg CallbackDispatcherConfig) *CallbackDispatcher {
+	t.Helper()
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 3
+	}
+	cfg.BackoffBase = time.Millisecond
+	cfg.BackoffMax = 5 * time.Millisecond
+	cfg.AttemptTimeout = 5 * time.Second
+	return NewCallbackDispatcher(newTestSigner(t), repo, cfg)
+}
+
+func dispatchCallback(bapURI, messageID string) *OnSearchCallback {
+	return &OnSearchCallback{
+		ID:            uuid.NewString(),
+		TransactionID: "t-1",
+		MessageID:     messageID,
+		Action:        "on_select",
+		BapID:         "bap.example.com",
+		BapURI:        bapURI,
+		BppID:         "bpp.example.com",
+		Payload:       []byte(fmt.Sprintf(`{"context":{"action":"on_select","message_id":%q},"message":{}}`, messageID)),
+	}
+}
+
+func closeDispatcher(t *testing.T, dispatcher *CallbackDispatcher) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dispatcher.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestCallbackDispatcherDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []stubResponse
+		wantStatus   string
+		wantAttempts int
+		wantCode     int
+	}{
+		{name: "ack", responses: []stubResponse{{200, testAck}}, wantStatus: ports.DeliveryStatusDelivered, wantAttempts: 1, wantCode: 200},
+		{name: "retryable 5xx", responses: []stubResponse{{503, ""}, {502, ""}, {200, testAck}}, wantStatus: ports.DeliveryStatusDelivered, wantAttempts: 3, wantCode: 200},
+		{name: "throttled", responses: []stubResponse{{429, ""}, {200, testAck}}, wantStatus: ports.DeliveryStatusDelivered, wantAttempts: 2, wantCode: 200},
+		{name: "5xx until attempts run out", responses: []stubResponse{{500, ""}}, wantStatus: ports.DeliveryStatusFailed, wantAttempts: 3, wantCode: 500},
+		{name: "non-retryable 4xx", responses: []stubResponse{{400, ""}, {200, testAck}}, wantStatus: ports.DeliveryStatusFailed, wantAttempts: 1, wantCode: 400},
+		{name: "nack", responses: []stubResponse{{200, `{"message":{"ack":{"status":"NACK"}},"error":{"message":"bad"}}`}}, wantStatus: ports.DeliveryStatusFailed, wantAttempts: 1, wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bap := newStubBAP(t, tt.responses...)
+			repo := newMemoryDeliveryRepository()
+			dispatcher := newTestDispatcher(t, repo, CallbackDispatcherConfig{})
+
+			callback := dispatchCallback(bap.URL+"/", "m-1")
+			dispatcher.Dispatch(context.Background(), callback)
+			closeDispatcher(t, dispatcher)
+
+			delivery := repo.get(callback.ID)
+			if delivery.Status != tt.wantStatus || delivery.Attempts != tt.wantAttempts || delivery.LastStatusCode != tt.wantCode {
+				t.Fatalf("delivery = %s after %d attempts (last %d), want %s after %d (last %d)",
+					delivery.Status, delivery.Attempts, delivery.LastStatusCode, tt.wantStatus, tt.wantAttempts, tt.wantCode)
+			}
+			if (delivery.DeliveredAt != nil) != (tt.wantStatus == ports.DeliveryStatusDelivered) {
+				t.Errorf("DeliveredAt = %v for status %s", delivery.DeliveredAt, delivery.Status)
+			}
+			requests := bap.received()
+			if len(requests) != tt.wantAttempts {
+				t.Fatalf("buyer app received %d requests, want %d", len(requests), tt.wantAttempts)
+			}
+			for _, request := range requests {
+				if request.path != "/on_select" {
+					t.Errorf("path = %s, want /on_select", request.path)
+				}
+			}
+		})
+	}
+}
+
+func TestCallbackDispatcherSignsPayload(t *testing.T) {
+	bap := newStubBAP(t, stubResponse{200, testAck})
+	dispatcher := newTestDispatcher(t, nil, CallbackDispatcherConfig{})
+
+	callback := dispatchCallback(bap.URL, "m-1")
+	sent := string(callback.Payload)
+	dispatcher.Dispatch(context.Background(), callback)
+	// The caller may reuse its buffer as soon as Dispatch returns.
+	for i := range callback.Payload {
+		callback.Payload[i] = 'x'
+	}
+	closeDispatcher(t, dispatcher)
+
+	requests := bap.received()
+	if len(requests) != 1 {
+		t.Fatalf("buyer app received %d requests, want 1", len(requests))
+	}
+	request := requests[0]
+	if string(request.body) != sent {
+		t.Fatalf("body = %s, want %s", request.body, sent)
+	}
+	params, err := ondc.VerifyAuthorization(request.authorization, request.body, dispatcher.signer.PublicKey())
+	if err != nil {
+		t.Fatalf("signature does not verify: %v (%s)", err, request.authorization)
+	}
+	if params.SubscriberID != "edge.example.com" || params.UniqueKeyID != "key-1" {
+		t.Fatalf("keyId = %s|%s, want edge.example.com|key-1", params.SubscriberID, params.UniqueKeyID)
+	}
+	if _, err := ondc.VerifyAuthorization(request.authorization, []byte(`{"tampered":true}`), dispatcher.signer.PublicKey()); err == nil {
+		t.Fatal("signature verified against a different body")
+	}
+}
+
+func TestCallbackDispatcherShedsBeyondMaxPending(t *testing.T) {
+	bap := newStubBAP(t, stubResponse{200, testAck})
+	bap.release = make(chan struct{})
+	repo := newMemoryDeliveryRepository()
+	dispatcher := newTestDispatcher(t, repo, CallbackDispatcherConfig{MaxPending: 2, PerBAPConcurrency: 2})
+
+	first, second, shed := dispatchCallback(bap.URL, "m-1"), dispatchCallback(bap.URL, "m-2"), dispatchCallback(bap.URL, "m-3")
+	dispatcher.Dispatch(context.Background(), first)
+	dispatcher.Dispatch(context.Background(), second)
+	dispatcher.Dispatch(context.Background(), shed)
+
+	if err := dispatcher.HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck passed with a full backlog")
+	}
+	if delivery := repo.get(shed.ID); delivery.Status != ports.DeliveryStatusFailed || !strings.Contains(delivery.LastError, "backlog full") {
+		t.Fatalf("shed delivery = %+v, want failed for a full backlog", delivery)
+	}
+
+	close(bap.release)
+	closeDispatcher(t, dispatcher)
+	for _, callback := range []*OnSearchCallback{first, second} {
+		if delivery := repo.get(callback.ID); delivery.Status != ports.DeliveryStatusDelivered {
+			t.Errorf("delivery of %s = %s, want delivered", callback.MessageID, delivery.Status)
+		}
+	}
+	if err := dispatcher.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck failed once drained: %v", err)
+	}
+}
+
+func TestCallbackDispatcherBoundsConcurrencyPerBAP(t *testing.T) {
+	bap := newStubBAP(t, stubResponse{200, testAck})
+	bap.release = make(chan struct{})
+	dispatcher := newTestDispatcher(t, nil, CallbackDispatcherConfig{PerBAPConcurrency: 2})
+
+	for i := 0; i < 6; i++ {
+		dispatcher.Dispatch(context.Background(), dispatchCallback(bap.URL, fmt.Sprintf("m-%d", i)))
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for bap.inFlight.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give a third request the chance to slip through if slots leak.
+	time.Sleep(50 * time.Millisecond)
+	close(bap.release)
+	closeDispatcher(t, dispatcher)
+
+	if got := bap.maxInFlight.Load(); got != 2 {
+		t.Fatalf("max requests in flight to one buyer app = %d, want 2", got)
+	}
+	if got := len(bap.received()); got != 6 {
+		t.Fatalf("buyer app received %d requests, want 6", got)
+	}
+}
+
+func TestCallbackDispatcherSkipsDuplicates(t *testing.T) {
+	bap := newStubBAP(t, stubResponse{200, testAck})
+	repo := newMemoryDeliveryRepository()
+	dispatcher := newTestDispatcher(t, repo, CallbackDispatcherConfig{})
+
+	callback := dispatchCallback(bap.URL, "m-1")
+	dispatcher.Dispatch(context.Background(), callback)
+	// The same callback received again, e.g. reprocessed after a restart.
+	retried := *callback
+	retried.ID = uuid.NewString()
+	dispatcher.Dispatch(context.Background(), &retried)
+	// Another seller's answer to the same search is a different callback.
+	other := *callback
+	other.ID = uuid.NewString()
+	other.BppID = "other-bpp.example.com"
+	dispatcher.Dispatch(context.Background(), &other)
+	closeDispatcher(t, dispatcher)
+
+	if got := len(bap.received()); got != 2 {
+		t.Fatalf("buyer app received %d requests, want 2", got)
+	}
+	if _, ok := repo.deliveries[retried.ID]; ok {
+		t.Fatal("duplicate callback was recorded as a delivery")
+	}
+}
+
+func TestCallbackDispatcherTakesOverClaims(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		age      time.Duration
+		wantSent bool
+	}{
+		{name: "delivered", status: ports.DeliveryStatusDelivered, age: time.Hour, wantSent: false},
+		{name: "pending in progress", status: ports.DeliveryStatusPending, age: time.Second, wantSent: false},
+		{name: "retrying in progress", status: ports.DeliveryStatusRetrying, age: time.Second, wantSent: false},
+		{name: "pending abandoned", status: ports.DeliveryStatusPending, age: time.Hour, wantSent: true},
+		{name: "retrying abandoned", status: ports.DeliveryStatusRetrying, age: time.Hour, wantSent: true},
+		{name: "failed", status: ports.DeliveryStatusFailed, age: time.Second, wantSent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bap := newStubBAP(t, stubResponse{200, testAck})
+			repo := newMemoryDeliveryRepository()
+			dispatcher := newTestDispatcher(t, repo, CallbackDispatcherConfig{})
+
+			// A row left behind by an earlier attempt, e.g. by an instance
+			// that crashed before delivering.
+			callback := dispatchCallback(bap.URL, "m-1")
+			existing := ports.CallbackDelivery{
+				ID:          uuid.NewString(),
+				DispatchKey: dispatchKey(callback),
+				Status:      tt.status,
+				UpdatedAt:   time.Now().UTC().Add(-tt.age),
+			}
+			repo.Save(context.Background(), &existing)
+			repo.keys[existing.DispatchKey] = existing.ID
+
+			dispatcher.Dispatch(context.Background(), callback)
+			closeDispatcher(t, dispatcher)
+
+			if sent := len(bap.received()) == 1; sent != tt.wantSent {
+				t.Fatalf("callback sent = %v, want %v", sent, tt.wantSent)
+			}
+			got := repo.get(existing.ID)
+			if tt.wantSent && got.Status != ports.DeliveryStatusDelivered {
+				t.Fatalf("taken over delivery status = %q, want delivered", got.Status)
+			}
+			if !tt.wantSent && got.Status != tt.status {
+				t.Fatalf("skipped delivery status = %q, want %q", got.Status, tt.status)
+			}
+			if len(repo.deliveries) != 1 {
+				t.Fatalf("recorded %d deliveries, want the existing row only", len(repo.deliveries))
+			}
+		})
+	}
+}
+
+func TestCallbackDispatcherWithoutBapURI(t *testing.T) {
+	repo := newMemoryDeliveryRepository()
+	dispatcher := newTestDispatcher(t, repo, CallbackDispatcherConfig{})
+
+	callback := dispatchCallback("", "m-1")
+	dispatcher.Dispatch(context.Background(), callback)
+	closeDispatcher(t, dispatcher)
+
+	if delivery := repo.get(callback.ID); delivery.Status != ports.DeliveryStatusFailed || delivery.Attempts != 0 {
+		t.Fatalf("delivery = %+v, want failed without attempts", delivery)
+	}
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// Timing enforcement modes.
+const (
+	TimingModeOff    = "off"
+	TimingModeFlag   = "flag"
+	TimingModeReject = "reject"
+)
+
+// TimingRule is the timing policy for one domain.
+type TimingRule struct {
+	Mode    string
+	MaxSkew time.Duration
+}
+
+// TimingPolicy checks context.timestamp against the receive time and the
+// callback against the TTL of the request it answers.
+type TimingPolicy struct {
+	defaults          TimingRule
+	domains           map[string]TimingRule
+	defaultRequestTTL time.Duration
+	requests          ports.TransactionRepository
+}
+
+// NewTimingPolicy parses per-domain overrides of the form
+// "ONDC:RET11=reject:60,ONDC:RET10=off", where the optional number is the
+// allowed clock skew in seconds.
+func NewTimingPolicy(mode string, maxSkew time.Duration, domainSpec, defaultRequestTTL string, requests ports.TransactionRepository) (*TimingPolicy, error) {
+	if err := validateTimingMode(mode); err != nil {
+		return nil, err
+	}
+	ttl, err := ondc.ParseDuration(defaultRequestTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default request ttl: %w", err)
+	}
+
+	policy := &TimingPolicy{
+		defaults:          TimingRule{Mode: mode, MaxSkew: maxSkew},
+		domains:           make(map[string]TimingRule),
+		defaultRequestTTL: ttl,
+		requests:          requests,
+	}
+	for _, entry := range strings.Split(domainSpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		domain, value, ok := strings.Cut(entry, "=")
+		if !ok || domain == "" {
+			return nil, fmt.Errorf("invalid timing policy %q, expected domain=mode[:skewSeconds]", entry)
+		}
+		rule := policy.defaults
+		modeValue, skewValue, hasSkew := strings.Cut(value, ":")
+		rule.Mode = modeValue
+		if err := validateTimingMode(rule.Mode); err != nil {
+			return nil, fmt.Errorf("invalid timing policy %q: %w", entry, err)
+		}
+		if hasSkew {
+			seconds, err := strconv.Atoi(skewValue)
+			if err != nil || seconds < 0 {
+				return nil, fmt.Errorf("invalid skew in timing policy %q", entry)
+			}
+			rule.MaxSkew = time.Duration(seconds) * time.Second
+		}
+		policy.domains[domain] = rule
+	}
+	return policy, nil
+}
+
+func validateTimingMode(mode string) error {
+	switch mode {
+	case TimingModeOff, TimingModeFlag, TimingModeReject:
+		return nil
+	}
+	return fmt.Errorf("unknown timing mode %q, expected off, flag or reject", mode)
+}
+
+// Rule returns the rule that applies to domain.
+func (p *TimingPolicy) Rule(domain string) TimingRule {
+	if rule, ok := p.domains[domain]; ok {
+		return rule
+	}
+	return p.defaults
+}
+
+// Check evaluates callback and records the violations on it. In reject
+// mode a violation is returned as an error; in flag mode the callback is
+// accepted and the violations travel with it.
+func (p *TimingPolicy) Check(ctx context.Context, callback *OnSearchCallback) error {
+	callback.timingChecked = true
+	rule := p.Rule(callback.Domain)
+	if rule.Mode == TimingModeOff {
+		return nil
+	}
+
+	violations := p.violations(ctx, callback, rule)
+	callback.TimingViolations = violations
+	if len(violations) == 0 {
+		return nil
+	}
+
+	logger.Warnf(ctx, "Timing check failed for %s transaction_id=%s: %s", callback.Action, callback.TransactionID, strings.Join(violations, "; "))
+	if rule.Mode == TimingModeReject {
+		return appError.NewCustomError(
+			appError.ErrStaleCallback.HTTPCode,
+			appError.ErrStaleCallback.Code,
+			appError.ErrStaleCallback.Message,
+			violations,
+		)
+	}
+	return nil
+}
+
+func (p *TimingPolicy) violations(ctx context.Context, callback *OnSearchCallback, rule TimingRule) []string {
+	var violations []string
+
+	if callback.TTL != "" {
+		if _, err := ondc.ParseDuration(callback.TTL); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+
+	timestamp, err := ondc.ParseTimestamp(callback.Timestamp)
+	if err != nil {
+		return append(violations, "missing or invalid context.timestamp")
+	}
+	if skew := callback.ReceivedAt.Sub(timestamp); skew > rule.MaxSkew || -skew > rule.MaxSkew {
+		violations = append(violations, fmt.Sprintf("context.timestamp is %s from receive time, allowed skew is %s", skew.Round(time.Millisecond), rule.MaxSkew))
+	}
+
+	if !strings.HasPrefix(callback.Action, "on_") || p.requests == nil {
+		return violations
+	}
+	request, err := p.requests.FindRequest(ctx, callback.TransactionID, callback.MessageID, strings.TrimPrefix(callback.Action, "on_"))
+	if err != nil {
+		logger.Warnf(ctx, "Failed to look up request for %s transaction_id=%s: %v", callback.Action, callback.TransactionID, err)
+		return violations
+	}
+	if request == nil || request.ContextTimestamp == nil {
+		return violations
+	}
+
+	ttl := p.defaultRequestTTL
+	if request.TTL != "" {
+		if parsed, err := ondc.ParseDuration(request.TTL); err == nil {
+			ttl = parsed
+		}
+	}
+	if deadline := request.ContextTimestamp.Add(ttl); callback.ReceivedAt.After(deadline.Add(rule.MaxSkew)) {
+		callback.Late = true
+		violations = append(violations, fmt.Sprintf("received %s after the %s ttl of %s expired", callback.ReceivedAt.Sub(deadline).Round(time.Millisecond), ttl, request.Action))
+	}
+	return violations
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// requestLookup is a ports.TransactionRepository that only answers
+// FindRequest.
+type requestLookup struct {
+	ports.TransactionRepository
+	request *ports.Transaction
+	err     error
+}
+
+func (r *requestLookup) FindRequest(ctx context.Context, transactionID, messageID, action string) (*ports.Transaction, error) {
+	if r.request == nil || r.request.Action != action {
+		return nil, r.err
+	}
+	return r.request, r.err
+}
+
+func TestNewTimingPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		domainSpec string
+		ttl        string
+		wantErr    bool
+		domain     string
+		wantRule   TimingRule
+	}{
+		{name: "defaults", mode: TimingModeFlag, ttl: "PT30S", domain: "ONDC:RET10", wantRule: TimingRule{Mode: TimingModeFlag, MaxSkew: 30 * time.Second}},
+		{name: "override with skew", mode: TimingModeFlag, domainSpec: "ONDC:RET11=reject:60", ttl: "PT30S", domain: "ONDC:RET11", wantRule: TimingRule{Mode: TimingModeReject, MaxSkew: time.Minute}},
+		{name: "override keeps default skew", mode: TimingModeFlag, domainSpec: " ONDC:RET11=off , ", ttl: "PT30S", domain: "ONDC:RET11", wantRule: TimingRule{Mode: TimingModeOff, MaxSkew: 30 * time.Second}},
+		{name: "other domain uses defaults", mode: TimingModeOff, domainSpec: "ONDC:RET11=reject", ttl: "PT30S", domain: "ONDC:RET10", wantRule: TimingRule{Mode: TimingModeOff, MaxSkew: 30 * time.Second}},
+		{name: "unknown mode", mode: "strict", ttl: "PT30S", wantErr: true},
+		{name: "unknown domain mode", mode: TimingModeFlag, domainSpec: "ONDC:RET11=strict", ttl: "PT30S", wantErr: true},
+		{name: "missing domain", mode: TimingModeFlag, domainSpec: "=reject", ttl: "PT30S", wantErr: true},
+		{name: "negative skew", mode: TimingModeFlag, domainSpec: "ONDC:RET11=reject:-1", ttl: "PT30S", wantErr: true},
+		{name: "invalid ttl", mode: TimingModeFlag, ttl: "30s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTimingPolicy(tt.mode, 30*time.Second, tt.domainSpec, tt.ttl, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && policy.Rule(tt.domain) != tt.wantRule {
+				t.Fatalf("Rule(%s) = %+v, want %+v", tt.domain, policy.Rule(tt.domain), tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestTimingPolicyCheck(t *testing.T) {
+	receivedAt := time.Date(2025, 1, 8, 8, 0, 30, 0, time.UTC)
+	requestAt := receivedAt.Add(-20 * time.Second)
+	search := &ports.Transaction{Action: "search", TTL: "PT30S", ContextTimestamp: &requestAt}
+
+	tests := []struct {
+		name       string
+		mode       string
+		action     string
+		timestamp  string
+		ttl        string
+		request    *ports.Transaction
+		lookupErr  error
+		wantReject bool
+		wantLate   bool
+		// violations are substrings expected in order; none means on time.
+		violations []string
+	}{
+		{name: "on time", mode: TimingModeReject, action: "on_search", timestamp: "2025-01-08T08:00:29Z", request: search},
+		{name: "skew within limit", mode: TimingModeReject, action: "on_search", timestamp: "2025-01-08T08:00:40Z"},
+		{name: "timestamp ahead", mode: TimingModeReject, action: "on_search", timestamp: "2025-01-08T08:01:30Z", wantReject: true,
+			violations: []string{"context.timestamp is -1m0s from receive time, allowed skew is 10s"}},
+		{name: "timestamp behind", mode: TimingModeFlag, action: "on_search", timestamp: "2025-01-08T07:59:30Z",
+			violations: []string{"context.timestamp is 1m0s from receive time"}},
+		{name: "missing timestamp", mode: TimingModeReject, action: "on_search", wantReject: true,
+			violations: []string{"missing or invalid context.timestamp"}},
+		{name: "invalid ttl", mode: TimingModeFlag, action: "on_search", timestamp: "2025-01-08T08:00:30Z", ttl: "30 seconds",
+			violations: []string{"invalid ISO-8601 duration"}},
+		{name: "after request ttl", mode: TimingModeFlag, action: "on_search", timestamp: "2025-01-08T08:00:30Z", wantLate: true,
+			request:    &ports.Transaction{Action: "search", TTL: "PT5S", ContextTimestamp: &requestAt},
+			violations: []string{"received 15s after the 5s ttl of search expired"}},
+		{name: "late rejected", mode: TimingModeReject, action: "on_search", timestamp: "2025-01-08T08:00:30Z", wantLate: true, wantReject: true,
+			request:    &ports.Transaction{Action: "search", TTL: "PT5S", ContextTimestamp: &requestAt},
+			violations: []string{"ttl of search expired"}},
+		{name: "default ttl without request ttl", mode: TimingModeFlag, action: "on_search", timestamp: "2025-01-08T08:00:30Z", wantLate: true,
+			request: &ports.Transaction{Action: "search", ContextTimestamp: &requestAt}, violations: []string{"the 1s ttl of search"}},
+		{name: "unknown request", mode: TimingModeReject, action: "on_select", timestamp: "2025-01-08T08:00:30Z", request: search},
+		{name: "lookup failure is not a violation", mode: TimingModeReject, action: "on_search", timestamp: "2025-01-08T08:00:30Z",
+			lookupErr: errors.New("database down")},
+		{name: "requests are not checked against a ttl", mode: TimingModeReject, action: "search", timestamp: "2025-01-08T08:00:30Z",
+			request: &ports.Transaction{Action: "search", TTL: "PT1S", ContextTimestamp: &requestAt}},
+		{name: "off", mode: TimingModeOff, action: "on_search", timestamp: "2020-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTimingPolicy(tt.mode, 10*time.Second, "", "PT1S", &requestLookup{request: tt.request, err: tt.lookupErr})
+			if err != nil {
+				t.Fatalf("NewTimingPolicy: %v", err)
+			}
+			callback := &OnSearchCallback{
+				Domain:        "ONDC:RET10",
+				Action:        tt.action,
+				TransactionID: "t-1",
+				MessageID:     "m-1",
+				Timestamp:     tt.timestamp,
+				TTL:           tt.ttl,
+				ReceivedAt:    receivedAt,
+			}
+
+			err = policy.Check(context.Background(), callback)
+			if tt.wantReject {
+				var customErr *appError.CustomError
+				if !errors.As(err, &customErr) || customErr.Code != appError.ErrStaleCallback.Code {
+					t.Fatalf("err = %v, want %s", err, appError.ErrStaleCallback.Code)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if callback.Late != tt.wantLate {
+				t.Errorf("Late = %v, want %v", callback.Late, tt.wantLate)
+			}
+			if len(callback.TimingViolations) != len(tt.violations) {
+				t.Fatalf("violations = %q, want %q", callback.TimingViolations, tt.violations)
+			}
+			for i, want := range tt.violations {
+				if !strings.Contains(callback.TimingViolations[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, callback.TimingViolations[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/events"
+	"adapter/pkg/ondc"
+)
+
+// CatalogAggregatorConfig tunes the aggregation windows.
+type CatalogAggregatorConfig struct {
+	// DefaultWindow applies when neither the search nor the part carries a
+	// context.ttl.
+	DefaultWindow time.Duration
+	// MaxWindow caps the window derived from the TTL.
+	MaxWindow time.Duration
+	// Grace is added to every window for parts still in flight.
+	Grace time.Duration
+	// ClaimTimeout is how long an aggregate may stay closing before another
+	// instance claims it again.
+	ClaimTimeout time.Duration
+	// BatchSize bounds the aggregates closed per scan.
+	BatchSize int
+}
+
+// CatalogAggregator groups the on_search parts a large seller sends for
+// one search, keyed by (transaction_id, message_id, bpp_id). When the
+// search's TTL window closes, the parts are merged into one catalog object
+// and a pointer flagged complete is published next to the per-part
+// pointers. Aggregates live in the database, so parts received by
+// different instances are combined.
+type CatalogAggregator struct {
+	repo          ports.AggregateRepository
+	transactions  ports.TransactionRepository
+	storage       ports.ObjectStorage
+	publisher     ports.EventPublisher
+	onSearchTopic string
+	eventSource   string
+	cfg           CatalogAggregatorConfig
+}
+
+// NewCatalogAggregator constructs a new CatalogAggregator.
+func NewCatalogAggregator(
+	repo ports.AggregateRepository,
+	transactions ports.TransactionRepository,
+	storage ports.ObjectStorage,
+	publisher ports.EventPublisher,
+	onSearchTopic string,
+	eventSource string,
+	cfg CatalogAggregatorConfig,
+) *CatalogAggregator {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	return &CatalogAggregator{
+		repo:          repo,
+		transactions:  transactions,
+		storage:       storage,
+		publisher:     publisher,
+		onSearchTopic: onSearchTopic,
+		eventSource:   eventSource,
+		cfg:           cfg,
+	}
+}
+
+// AddPart counts a stored on_search part. The first part opens the window,
+// which closes the search's TTL after the search was received, or the
+// part's own TTL after it arrived when no search was recorded.
+func (a *CatalogAggregator) AddPart(ctx context.Context, callback *OnSearchCallback) error {
+	if callback.BppID == "" {
+		return fmt.Errorf("context.bpp_id is missing")
+	}
+
+	opened, ttl := callback.ReceivedAt, callback.TTL
+	request, err := a.transactions.FindRequest(ctx, callback.TransactionID, callback.MessageID, "search")
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		opened, ttl = request.ReceivedAt, request.TTL
+	}
+	window := a.cfg.DefaultWindow
+	if ttl != "" {
+		if parsed, err := ondc.ParseDuration(ttl); err == nil {
+			window = parsed
+		}
+	}
+	if a.cfg.MaxWindow > 0 && window > a.cfg.MaxWindow {
+		window = a.cfg.MaxWindow
+	}
+
+	return a.repo.AddPart(ctx, &ports.CatalogAggregate{
+		TransactionID: callback.TransactionID,
+		MessageID:     callback.MessageID,
+		BppID:         callback.BppID,
+		Domain:        callback.Domain,
+		City:          callback.City,
+		BapID:         callback.BapID,
+		CoreVersion:   callback.CoreVersion,
+		Status:        ports.AggregateStatusOpen,
+		Parts:         1,
+		FirstPartAt:   callback.ReceivedAt,
+		ClosesAt:      opened.Add(window + a.cfg.Grace).UTC(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+}
+
+// CloseDue closes the aggregates whose window has expired and returns how
+// many were claimed.
+func (a *CatalogAggregator) CloseDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	aggregates, err := a.repo.ClaimDue(ctx, now, now.Add(-a.cfg.ClaimTimeout), a.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range aggregates {
+		aggregate := &aggregates[i]
+		if err := a.close(ctx, aggregate); err != nil {
+			logger.Warnf(ctx, "Failed to aggregate on_search parts of transaction_id=%s message_id=%s bpp_id=%s: %v",
+				aggregate.TransactionID, aggregate.MessageID, aggregate.BppID, err)
+			aggregate.Status, aggregate.Error = ports.AggregateStatusFailed, err.Error()
+		}
+		if err := a.repo.Finish(ctx, aggregate); err != nil {
+			logger.Warnf(ctx, "Failed to record aggregate of transaction_id=%s bpp_id=%s: %v", aggregate.TransactionID, aggregate.BppID, err)
+		}
+	}
+	return len(aggregates), nil
+}
+
+// Aggregates returns the aggregates of a transaction.
+func (a *CatalogAggregator) Aggregates(ctx context.Context, transactionID string) ([]ports.CatalogAggregate, error) {
+	return a.repo.ListByTransactionID(ctx, transactionID)
+}
+
+// close merges the stored parts of an aggregate in arrival order, uploads
+// the consolidated catalog and publishes its complete pointer.
+func (a *CatalogAggregator) close(ctx context.Context, aggregate *ports.CatalogAggregate) error {
+	records, err := a.transactions.ListByTransactionID(ctx, aggregate.TransactionID, aggregate.BppID)
+	if err != nil {
+		return err
+	}
+
+	merge := newCatalogMerge()
+	parts := 0
+	for _, record := range records {
+		if record.Action != "on_search" || record.MessageID != aggregate.MessageID || record.ObjectKey == "" {
+			continue
+		}
+		payload, err := a.storage.Download(ctx, record.ObjectKey)
+		if err != nil {
+			return fmt.Errorf("failed to download part %s: %w", record.ObjectKey, err)
+		}
+		found, err := merge.add(payload, "")
+		if err != nil {
+			return fmt.Errorf("failed to read part %s: %w", record.ObjectKey, err)
+		}
+		if found {
+			parts++
+		}
+	}
+	if parts == 0 {
+		return fmt.Errorf("no stored part with a catalog")
+	}
+
+	consolidated, err := merge.build()
+	if err != nil {
+		return err
+	}
+	objectKey := fmt.Sprintf(
+		"ondc/%s/on_search/complete/%s_%s_%s.json",
+		strings.ReplaceAll(aggregate.Domain, ":", "_"),
+		aggregate.TransactionID,
+		aggregate.MessageID,
+		uuid.NewString(),
+	)
+	uploadedObjectKey, err := a.storage.Upload(ctx, objectKey, consolidated, "application/json")
+	if err != nil {
+		return fmt.Errorf("failed to upload consolidated catalog: %w", err)
+	}
+
+	checksum := sha256.Sum256(consolidated)
+	pointer := events.OnSearchPointer{
+		Storage:       "minio",
+		Bucket:        a.storage.GetBucket(),
+		ObjectKey:     uploadedObjectKey,
+		ContentType:   "application/json",
+		SizeBytes:     int64(len(consolidated)),
+		SHA256:        hex.EncodeToString(checksum[:]),
+		Domain:        aggregate.Domain,
+		Action:        "on_search",
+		TransactionID: aggregate.TransactionID,
+		MessageID:     aggregate.MessageID,
+		BapID:         aggregate.BapID,
+		BppID:         aggregate.BppID,
+		City:          aggregate.City,
+		CoreVersion:   aggregate.CoreVersion,
+		Mode:          ports.CatalogModeFull,
+		Complete:      true,
+		Parts:         parts,
+	}
+	event := events.NewEnvelope(a.eventSource, events.OnSearchPointerType, aggregate.TransactionID, events.OnSearchPointerSchemaPath, pointer)
+	value, headers, err := event.Encode(pointerHeaders(pointer)...)
+	if err != nil {
+		return fmt.Errorf("failed to serialize complete pointer: %w", err)
+	}
+	if err := a.publisher.Publish(ctx, a.onSearchTopic, []byte(aggregate.TransactionID), value, headers...); err != nil {
+		return fmt.Errorf("failed to publish complete pointer: %w", err)
+	}
+
+	completedAt := time.Now().UTC()
+	aggregate.Status = ports.AggregateStatusComplete
+	aggregate.Bucket = a.storage.GetBucket()
+	aggregate.ObjectKey = uploadedObjectKey
+	aggregate.Error = ""
+	aggregate.CompletedAt = &completedAt
+	logger.Infof(ctx, "Published complete on_search of transaction_id=%s bpp_id=%s from %d parts with %d items",
+		aggregate.TransactionID, aggregate.BppID, parts, merge.itemCount())
+	return nil
+}
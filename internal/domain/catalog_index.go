@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// CatalogIndex keeps the catalogs sellers send through on_search and
+// answers buyer searches from them, so a search for a BPP whose catalog is
+// fresh does not have to be forwarded to its adapter.
+type CatalogIndex struct {
+	repo         ports.CatalogRepository
+	transactions ports.TransactionRepository
+	maxStaleness time.Duration
+}
+
+// NewCatalogIndex constructs a new CatalogIndex. A BPP is only answered
+// locally while every matching provider was indexed within maxStaleness.
+func NewCatalogIndex(repo ports.CatalogRepository, transactions ports.TransactionRepository, maxStaleness time.Duration) *CatalogIndex {
+	return &CatalogIndex{
+		repo:         repo,
+		transactions: transactions,
+		maxStaleness: maxStaleness,
+	}
+}
+
+// Index stores the providers of an on_search callback. A full catalog
+// pushed without a search replaces each provider's items; incremental
+// catalogs and answers to a search, which may be filtered by the buyer's
+// intent, are merged instead.
+func (c *CatalogIndex) Index(ctx context.Context, callback *OnSearchCallback) error {
+	if callback.BppID == "" {
+		return fmt.Errorf("context.bpp_id is missing")
+	}
+
+	// Deltas are merged, as are answers to searches
+	replace := callback.CatalogMode != ports.CatalogModeIncremental
+	if replace && c.transactions != nil {
+		request, err := c.transactions.FindRequest(ctx, callback.TransactionID, callback.MessageID, "search")
+		if err != nil {
+			return err
+		}
+		replace = request == nil
+	}
+
+	now := time.Now().UTC()
+	providers, items := 0, 0
+	err := ondc.EachRawProvider(callback.Payload, func(header *ondc.CatalogHeader, raw *ondc.RawProvider) error {
+		if raw.Provider.ID == "" {
+			return nil
+		}
+		provider := &ports.CatalogProvider{
+			BppID:           callback.BppID,
+			ProviderID:      raw.Provider.ID,
+			Domain:          callback.Domain,
+			City:            callback.City,
+			BppURI:          callback.BppURI,
+			CoreVersion:     callback.CoreVersion,
+			Name:            raw.Provider.Descriptor.Name,
+			BppDescriptor:   string(header.Descriptor),
+			BppFulfillments: string(header.Fulfillments),
+			Provider:        string(raw.JSON),
+			SourceID:        callback.ID,
+			IndexedAt:       now,
+		}
+
+		// A repeated item id keeps its last occurrence
+		position := make(map[string]int, len(raw.Provider.Items))
+		catalogItems := make([]ports.CatalogItem, 0, len(raw.Provider.Items))
+		for i, item := range raw.Provider.Items {
+			if item.ID == "" || i >= len(raw.Items) {
+				continue
+			}
+			entry := ports.CatalogItem{
+				BppID:       callback.BppID,
+				ProviderID:  raw.Provider.ID,
+				ItemID:      item.ID,
+				Name:        item.Descriptor.Name,
+				CategoryIDs: strings.Join(item.Categories(), ","),
+				Item:        string(raw.Items[i]),
+				IndexedAt:   now,
+			}
+			if at, ok := position[item.ID]; ok {
+				catalogItems[at] = entry
+				continue
+			}
+			position[item.ID] = len(catalogItems)
+			catalogItems = append(catalogItems, entry)
+		}
+
+		if err := c.repo.SaveProvider(ctx, provider, catalogItems, replace); err != nil {
+			return err
+		}
+		providers++
+		items += len(catalogItems)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Infof(ctx, "Indexed %d providers and %d items of bpp_id=%s (replace=%t)", providers, items, callback.BppID, replace)
+	return nil
+}
+
+// Answer builds the on_search responses of bppID for a search, one per
+// matching provider. fresh is false when the BPP has no catalog indexed for
+// the search's domain and city or a candidate provider is older than the
+// staleness limit; the search must then be forwarded live. A fresh index
+// with no match returns no responses.
+func (c *CatalogIndex) Answer(ctx context.Context, request *OnSearchCallback, search *ondc.Search, bppID string) (responses [][]byte, fresh bool, err error) {
+	intent := search.Message.Intent
+	providers, err := c.repo.ListProviders(ctx, bppID, request.Domain, request.City, intent.ProviderID())
+	if err != nil {
+		return nil, false, err
+	}
+	if len(providers) == 0 {
+		return nil, false, nil
+	}
+
+	cutoff := time.Now().UTC().Add(-c.maxStaleness)
+	for _, provider := range providers {
+		if provider.IndexedAt.Before(cutoff) {
+			logger.Infof(ctx, "Catalog of provider %s of bpp_id=%s is stale (indexed %s)", provider.ProviderID, bppID, provider.IndexedAt.Format(time.RFC3339))
+			return nil, false, nil
+		}
+	}
+
+	providerName := ""
+	if intent.Provider != nil {
+		providerName = strings.ToLower(strings.TrimSpace(intent.Provider.Descriptor.Name))
+	}
+	candidates := make(map[string]*ports.CatalogProvider, len(providers))
+	ids := make([]string, 0, len(providers))
+	for i := range providers {
+		provider := &providers[i]
+		if providerName != "" && !strings.Contains(strings.ToLower(provider.Name), providerName) {
+			continue
+		}
+		var decoded ondc.Provider
+		if err := json.Unmarshal([]byte(provider.Provider), &decoded); err != nil {
+			logger.Warnf(ctx, "Skipping unreadable indexed provider %s of bpp_id=%s: %v", provider.ProviderID, bppID, err)
+			continue
+		}
+		if !decoded.Serves(intent.GPS(), intent.AreaCode()) {
+			continue
+		}
+		candidates[provider.ProviderID] = provider
+		ids = append(ids, provider.ProviderID)
+	}
+	if len(ids) == 0 {
+		return nil, true, nil
+	}
+
+	items, err := c.repo.SearchItems(ctx, ports.CatalogItemQuery{
+		BppID:       bppID,
+		ProviderIDs: ids,
+		Text:        intent.ItemName(),
+		CategoryID:  intent.CategoryID(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	byProvider := make(map[string][]ports.CatalogItem)
+	for _, item := range items {
+		byProvider[item.ProviderID] = append(byProvider[item.ProviderID], item)
+	}
+
+	for _, id := range ids {
+		provider := candidates[id]
+		matched := byProvider[id]
+		if len(matched) == 0 {
+			continue
+		}
+		entry := onSearchProvider{ID: provider.ProviderID, JSON: provider.Provider}
+		for _, item := range matched {
+			entry.Items = append(entry.Items, item.Item)
+		}
+		response, err := buildOnSearch(request.Payload, provider.BppID, provider.BppURI, provider.BppDescriptor, provider.BppFulfillments, []onSearchProvider{entry})
+		if err != nil {
+			return nil, false, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, true, nil
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// CatalogMaterializer tracks the catalog versions received for each
+// provider and rebuilds a provider's current catalog from its latest full
+// version and the incremental (catalog_inc) deltas received since.
+type CatalogMaterializer struct {
+	versions     ports.CatalogVersionRepository
+	storage      ports.ObjectStorage
+	transactions ports.TransactionRepository
+}
+
+// NewCatalogMaterializer constructs a new CatalogMaterializer.
+func NewCatalogMaterializer(versions ports.CatalogVersionRepository, storage ports.ObjectStorage, transactions ports.TransactionRepository) *CatalogMaterializer {
+	return &CatalogMaterializer{
+		versions:     versions,
+		storage:      storage,
+		transactions: transactions,
+	}
+}
+
+// Track classifies a stored on_search as full or incremental, sets
+// callback.CatalogMode and records one version per provider. Incremental
+// versions, and further parts of a full catalog split across several
+// on_search of the same transaction_id and message_id, are linked to the
+// first part of the provider's latest full catalog. Tracking the same
+// callback again records nothing new.
+func (m *CatalogMaterializer) Track(ctx context.Context, callback *OnSearchCallback, bucket, objectKey string) error {
+	summary, err := ondc.SummarizeCatalog(callback.Payload)
+	if err != nil {
+		return err
+	}
+	callback.CatalogMode = m.mode(ctx, callback, summary)
+	checksum := sha256.Sum256(callback.Payload)
+
+	for _, providerID := range summary.ProviderIDs {
+		version := &ports.CatalogVersion{
+			ID:            uuid.NewString(),
+			BppID:         callback.BppID,
+			ProviderID:    providerID,
+			Domain:        callback.Domain,
+			City:          callback.City,
+			Mode:          callback.CatalogMode,
+			CallbackID:    callback.ID,
+			TransactionID: callback.TransactionID,
+			MessageID:     callback.MessageID,
+			Bucket:        bucket,
+			ObjectKey:     objectKey,
+			Checksum:      hex.EncodeToString(checksum[:]),
+			ReceivedAt:    callback.ReceivedAt,
+		}
+		base, err := m.versions.LatestFull(ctx, callback.BppID, providerID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case version.Mode == ports.CatalogModeIncremental && base != nil:
+			version.BaseVersionID = base.ID
+		case version.Mode == ports.CatalogModeIncremental:
+			logger.Warnf(ctx, "Incremental catalog of provider %s of bpp_id=%s has no full catalog to apply to", providerID, callback.BppID)
+		case base != nil && base.TransactionID == callback.TransactionID && base.MessageID == callback.MessageID:
+			// Another part of the same on_search response: the provider's
+			// full catalog is the union of the parts.
+			version.BaseVersionID = base.ID
+		}
+		if err := m.versions.Create(ctx, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mode reports an on_search as incremental when its catalog carries a
+// catalog_inc tag or it answers a catalog_inc search of the same
+// transaction. The latter covers push mode, where the seller keeps sending
+// on_search under the transaction of the search that started it.
+func (m *CatalogMaterializer) mode(ctx context.Context, callback *OnSearchCallback, summary *ondc.CatalogSummary) string {
+	if _, ok := summary.Tags.Group("catalog_inc"); ok {
+		return ports.CatalogModeIncremental
+	}
+	if m.transactions == nil {
+		return ports.CatalogModeFull
+	}
+	records, err := m.transactions.ListByTransactionID(ctx, callback.TransactionID, "")
+	if err != nil {
+		logger.Warnf(ctx, "Failed to look up search of transaction_id=%s, assuming a full catalog: %v", callback.TransactionID, err)
+		return ports.CatalogModeFull
+	}
+	for _, record := range records {
+		if record.Action == "search" && record.CatalogMode == ports.CatalogModeIncremental {
+			return ports.CatalogModeIncremental
+		}
+	}
+	return ports.CatalogModeFull
+}
+
+// Versions returns the provider's most recent versions, newest first.
+func (m *CatalogMaterializer) Versions(ctx context.Context, bppID, providerID string, limit int) ([]ports.CatalogVersion, error) {
+	return m.versions.ListByProvider(ctx, bppID, providerID, limit)
+}
+
+// Materialize returns the provider's current catalog as an on_search
+// payload: every part of its latest full version, then every later delta,
+// merged in arrival order. Provider fields of a later version replace
+// earlier ones, and its items replace items with the same id or are added.
+func (m *CatalogMaterializer) Materialize(ctx context.Context, bppID, providerID string) ([]byte, error) {
+	full, err := m.versions.LatestFull(ctx, bppID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	var chain []ports.CatalogVersion
+	baseID := ""
+	if full != nil {
+		chain = append(chain, *full)
+		baseID = full.ID
+	}
+	based, err := m.versions.ListBasedOn(ctx, bppID, providerID, baseID)
+	if err != nil {
+		return nil, err
+	}
+	chain = append(chain, based...)
+	if len(chain) == 0 {
+		return nil, appError.ErrCatalogNotFound
+	}
+
+	merge := newCatalogMerge()
+	for _, version := range chain {
+		payload, err := m.storage.Download(ctx, version.ObjectKey)
+		if err != nil {
+			return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, "failed to download catalog version", fmt.Sprintf("%s: %v", version.ObjectKey, err))
+		}
+		found, err := merge.add(payload, providerID)
+		if err != nil {
+			return nil, appError.NewCustomError(500, appError.ErrHTTPInternalServer.Code, "failed to read catalog version", fmt.Sprintf("%s: %v", version.ObjectKey, err))
+		}
+		if !found {
+			logger.Warnf(ctx, "Catalog version %s no longer contains provider %s, skipping", version.ID, providerID)
+		}
+	}
+	if merge.latest == nil {
+		return nil, appError.ErrCatalogNotFound
+	}
+
+	logger.Infof(ctx, "Materialized provider %s of bpp_id=%s from %d versions with %d items", providerID, bppID, len(chain), merge.itemCount())
+	return merge.build()
+}
@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"adapter/internal/ports"
+)
+
+// memoryVersionRepository is a ports.CatalogVersionRepository that keeps
+// the unique key of catalog_versions on bpp_id, provider_id, message_id and
+// checksum.
+type memoryVersionRepository struct {
+	versions []ports.CatalogVersion
+}
+
+func (r *memoryVersionRepository) Create(ctx context.Context, version *ports.CatalogVersion) error {
+	for _, existing := range r.versions {
+		if existing.BppID == version.BppID && existing.ProviderID == version.ProviderID &&
+			existing.MessageID == version.MessageID && existing.Checksum == version.Checksum {
+			return nil
+		}
+	}
+	r.versions = append(r.versions, *version)
+	return nil
+}
+
+func (r *memoryVersionRepository) LatestFull(ctx context.Context, bppID, providerID string) (*ports.CatalogVersion, error) {
+	var latest *ports.CatalogVersion
+	for i, version := range r.versions {
+		if version.BppID == bppID && version.ProviderID == providerID && version.Mode == ports.CatalogModeFull && version.BaseVersionID == "" &&
+			(latest == nil || version.ReceivedAt.After(latest.ReceivedAt)) {
+			latest = &r.versions[i]
+		}
+	}
+	return latest, nil
+}
+
+func (r *memoryVersionRepository) ListBasedOn(ctx context.Context, bppID, providerID, baseVersionID string) ([]ports.CatalogVersion, error) {
+	var based []ports.CatalogVersion
+	for _, version := range r.versions {
+		if version.BppID == bppID && version.ProviderID == providerID && version.BaseVersionID == baseVersionID &&
+			(baseVersionID != "" || version.Mode == ports.CatalogModeIncremental) {
+			based = append(based, version)
+		}
+	}
+	sort.SliceStable(based, func(i, j int) bool { return based[i].ReceivedAt.Before(based[j].ReceivedAt) })
+	return based, nil
+}
+
+func (r *memoryVersionRepository) ListByProvider(ctx context.Context, bppID, providerID string, limit int) ([]ports.CatalogVersion, error) {
+	var versions []ports.CatalogVersion
+	for _, version := range r.versions {
+		if version.BppID == bppID && version.ProviderID == providerID {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// objectStore is a ports.ObjectStorage keeping objects in a map.
+type objectStore map[string][]byte
+
+func (s objectStore) Upload(ctx context.Context, objectName string, data []byte, contentType string) (string, error) {
+	s[objectName] = data
+	return objectName, nil
+}
+
+func (s objectStore) Download(ctx context.Context, objectName string) ([]byte, error) {
+	data, ok := s[objectName]
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", objectName)
+	}
+	return data, nil
+}
+
+func (s objectStore) GetBucket() string {
+	return "ondc-test"
+}
+
+// catalogCallback returns an on_search of provider P1 with item I1 at price.
+func catalogCallback(id, messageID, price string, incremental bool, receivedAt time.Time) *OnSearchCallback {
+	return catalogPart(id, messageID, "I1", price, incremental, receivedAt)
+}
+
+// catalogPart returns an on_search of provider P1 with one item.
+func catalogPart(id, messageID, itemID, price string, incremental bool, receivedAt time.Time) *OnSearchCallback {
+	tags := ""
+	if incremental {
+		tags = `,"tags":[{"code":"catalog_inc","list":[{"code":"mode","value":"start"}]}]`
+	}
+	payload := `{"context":{"domain":"ONDC:RET10","action":"on_search","bpp_id":"seller.example.com","transaction_id":"t-1","message_id":"` + messageID + `"},` +
+		`"message":{"catalog":{"bpp/providers":[{"id":"P1","items":[{"id":"` + itemID + `","price":{"value":"` + price + `"}}]}]` + tags + `}}}`
+	return &OnSearchCallback{
+		ID:            id,
+		BppID:         "seller.example.com",
+		Domain:        "ONDC:RET10",
+		TransactionID: "t-1",
+		MessageID:     messageID,
+		Payload:       []byte(payload),
+		ReceivedAt:    receivedAt,
+	}
+}
+
+func TestCatalogMaterializerRedeliveredDelta(t *testing.T) {
+	ctx := context.Background()
+	versions := &memoryVersionRepository{}
+	storage := objectStore{}
+	materializer := NewCatalogMaterializer(versions, storage, nil)
+
+	start := time.Date(2025, 1, 8, 8, 0, 0, 0, time.UTC)
+	callbacks := []*OnSearchCallback{
+		catalogCallback("cb-1", "m-full", "10", false, start),
+		catalogCallback("cb-2", "m-inc", "12", true, start.Add(time.Minute)),
+		// A second part of the same push carries different content.
+		catalogCallback("cb-3", "m-inc", "15", true, start.Add(2*time.Minute)),
+		// The first delta redelivered after a restart, under a new callback id.
+		catalogCallback("cb-4", "m-inc", "12", true, start.Add(3*time.Minute)),
+	}
+	for _, callback := range callbacks {
+		objectKey := callback.ID + ".json"
+		storage[objectKey] = callback.Payload
+		if err := materializer.Track(ctx, callback, storage.GetBucket(), objectKey); err != nil {
+			t.Fatalf("Track(%s): %v", callback.ID, err)
+		}
+	}
+	if err := materializer.Track(ctx, callbacks[2], storage.GetBucket(), "cb-3.json"); err != nil {
+		t.Fatalf("Track(cb-3) again: %v", err)
+	}
+
+	var recorded []string
+	for _, version := range versions.versions {
+		recorded = append(recorded, version.CallbackID)
+	}
+	if got := strings.Join(recorded, ","); got != "cb-1,cb-2,cb-3" {
+		t.Fatalf("recorded versions of %s, want cb-1,cb-2,cb-3", got)
+	}
+	if callbacks[3].CatalogMode != ports.CatalogModeIncremental {
+		t.Fatalf("CatalogMode = %q, want %q", callbacks[3].CatalogMode, ports.CatalogModeIncremental)
+	}
+
+	catalog, err := materializer.Materialize(ctx, "seller.example.com", "P1")
+	if err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+	if !strings.Contains(string(catalog), `"value":"15"`) {
+		t.Fatalf("materialized catalog does not end with the latest delta: %s", catalog)
+	}
+}
+
+func TestCatalogMaterializerMultiPartFullCatalog(t *testing.T) {
+	ctx := context.Background()
+	versions := &memoryVersionRepository{}
+	storage := objectStore{}
+	materializer := NewCatalogMaterializer(versions, storage, nil)
+
+	start := time.Date(2025, 1, 8, 8, 0, 0, 0, time.UTC)
+	callbacks := []*OnSearchCallback{
+		// An older full catalog that the new response replaces.
+		catalogPart("cb-0", "m-old", "I0", "5", false, start.Add(-time.Hour)),
+		// One response split across three parts.
+		catalogPart("cb-1", "m-full", "I1", "10", false, start),
+		catalogPart("cb-2", "m-full", "I2", "20", false, start.Add(time.Second)),
+		// A delta arriving between the parts.
+		catalogPart("cb-3", "m-inc", "I1", "12", true, start.Add(2*time.Second)),
+		catalogPart("cb-4", "m-full", "I3", "30", false, start.Add(3*time.Second)),
+	}
+	for _, callback := range callbacks {
+		objectKey := callback.ID + ".json"
+		storage[objectKey] = callback.Payload
+		if err := materializer.Track(ctx, callback, storage.GetBucket(), objectKey); err != nil {
+			t.Fatalf("Track(%s): %v", callback.ID, err)
+		}
+	}
+
+	bases := map[string]string{}
+	ids := map[string]string{}
+	for _, version := range versions.versions {
+		ids[version.CallbackID] = version.ID
+		bases[version.CallbackID] = version.BaseVersionID
+	}
+	for _, callbackID := range []string{"cb-2", "cb-3", "cb-4"} {
+		if bases[callbackID] != ids["cb-1"] {
+			t.Errorf("%s is based on %q, want the first part %q", callbackID, bases[callbackID], ids["cb-1"])
+		}
+	}
+	if bases["cb-1"] != "" {
+		t.Errorf("first part is based on %q, want no base", bases["cb-1"])
+	}
+
+	catalog, err := materializer.Materialize(ctx, "seller.example.com", "P1")
+	if err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+	for _, want := range []string{`"id":"I1","price":{"value":"12"}`, `"id":"I2"`, `"id":"I3"`} {
+		if !strings.Contains(string(catalog), want) {
+			t.Errorf("materialized catalog is missing %s: %s", want, catalog)
+		}
+	}
+	if strings.Contains(string(catalog), `"id":"I0"`) {
+		t.Errorf("materialized catalog still has the replaced full catalog: %s", catalog)
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valyala/fastjson"
+
+	"adapter/pkg/ondc"
+)
+
+// catalogMerge folds on_search catalogs into one in the order they are
+// added. Provider fields and items of a later catalog replace those with
+// the same id; new providers and items are appended.
+type catalogMerge struct {
+	providerIDs  []string
+	providers    map[string]*mergedProvider
+	descriptor   json.RawMessage
+	fulfillments json.RawMessage
+	// latest is the last payload that contributed a provider; its context
+	// is used for the merged on_search.
+	latest []byte
+}
+
+type mergedProvider struct {
+	fields  map[string]json.RawMessage
+	itemIDs []string
+	items   map[string]json.RawMessage
+}
+
+func newCatalogMerge() *catalogMerge {
+	return &catalogMerge{providers: make(map[string]*mergedProvider)}
+}
+
+// add merges the providers of an on_search payload, or only providerID
+// when it is set. It reports whether any provider was merged.
+func (m *catalogMerge) add(payload []byte, providerID string) (bool, error) {
+	found := false
+	err := ondc.EachRawProvider(payload, func(header *ondc.CatalogHeader, raw *ondc.RawProvider) error {
+		if raw.Provider.ID == "" || (providerID != "" && raw.Provider.ID != providerID) {
+			return nil
+		}
+		found = true
+
+		provider, ok := m.providers[raw.Provider.ID]
+		if !ok {
+			provider = &mergedProvider{fields: make(map[string]json.RawMessage), items: make(map[string]json.RawMessage)}
+			m.providers[raw.Provider.ID] = provider
+			m.providerIDs = append(m.providerIDs, raw.Provider.ID)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw.JSON, &fields); err != nil {
+			return fmt.Errorf("failed to decode provider %s: %w", raw.Provider.ID, err)
+		}
+		for key, value := range fields {
+			provider.fields[key] = value
+		}
+		for i, item := range raw.Provider.Items {
+			if item.ID == "" || i >= len(raw.Items) {
+				continue
+			}
+			if _, seen := provider.items[item.ID]; !seen {
+				provider.itemIDs = append(provider.itemIDs, item.ID)
+			}
+			provider.items[item.ID] = raw.Items[i]
+		}
+
+		if len(header.Descriptor) > 0 {
+			m.descriptor = header.Descriptor
+		}
+		if len(header.Fulfillments) > 0 {
+			m.fulfillments = header.Fulfillments
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if found {
+		m.latest = payload
+	}
+	return found, nil
+}
+
+// itemCount returns the number of distinct items merged so far.
+func (m *catalogMerge) itemCount() int {
+	count := 0
+	for _, provider := range m.providers {
+		count += len(provider.itemIDs)
+	}
+	return count
+}
+
+// build returns the merged catalog as an on_search carrying the context of
+// the latest contributing payload.
+func (m *catalogMerge) build() ([]byte, error) {
+	if m.latest == nil {
+		return nil, fmt.Errorf("no provider merged")
+	}
+	latestContext, err := ondc.ParseContext(m.latest)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]onSearchProvider, 0, len(m.providerIDs))
+	for _, id := range m.providerIDs {
+		provider := m.providers[id]
+		providerJSON, err := json.Marshal(provider.fields)
+		if err != nil {
+			return nil, err
+		}
+		entry := onSearchProvider{ID: id, JSON: string(providerJSON)}
+		for _, itemID := range provider.itemIDs {
+			entry.Items = append(entry.Items, string(provider.items[itemID]))
+		}
+		providers = append(providers, entry)
+	}
+	return buildOnSearch(m.latest, latestContext.BppID, latestContext.BppURI, string(m.descriptor), string(m.fulfillments), providers)
+}
+
+// onSearchProvider is a provider's JSON without items and its raw items.
+type onSearchProvider struct {
+	ID    string
+	JSON  string
+	Items []string
+}
+
+// buildOnSearch assembles an on_search from the context of payload (a
+// search, or a stored on_search) and catalog parts kept as raw JSON. The
+// catalog uses the v2.x unprefixed keys when the context is v2.x.
+func buildOnSearch(payload []byte, bppID, bppURI, descriptor, fulfillments string, providers []onSearchProvider) ([]byte, error) {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	payloadContext := v.Get("context")
+	if payloadContext == nil || payloadContext.Type() != fastjson.TypeObject {
+		return nil, ondc.ErrNoContext
+	}
+
+	var a fastjson.Arena
+	payloadContext.Set("action", a.NewString("on_search"))
+	payloadContext.Set("bpp_id", a.NewString(bppID))
+	payloadContext.Set("bpp_uri", a.NewString(bppURI))
+	payloadContext.Set("timestamp", a.NewString(time.Now().UTC().Format("2006-01-02T15:04:05.000Z")))
+
+	prefix := "bpp/"
+	if strings.HasPrefix(string(payloadContext.GetStringBytes("version")), "2") {
+		prefix = ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"context":`)
+	buf.Write(payloadContext.MarshalTo(nil))
+	buf.WriteString(`,"message":{"catalog":{`)
+	if descriptor == "" {
+		descriptor = "{}"
+	}
+	fmt.Fprintf(&buf, `"%sdescriptor":%s`, prefix, descriptor)
+	if fulfillments != "" {
+		fmt.Fprintf(&buf, `,"%sfulfillments":%s`, prefix, fulfillments)
+	}
+	fmt.Fprintf(&buf, `,"%sproviders":[`, prefix)
+
+	for i, provider := range providers {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		// The provider JSON has no items; append them as the last field
+		providerJSON := bytes.TrimSpace([]byte(provider.JSON))
+		if len(providerJSON) < 2 || providerJSON[len(providerJSON)-1] != '}' {
+			return nil, fmt.Errorf("provider %s is not a JSON object", provider.ID)
+		}
+		buf.Write(providerJSON[:len(providerJSON)-1])
+		if len(bytes.TrimSpace(providerJSON[1:len(providerJSON)-1])) > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"items":[`)
+		for j, item := range provider.Items {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(item)
+		}
+		buf.WriteString(`]}`)
+	}
+	buf.WriteString(`]}}}`)
+	return buf.Bytes(), nil
+}
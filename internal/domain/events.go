@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strconv"
+
+	"adapter/internal/ports"
+	"adapter/pkg/events"
+)
+
+const (
+	// TransactionStateChangedEventType is published when a transaction
+	// moves stage or order state, or receives an out-of-order action.
+	TransactionStateChangedEventType  = "ondc.transaction.state_changed.v1"
+	TransactionStateChangedSchemaPath = "/schemas/events/ondc.transaction.state_changed.v1.json"
+)
+
+// TransactionStateChange is the data of a state-change event.
+type TransactionStateChange struct {
+	TransactionID      string `json:"transaction_id"`
+	MessageID          string `json:"message_id"`
+	Action             string `json:"action"`
+	Domain             string `json:"domain"`
+	BapID              string `json:"bap_id,omitempty"`
+	BppID              string `json:"bpp_id,omitempty"`
+	PreviousStage      string `json:"previous_stage,omitempty"`
+	Stage              string `json:"stage"`
+	PreviousOrderState string `json:"previous_order_state,omitempty"`
+	OrderState         string `json:"order_state,omitempty"`
+	OutOfOrder         bool   `json:"out_of_order"`
+	Violation          string `json:"violation,omitempty"`
+	Version            int    `json:"version"`
+}
+
+// pointerHeaders exposes the ONDC routing fields as Kafka headers.
+func pointerHeaders(pointer events.OnSearchPointer) []ports.Header {
+	return []ports.Header{
+		{Key: "ondc_domain", Value: []byte(pointer.Domain)},
+		{Key: "ondc_action", Value: []byte(pointer.Action)},
+		{Key: "ondc_transaction_id", Value: []byte(pointer.TransactionID)},
+		{Key: "ondc_message_id", Value: []byte(pointer.MessageID)},
+		{Key: "ondc_bap_id", Value: []byte(pointer.BapID)},
+		{Key: "ondc_bpp_id", Value: []byte(pointer.BppID)},
+		{Key: "ondc_city", Value: []byte(pointer.City)},
+		{Key: "ondc_late", Value: []byte(strconv.FormatBool(pointer.Late))},
+		{Key: "ondc_mode", Value: []byte(pointer.Mode)},
+		{Key: "ondc_complete", Value: []byte(strconv.FormatBool(pointer.Complete))},
+	}
+}
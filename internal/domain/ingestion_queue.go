@@ -0,0 +1,490 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+)
+
+const (
+	spillFileSuffix = ".json"
+	deadLetterDir   = "dead"
+)
+
+// IngestionQueueConfig tunes the asynchronous ingestion pipeline.
+type IngestionQueueConfig struct {
+	// QueueSize is the number of payloads buffered in memory.
+	QueueSize int
+	// Workers is the number of goroutines uploading and publishing.
+	Workers int
+	// SpillDir holds payloads that did not fit in memory.
+	SpillDir string
+	// SpillMaxBytes caps the on-disk backlog; beyond it callbacks are NACKed.
+	SpillMaxBytes int64
+	// MaxAttempts bounds retries of transient storage/publish failures.
+	MaxAttempts int
+	// RetryBaseDelay is the first backoff delay; it doubles on each attempt.
+	RetryBaseDelay time.Duration
+	// JobTimeout bounds a single processing attempt.
+	JobTimeout time.Duration
+}
+
+// IngestionQueueStats is a point-in-time view of the queue.
+type IngestionQueueStats struct {
+	Depth         int   `json:"depth"`
+	Capacity      int   `json:"capacity"`
+	Spilled       int   `json:"spilled"`
+	SpilledBytes  int64 `json:"spilled_bytes"`
+	SpillMaxBytes int64 `json:"spill_max_bytes"`
+	Workers       int   `json:"workers"`
+	InFlight      int64 `json:"in_flight"`
+	Processed     int64 `json:"processed"`
+	Failed        int64 `json:"failed"`
+}
+
+type ingestionJob struct {
+	callback *OnSearchCallback
+	// spillFile is set when the payload was loaded from disk and must be
+	// removed once it has been handled.
+	spillFile string
+}
+
+type spilledFile struct {
+	path string
+	size int64
+}
+
+// IngestionQueue decouples on_search acceptance from storage and publishing.
+// Payloads are buffered in a bounded channel, spill to disk when it is full
+// and are processed by a fixed pool of workers with retries.
+type IngestionQueue struct {
+	service *OnSearchService
+	cfg     IngestionQueueConfig
+
+	jobs chan *ingestionJob
+
+	mu           sync.Mutex
+	spilled      []spilledFile
+	spilledBytes int64
+	// spilling counts spill files being written outside the lock.
+	spilling int
+	closed   bool
+
+	wake         chan struct{}
+	stopDrainer  chan struct{}
+	drainerDone  chan struct{}
+	abort        chan struct{}
+	cancelJobs   context.CancelFunc
+	jobsCtx      context.Context
+	workersGroup sync.WaitGroup
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewIngestionQueue prepares the spill directory and picks up any payloads
+// left there by a previous run.
+func NewIngestionQueue(service *OnSearchService, cfg IngestionQueueConfig) (*IngestionQueue, error) {
+	if cfg.QueueSize <= 0 || cfg.Workers <= 0 || cfg.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("ingestion queue size, workers and max attempts must be positive")
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.SpillDir, deadLetterDir), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	jobsCtx, cancel := context.WithCancel(context.Background())
+	q := &IngestionQueue{
+		service:     service,
+		cfg:         cfg,
+		jobs:        make(chan *ingestionJob, cfg.QueueSize),
+		wake:        make(chan struct{}, 1),
+		stopDrainer: make(chan struct{}),
+		drainerDone: make(chan struct{}),
+		abort:       make(chan struct{}),
+		cancelJobs:  cancel,
+		jobsCtx:     jobsCtx,
+	}
+	if err := q.loadSpilled(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Start launches the workers and the spill drainer.
+func (q *IngestionQueue) Start() {
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.workersGroup.Add(1)
+		go q.worker()
+	}
+	go q.drainSpilled()
+	q.signal()
+}
+
+// Enqueue accepts a callback for asynchronous processing. It returns
+// ErrIngestionQueueFull when both the memory queue and the spill budget are
+// exhausted.
+func (q *IngestionQueue) Enqueue(ctx context.Context, callback *OnSearchCallback) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return appError.ErrIngestionQueueClosed
+	}
+
+	// Keep FIFO order: once anything has spilled, new work queues behind it.
+	if len(q.spilled) == 0 && q.spilling == 0 {
+		select {
+		case q.jobs <- &ingestionJob{callback: callback}:
+			q.mu.Unlock()
+			return nil
+		default:
+		}
+	}
+
+	size := int64(len(callback.Payload))
+	if q.spilledBytes+size > q.cfg.SpillMaxBytes {
+		spilledBytes := q.spilledBytes
+		q.mu.Unlock()
+		logger.Warnf(ctx, "Ingestion spill limit reached (%d/%d bytes), rejecting transaction_id=%s",
+			spilledBytes, q.cfg.SpillMaxBytes, callback.TransactionID)
+		return appError.ErrIngestionQueueFull
+	}
+	// Reserve the bytes so concurrent spills cannot overshoot the budget,
+	// then write the file without holding the lock.
+	q.spilledBytes += size
+	q.spilling++
+	q.mu.Unlock()
+
+	path, err := q.writeSpillFile(q.cfg.SpillDir, callback.ReceivedAt, callback.Payload)
+
+	q.mu.Lock()
+	q.spilling--
+	if err != nil {
+		q.spilledBytes -= size
+		q.mu.Unlock()
+		logger.Errorf(ctx, err, "Failed to spill on_search payload to disk")
+		return appError.ErrIngestionQueueFull
+	}
+	q.insertSpilled(spilledFile{path: path, size: size})
+	q.mu.Unlock()
+
+	logger.Infof(ctx, "Ingestion queue full, spilled transaction_id=%s to %s", callback.TransactionID, path)
+	q.signal()
+	return nil
+}
+
+// Stats reports the current queue depth and counters.
+func (q *IngestionQueue) Stats() IngestionQueueStats {
+	q.mu.Lock()
+	spilled := len(q.spilled)
+	spilledBytes := q.spilledBytes
+	q.mu.Unlock()
+
+	return IngestionQueueStats{
+		Depth:         len(q.jobs),
+		Capacity:      cap(q.jobs),
+		Spilled:       spilled,
+		SpilledBytes:  spilledBytes,
+		SpillMaxBytes: q.cfg.SpillMaxBytes,
+		Workers:       q.cfg.Workers,
+		InFlight:      q.inFlight.Load(),
+		Processed:     q.processed.Load(),
+		Failed:        q.failed.Load(),
+	}
+}
+
+// Close stops accepting work and lets the workers drain the in-memory
+// queue until ctx expires. Anything still queued at that point is spilled
+// to disk and picked up on the next start.
+func (q *IngestionQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	close(q.stopDrainer)
+	<-q.drainerDone
+	close(q.jobs)
+
+	logger.Infof(ctx, "Draining ingestion queue: %d queued, %d in flight", len(q.jobs), q.inFlight.Load())
+
+	done := make(chan struct{})
+	go func() {
+		q.workersGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		close(q.abort)
+		q.cancelJobs()
+		<-done
+		return fmt.Errorf("ingestion queue did not drain before shutdown deadline, remaining payloads spilled to disk: %w", ctx.Err())
+	}
+}
+
+func (q *IngestionQueue) worker() {
+	defer q.workersGroup.Done()
+	for job := range q.jobs {
+		select {
+		case <-q.abort:
+			q.persistForRestart(job)
+			continue
+		default:
+		}
+		q.inFlight.Add(1)
+		q.handle(job)
+		q.inFlight.Add(-1)
+	}
+}
+
+func (q *IngestionQueue) handle(job *ingestionJob) {
+	callback := job.callback
+	ctx := context.WithValue(q.jobsCtx, "request_id", "ingest-"+callback.MessageID)
+
+	var err error
+	for attempt := 1; attempt <= q.cfg.MaxAttempts; attempt++ {
+		err = q.attempt(ctx, callback)
+		if err == nil {
+			q.processed.Add(1)
+			q.removeSpillFile(ctx, job)
+			return
+		}
+		if isPermanent(err) {
+			break
+		}
+		if attempt == q.cfg.MaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(q.cfg.RetryBaseDelay, attempt)
+		logger.Warnf(ctx, "Ingestion attempt %d/%d failed for transaction_id=%s, retrying in %s: %v",
+			attempt, q.cfg.MaxAttempts, callback.TransactionID, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-q.abort:
+			q.persistForRestart(job)
+			return
+		}
+	}
+
+	q.failed.Add(1)
+	logger.Errorf(ctx, err, "Giving up on transaction_id=%s, moving payload to dead letter", callback.TransactionID)
+	if path, dlErr := q.writeSpillFile(filepath.Join(q.cfg.SpillDir, deadLetterDir), callback.ReceivedAt, callback.Payload); dlErr != nil {
+		logger.Errorf(ctx, dlErr, "Failed to write dead letter payload")
+	} else {
+		logger.Infof(ctx, "Dead letter payload written to %s", path)
+	}
+	q.removeSpillFile(ctx, job)
+}
+
+func (q *IngestionQueue) attempt(ctx context.Context, callback *OnSearchCallback) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = appError.NewCustomError(500, appError.ErrHTTPInternalServer.Code, "internal server error", fmt.Sprintf("%v", r))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, q.cfg.JobTimeout)
+	defer cancel()
+	return q.service.Process(ctx, callback)
+}
+
+// drainSpilled moves spilled payloads back into the memory queue, oldest
+// first, whenever there is room.
+func (q *IngestionQueue) drainSpilled() {
+	defer close(q.drainerDone)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stopDrainer:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+
+		for {
+			q.mu.Lock()
+			if len(q.spilled) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			next := q.spilled[0]
+			q.mu.Unlock()
+
+			job, err := q.loadSpillFile(next.path)
+			if err != nil {
+				logger.Errorf(context.Background(), err, "Dropping unreadable spill file %s", next.path)
+				q.popSpilled(next.path)
+				continue
+			}
+
+			select {
+			case q.jobs <- job:
+				q.popSpilled(next.path)
+			case <-q.stopDrainer:
+				return
+			}
+		}
+	}
+}
+
+// popSpilled forgets a spill file handed to the workers. It is looked up by
+// path, as an older payload may have been inserted ahead of it meanwhile.
+func (q *IngestionQueue) popSpilled(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, file := range q.spilled {
+		if file.path == path {
+			q.spilledBytes -= file.size
+			q.spilled = append(q.spilled[:i], q.spilled[i+1:]...)
+			return
+		}
+	}
+}
+
+// insertSpilled adds a spill file in name order, which is arrival order.
+// Callers must hold q.mu.
+func (q *IngestionQueue) insertSpilled(file spilledFile) {
+	i := sort.Search(len(q.spilled), func(i int) bool { return q.spilled[i].path > file.path })
+	q.spilled = append(q.spilled, spilledFile{})
+	copy(q.spilled[i+1:], q.spilled[i:])
+	q.spilled[i] = file
+}
+
+func (q *IngestionQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// persistForRestart writes an unprocessed in-memory job to the spill
+// directory. Jobs that came from disk are already there.
+func (q *IngestionQueue) persistForRestart(job *ingestionJob) {
+	if job.spillFile != "" {
+		return
+	}
+	if _, err := q.writeSpillFile(q.cfg.SpillDir, job.callback.ReceivedAt, job.callback.Payload); err != nil {
+		logger.Errorf(context.Background(), err, "Failed to spill transaction_id=%s during shutdown, payload lost", job.callback.TransactionID)
+	}
+}
+
+func (q *IngestionQueue) loadSpilled() error {
+	entries, err := os.ReadDir(q.cfg.SpillDir)
+	if err != nil {
+		return fmt.Errorf("failed to read spill directory: %w", err)
+	}
+
+	var files []spilledFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spillFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat spill file: %w", err)
+		}
+		files = append(files, spilledFile{path: filepath.Join(q.cfg.SpillDir, entry.Name()), size: info.Size()})
+		q.spilledBytes += info.Size()
+	}
+	// File names start with a zero-padded timestamp so lexical order is FIFO.
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	q.spilled = files
+
+	if len(files) > 0 {
+		logger.Infof(context.Background(), "Recovered %d spilled on_search payloads (%d bytes)", len(files), q.spilledBytes)
+	}
+	return nil
+}
+
+func (q *IngestionQueue) loadSpillFile(path string) (*ingestionJob, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	callback, err := ParseOnSearchCallback(context.Background(), payload)
+	if err != nil {
+		return nil, err
+	}
+	// Timing checks are judged against when the callback arrived, which the
+	// file name records.
+	prefix, _, _ := strings.Cut(filepath.Base(path), "_")
+	if nanos, err := strconv.ParseInt(prefix, 10, 64); err == nil {
+		callback.ReceivedAt = time.Unix(0, nanos).UTC()
+	}
+	return &ingestionJob{callback: callback, spillFile: path}, nil
+}
+
+// writeSpillFile names the file after receivedAt, so that loadSpillFile
+// restores when the callback arrived rather than when it was spilled.
+func (q *IngestionQueue) writeSpillFile(dir string, receivedAt time.Time, payload []byte) (string, error) {
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	name := fmt.Sprintf("%020d_%s%s", receivedAt.UnixNano(), uuid.NewString(), spillFileSuffix)
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (q *IngestionQueue) removeSpillFile(ctx context.Context, job *ingestionJob) {
+	if job.spillFile == "" {
+		return
+	}
+	if err := os.Remove(job.spillFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Errorf(ctx, err, "Failed to remove spill file %s", job.spillFile)
+	}
+}
+
+// isPermanent reports whether retrying err cannot succeed, e.g. a payload
+// that fails schema validation.
+func isPermanent(err error) bool {
+	var customErr *appError.CustomError
+	return errors.As(err, &customErr) && customErr.HTTPCode < 500
+}
+
+// backoffDelay returns base*2^(attempt-1) with up to 50% jitter.
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// HealthCheck fails while the queue is rejecting callbacks for lack of
+// spill space.
+func (q *IngestionQueue) HealthCheck(ctx context.Context) error {
+	stats := q.Stats()
+	if stats.SpilledBytes >= stats.SpillMaxBytes {
+		return fmt.Errorf("ingestion spill limit reached (%d/%d bytes)", stats.SpilledBytes, stats.SpillMaxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const testOnSearchPayload = `{"context":{"domain":"ONDC:RET10","action":"on_search","transaction_id":"t-1","message_id":"m-1"},"message":{}}`
+
+func newTestIngestionQueue(t *testing.T, queueSize int, spillMaxBytes int64) *IngestionQueue {
+	t.Helper()
+	q, err := NewIngestionQueue(nil, IngestionQueueConfig{
+		QueueSize:     queueSize,
+		Workers:       1,
+		SpillDir:      t.TempDir(),
+		SpillMaxBytes: spillMaxBytes,
+		MaxAttempts:   1,
+	})
+	if err != nil {
+		t.Fatalf("NewIngestionQueue: %v", err)
+	}
+	return q
+}
+
+func TestIngestionQueueSpillKeepsReceivedAt(t *testing.T) {
+	q := newTestIngestionQueue(t, 1, 1<<20)
+	ctx := context.Background()
+
+	first, err := ParseOnSearchCallback(ctx, []byte(testOnSearchPayload))
+	if err != nil {
+		t.Fatalf("ParseOnSearchCallback: %v", err)
+	}
+	if err := q.Enqueue(ctx, first); err != nil {
+		t.Fatalf("Enqueue first: %v", err)
+	}
+
+	receivedAt := time.Date(2024, 3, 1, 10, 0, 0, 123, time.UTC)
+	second, err := ParseOnSearchCallback(ctx, []byte(testOnSearchPayload))
+	if err != nil {
+		t.Fatalf("ParseOnSearchCallback: %v", err)
+	}
+	second.ReceivedAt = receivedAt
+	if err := q.Enqueue(ctx, second); err != nil {
+		t.Fatalf("Enqueue second: %v", err)
+	}
+
+	stats := q.Stats()
+	if stats.Spilled != 1 || stats.SpilledBytes != int64(len(testOnSearchPayload)) {
+		t.Fatalf("stats = %+v, want one spilled payload", stats)
+	}
+
+	job, err := q.loadSpillFile(q.spilled[0].path)
+	if err != nil {
+		t.Fatalf("loadSpillFile: %v", err)
+	}
+	if !job.callback.ReceivedAt.Equal(receivedAt) {
+		t.Fatalf("ReceivedAt = %s, want %s", job.callback.ReceivedAt, receivedAt)
+	}
+}
+
+func TestIngestionQueueSpillOrderAndBudget(t *testing.T) {
+	q := newTestIngestionQueue(t, 1, int64(2*len(testOnSearchPayload)))
+	ctx := context.Background()
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	enqueue := func(receivedAt time.Time) error {
+		callback, err := ParseOnSearchCallback(ctx, []byte(testOnSearchPayload))
+		if err != nil {
+			t.Fatalf("ParseOnSearchCallback: %v", err)
+		}
+		callback.ReceivedAt = receivedAt
+		return q.Enqueue(ctx, callback)
+	}
+
+	if err := enqueue(base); err != nil {
+		t.Fatalf("Enqueue into memory: %v", err)
+	}
+	// Spilled out of arrival order; the backlog must still be oldest first.
+	if err := enqueue(base.Add(2 * time.Second)); err != nil {
+		t.Fatalf("Enqueue spill: %v", err)
+	}
+	if err := enqueue(base.Add(time.Second)); err != nil {
+		t.Fatalf("Enqueue spill: %v", err)
+	}
+	if err := enqueue(base.Add(3 * time.Second)); err == nil {
+		t.Fatal("Enqueue beyond the spill budget succeeded")
+	}
+
+	want := []time.Time{base.Add(time.Second), base.Add(2 * time.Second)}
+	if len(q.spilled) != len(want) {
+		t.Fatalf("spilled %d payloads, want %d", len(q.spilled), len(want))
+	}
+	for i, file := range q.spilled {
+		job, err := q.loadSpillFile(file.path)
+		if err != nil {
+			t.Fatalf("loadSpillFile: %v", err)
+		}
+		if !job.callback.ReceivedAt.Equal(want[i]) {
+			t.Fatalf("spilled[%d].ReceivedAt = %s, want %s", i, job.callback.ReceivedAt, want[i])
+		}
+	}
+
+	q.popSpilled(q.spilled[1].path)
+	if stats := q.Stats(); stats.Spilled != 1 || stats.SpilledBytes != int64(len(testOnSearchPayload)) {
+		t.Fatalf("stats after pop = %+v", stats)
+	}
+}
@@ -2,64 +2,110 @@ package domain
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/valyala/fastjson"
 
-	"adapter/internal/adapters/storage"
-	"adapter/internal/config"
 	"adapter/internal/ports"
 	appError "adapter/internal/shared/error"
 	logger "adapter/internal/shared/log"
+	"adapter/pkg/events"
+	"adapter/pkg/ondc"
 )
 
 // OnSearchService encapsulates the core application logic for handling
-// ONDC on_search callbacks in a hexagonal style.
+// ONDC on_search callbacks in a hexagonal style. Order lifecycle callbacks
+// (on_select, on_init, ...) share the pipeline and additionally drive the
+// transaction state machine; only on_search publishes a pointer event.
 type OnSearchService struct {
 	validator     ports.SchemaValidator
 	storage       ports.ObjectStorage
 	publisher     ports.EventPublisher
+	transactions  ports.TransactionRepository
+	states        *TransactionStateMachine
+	timing        *TimingPolicy
+	dispatcher    *CallbackDispatcher
+	catalog       *CatalogIndex
+	catalogs      *CatalogMaterializer
+	aggregator    *CatalogAggregator
+	serviceable   *ServiceabilityIndex
+	schedules     *StoreSchedules
 	onSearchTopic string
+	eventSource   string
 }
 
-type onSearchPointer struct {
-	Storage       string `json:"storage"`
-	Bucket        string `json:"bucket"`
-	ObjectKey     string `json:"object_key"`
-	Domain        string `json:"domain"`
-	Action        string `json:"action"`
-	TransactionID string `json:"transaction_id"`
+// OnSearchCallback is an on_search payload together with the context fields
+// needed to route it.
+type OnSearchCallback struct {
+	// ID identifies this callback in the transactions table.
+	ID            string
+	ReceivedAt    time.Time
+	Domain        string
+	Action        string
+	TransactionID string
+	MessageID     string
+	BapID         string
+	BapURI        string
+	BppID         string
+	BppURI        string
+	City          string
+	CoreVersion   string
+	Timestamp     string
+	TTL           string
+	Payload       []byte
+	// Violation is set when the action broke the transaction's state
+	// machine.
+	Violation string
+	// Late is set when the callback arrived after the TTL of its request
+	// expired; TimingViolations lists every timing problem found.
+	Late             bool
+	TimingViolations []string
+	timingChecked    bool
+	// CatalogMode is set on on_search callbacks once their catalog has been
+	// classified, and on searches.
+	CatalogMode string
 }
 
 // NewOnSearchService constructs a new OnSearchService.
 func NewOnSearchService(
 	validator ports.SchemaValidator,
+	storage ports.ObjectStorage,
 	publisher ports.EventPublisher,
+	transactions ports.TransactionRepository,
+	states *TransactionStateMachine,
+	timing *TimingPolicy,
+	dispatcher *CallbackDispatcher,
+	catalog *CatalogIndex,
+	catalogs *CatalogMaterializer,
+	aggregator *CatalogAggregator,
+	serviceable *ServiceabilityIndex,
+	schedules *StoreSchedules,
 	onSearchTopic string,
-	cfg *config.Config,
+	eventSource string,
 ) (*OnSearchService, error) {
-	minioStorage, err := storage.NewMinIOStorage(storage.MinIOConfig{
-		Endpoint:  cfg.MinIOEndpoint,
-		AccessKey: cfg.MinIOAccessKey,
-		SecretKey: cfg.MinIOSecretKey,
-		UseSSL:    cfg.MinIOUseSSL,
-		Bucket:    cfg.MinIOBucket,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize MinIO storage: %w", err)
-	}
-	if minioStorage == nil {
-		return nil, fmt.Errorf("minio storage is nil after initialization")
+	if storage == nil {
+		return nil, fmt.Errorf("object storage is nil")
 	}
 	return &OnSearchService{
 		validator:     validator,
-		storage:       minioStorage,
+		storage:       storage,
 		publisher:     publisher,
+		transactions:  transactions,
+		states:        states,
+		timing:        timing,
+		dispatcher:    dispatcher,
+		catalog:       catalog,
+		catalogs:      catalogs,
+		aggregator:    aggregator,
+		serviceable:   serviceable,
+		schedules:     schedules,
 		onSearchTopic: onSearchTopic,
+		eventSource:   eventSource,
 	}, nil
 }
 
@@ -73,18 +119,77 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		}
 	}()
 
+	callback, err := ParseOnSearchCallback(ctx, payload)
+	if err != nil {
+		return err
+	}
+	return s.Process(ctx, callback)
+}
+
+// Process validates a parsed callback against its schema, uploads it to
+// object storage and publishes the pointer event.
+func (s *OnSearchService) Process(ctx context.Context, callback *OnSearchCallback) error {
+	s.record(ctx, callback, ports.TransactionStatusReceived, "", nil)
+	if err := s.Validate(ctx, callback); err != nil {
+		s.record(ctx, callback, ports.TransactionStatusRejected, "", err)
+		return err
+	}
+	if !callback.timingChecked {
+		if err := s.CheckTiming(ctx, callback); err != nil {
+			return err
+		}
+	}
+	s.applyState(ctx, callback)
+	return s.Persist(ctx, callback)
+}
+
+// CheckTiming enforces the domain's timing policy on callback. A rejected
+// callback is recorded before the error is returned, so the async path can
+// call it ahead of enqueueing.
+func (s *OnSearchService) CheckTiming(ctx context.Context, callback *OnSearchCallback) error {
+	if s.timing == nil {
+		return nil
+	}
+	if err := s.timing.Check(ctx, callback); err != nil {
+		s.record(ctx, callback, ports.TransactionStatusRejected, "", err)
+		return err
+	}
+	return nil
+}
+
+// applyState runs the callback through the transaction state machine.
+func (s *OnSearchService) applyState(ctx context.Context, callback *OnSearchCallback) {
+	if s.states == nil {
+		return
+	}
+	transition, err := s.states.Apply(ctx, callback)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to apply %s to state of transaction_id=%s: %v", callback.Action, callback.TransactionID, err)
+		return
+	}
+	if transition != nil {
+		callback.Violation = transition.Violation
+	}
+}
+
+// ParseOnSearchCallback extracts the routing context from a raw payload
+// without unmarshalling the (potentially very large) catalog.
+func ParseOnSearchCallback(ctx context.Context, payload []byte) (*OnSearchCallback, error) {
 	if len(payload) == 0 {
-		return appError.ErrInvalidRequestBody
+		return nil, appError.ErrInvalidRequestBody
 	}
 
-	logger.Info(ctx, "Step 1: Extracting context from payload using fastjson")
+	logger.Info(ctx, "Step 1: Extracting context from payload")
 
-	// 1. Extract minimal context for routing using fastjson
-	var p fastjson.Parser
-	v, err := p.ParseBytes(payload)
+	// 1. Extract minimal context for routing without decoding the catalog
+	onSearchCtx, err := ondc.ParseContext(payload)
+	if errors.Is(err, ondc.ErrNoContext) {
+		logger.Warn(ctx, "Missing 'context' object in payload")
+		return nil, appError.ErrMissingRequiredField
+	}
 	if err != nil {
 		logger.Errorf(ctx, err, "Failed to parse JSON payload")
-		return appError.NewCustomError(
+		return nil, appError.NewCustomError(
 			400,
 			appError.ErrInvalidRequestBody.Code,
 			"failed to parse ONDC payload",
@@ -92,37 +197,48 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		)
 	}
 
-	ctxObj := v.GetObject("context")
-	if ctxObj == nil {
-		logger.Warn(ctx, "Missing 'context' object in payload")
-		return appError.ErrMissingRequiredField
-	}
-
-	domainVal := ctxObj.Get("domain")
-	actionVal := ctxObj.Get("action")
-	transactionIDVal := ctxObj.Get("transaction_id")
-	messageIDVal := ctxObj.Get("message_id")
-
-	if domainVal == nil || actionVal == nil || transactionIDVal == nil || messageIDVal == nil {
-		logger.Warn(ctx, "Missing required fields in context")
-		return appError.ErrMissingRequiredField
-	}
-
-	domain := string(domainVal.GetStringBytes())
-	action := string(actionVal.GetStringBytes())
-	transactionID := string(transactionIDVal.GetStringBytes())
-	messageID := string(messageIDVal.GetStringBytes())
+	domain := onSearchCtx.Domain
+	action := onSearchCtx.Action
+	transactionID := onSearchCtx.TransactionID
+	messageID := onSearchCtx.MessageID
 
 	if domain == "" || action == "" || transactionID == "" || messageID == "" {
 		logger.Warnf(ctx, "Empty required fields: domain=%s, action=%s, transaction_id=%s, message_id=%s", domain, action, transactionID, messageID)
-		return appError.ErrMissingRequiredField
+		return nil, appError.ErrMissingRequiredField
 	}
 
 	logger.Infof(ctx, "Extracted context: domain=%s, action=%s, transaction_id=%s, message_id=%s", domain, action, transactionID, messageID)
 
+	return &OnSearchCallback{
+		ID:            uuid.NewString(),
+		ReceivedAt:    time.Now().UTC(),
+		Domain:        domain,
+		Action:        action,
+		TransactionID: transactionID,
+		MessageID:     messageID,
+		BapID:         onSearchCtx.BapID,
+		BapURI:        onSearchCtx.BapURI,
+		BppID:         onSearchCtx.BppID,
+		BppURI:        onSearchCtx.BppURI,
+		City:          onSearchCtx.City,
+		CoreVersion:   onSearchCtx.CoreVersion,
+		Timestamp:     onSearchCtx.Timestamp,
+		TTL:           onSearchCtx.TTL,
+		Payload:       payload,
+	}, nil
+}
+
+// Validate checks the payload against the schema for its domain and action.
+func (s *OnSearchService) Validate(ctx context.Context, callback *OnSearchCallback) error {
 	// 2. Schema validation (domain/action aware)
-	logger.Infof(ctx, "Step 2: Validating payload against schema for domain=%s, action=%s", domain, action)
-	if err := s.validator.Validate(ctx, domain, action, payload); err != nil {
+	logger.Infof(ctx, "Step 2: Validating payload against schema for domain=%s, action=%s", callback.Domain, callback.Action)
+	err := s.validator.Validate(ctx, callback.Domain, callback.Action, callback.Payload)
+	if errors.Is(err, ports.ErrSchemaNotFound) && callback.Action != "on_search" {
+		// Lifecycle callbacks are accepted until their schemas are added
+		logger.Warnf(ctx, "No schema for domain=%s, action=%s, skipping validation", callback.Domain, callback.Action)
+		return nil
+	}
+	if err != nil {
 		logger.Errorf(ctx, err, "Schema validation failed")
 		return appError.NewCustomError(
 			400,
@@ -131,6 +247,15 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		)
 	}
 	logger.Info(ctx, "Schema validation passed")
+	return nil
+}
+
+// Persist uploads the payload to object storage and publishes a pointer
+// event referencing it.
+func (s *OnSearchService) Persist(ctx context.Context, callback *OnSearchCallback) error {
+	domain := callback.Domain
+	action := callback.Action
+	transactionID := callback.TransactionID
 
 	// 3. Upload raw payload to object storage
 	logger.Info(ctx, "Step 3: Uploading payload to object storage")
@@ -155,9 +280,10 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 	)
 
 	logger.Infof(ctx, "Uploading to object storage: %s", objectKey)
-	uploadedObjectKey, err := s.storage.Upload(ctx, objectKey, payload, "application/json")
+	uploadedObjectKey, err := s.storage.Upload(ctx, objectKey, callback.Payload, "application/json")
 	if err != nil {
 		logger.Errorf(ctx, err, "Failed to upload payload to object storage")
+		s.record(ctx, callback, ports.TransactionStatusFailed, "", err)
 		return appError.NewCustomError(
 			500,
 			appError.ErrDatabaseQueryFailed.Code,
@@ -166,20 +292,65 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		)
 	}
 	logger.Infof(ctx, "Successfully uploaded payload, object_key: %s", uploadedObjectKey)
+	s.record(ctx, callback, ports.TransactionStatusStored, uploadedObjectKey, nil)
+
+	if action != "on_search" {
+		s.dispatch(ctx, callback)
+		return nil
+	}
+
+	// Track the catalog version before indexing and publishing, which both
+	// depend on whether it is a full catalog or a delta
+	if s.catalogs != nil {
+		if err := s.catalogs.Track(ctx, callback, s.storage.GetBucket(), uploadedObjectKey); err != nil {
+			logger.Warnf(ctx, "Failed to track catalog versions of bpp_id=%s for transaction_id=%s: %v", callback.BppID, transactionID, err)
+		}
+	}
+
+	// Index the catalog so later searches can be answered locally.
+	if s.catalog != nil {
+		if err := s.catalog.Index(ctx, callback); err != nil {
+			logger.Warnf(ctx, "Failed to index catalog of bpp_id=%s for transaction_id=%s: %v", callback.BppID, transactionID, err)
+		}
+	}
+	if s.serviceable != nil {
+		if err := s.serviceable.IndexCatalog(ctx, callback); err != nil {
+			logger.Warnf(ctx, "Failed to refresh serviceability of bpp_id=%s for transaction_id=%s: %v", callback.BppID, transactionID, err)
+		}
+	}
+	if s.schedules != nil {
+		if err := s.schedules.Track(ctx, callback); err != nil {
+			logger.Warnf(ctx, "Failed to save store timing of bpp_id=%s for transaction_id=%s: %v", callback.BppID, transactionID, err)
+		}
+	}
 
 	// 4. Publish pointer message to Kafka
 	logger.Infof(ctx, "Step 4: Publishing pointer event to Kafka topic: %s", s.onSearchTopic)
-	bucket := s.storage.GetBucket()
-	pointer := onSearchPointer{
+	checksum := sha256.Sum256(callback.Payload)
+	pointer := events.OnSearchPointer{
 		Storage:       "minio",
-		Bucket:        bucket,
+		Bucket:        s.storage.GetBucket(),
 		ObjectKey:     uploadedObjectKey,
+		ContentType:   "application/json",
+		SizeBytes:     int64(len(callback.Payload)),
+		SHA256:        hex.EncodeToString(checksum[:]),
 		Domain:        domain,
 		Action:        action,
 		TransactionID: transactionID,
+		MessageID:     callback.MessageID,
+		BapID:         callback.BapID,
+		BppID:         callback.BppID,
+		City:          callback.City,
+		CoreVersion:   callback.CoreVersion,
+		Late:          callback.Late,
+		Mode:          callback.CatalogMode,
+	}
+	if len(callback.TimingViolations) > 0 {
+		pointer.TimingViolations = callback.TimingViolations
 	}
 
-	payloadBytes, err := json.Marshal(pointer)
+	event := events.NewEnvelope(s.eventSource, events.OnSearchPointerType, transactionID, events.OnSearchPointerSchemaPath, pointer)
+	payloadBytes, headers, err := event.Encode(pointerHeaders(pointer)...)
 	if err != nil {
 		logger.Errorf(ctx, err, "Failed to serialize pointer")
 		return appError.NewCustomError(
@@ -190,8 +361,9 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		)
 	}
 
-	if err := s.publisher.Publish(ctx, s.onSearchTopic, []byte(transactionID), payloadBytes); err != nil {
+	if err := s.publisher.Publish(ctx, s.onSearchTopic, []byte(transactionID), payloadBytes, headers...); err != nil {
 		logger.Errorf(ctx, err, "Failed to publish pointer to Kafka")
+		s.record(ctx, callback, ports.TransactionStatusFailed, uploadedObjectKey, err)
 		return appError.NewCustomError(
 			500,
 			appError.ErrHTTPInternalServer.Code,
@@ -200,6 +372,70 @@ func (s *OnSearchService) HandleOnSearch(ctx context.Context, payload []byte) (e
 		)
 	}
 	logger.Info(ctx, "Successfully published pointer event to Kafka")
+	s.record(ctx, callback, ports.TransactionStatusPublished, uploadedObjectKey, nil)
+	s.dispatch(ctx, callback)
+
+	// Count the part towards the search's complete catalog. Deltas are
+	// applied by the materializer instead.
+	if s.aggregator != nil && callback.CatalogMode != ports.CatalogModeIncremental {
+		if err := s.aggregator.AddPart(ctx, callback); err != nil {
+			logger.Warnf(ctx, "Failed to add on_search part of bpp_id=%s to aggregate of transaction_id=%s: %v", callback.BppID, transactionID, err)
+		}
+	}
 
 	return nil
 }
+
+// dispatch forwards an on_* callback to the buyer app. It runs once nothing
+// left can fail ingestion, so a callback retried after a failed upload or
+// publish is forwarded only once.
+func (s *OnSearchService) dispatch(ctx context.Context, callback *OnSearchCallback) {
+	if s.dispatcher != nil && strings.HasPrefix(callback.Action, "on_") {
+		s.dispatcher.Dispatch(ctx, callback)
+	}
+}
+
+// record upserts the callback's row in the transactions table. Tracking is
+// best effort: a failed write is logged and never fails ingestion.
+func (s *OnSearchService) record(ctx context.Context, callback *OnSearchCallback, status, objectKey string, cause error) {
+	if s.transactions == nil {
+		return
+	}
+
+	record := &ports.Transaction{
+		ID:            callback.ID,
+		TransactionID: callback.TransactionID,
+		MessageID:     callback.MessageID,
+		Domain:        callback.Domain,
+		Action:        callback.Action,
+		BapID:         callback.BapID,
+		BppID:         callback.BppID,
+		ObjectKey:     objectKey,
+		SizeBytes:     int64(len(callback.Payload)),
+		Status:        status,
+		OutOfOrder:    callback.Violation != "",
+		Violation:     callback.Violation,
+		TTL:           callback.TTL,
+		Late:          callback.Late,
+		CatalogMode:   callback.CatalogMode,
+		ReceivedAt:    callback.ReceivedAt,
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if timestamp, err := ondc.ParseTimestamp(callback.Timestamp); err == nil {
+		timestamp = timestamp.UTC()
+		record.ContextTimestamp = &timestamp
+	}
+	if len(callback.TimingViolations) > 0 {
+		record.TimingViolation = strings.Join(callback.TimingViolations, "; ")
+	}
+	if objectKey != "" {
+		record.Bucket = s.storage.GetBucket()
+	}
+	if cause != nil {
+		record.Error = cause.Error()
+	}
+
+	if err := s.transactions.Save(ctx, record); err != nil {
+		logger.Warnf(ctx, "Failed to record %s callback %s for transaction_id=%s: %v", status, callback.ID, callback.TransactionID, err)
+	}
+}
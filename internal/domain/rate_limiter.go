@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/internal/shared/ratelimit"
+)
+
+const (
+	RateLimitReasonRequests = "requests"
+	RateLimitReasonBytes    = "bytes"
+
+	rateLimiterIdleTimeout   = 10 * time.Minute
+	rateLimiterSweepInterval = time.Minute
+)
+
+// RateLimitDecision is the outcome of a rate limit check.
+type RateLimitDecision struct {
+	Allowed    bool
+	Reason     string
+	RetryAfter time.Duration
+}
+
+type subscriberLimits struct {
+	policy        ports.RateLimitPolicy
+	requests      *ratelimit.TokenBucket
+	bytes         *ratelimit.TokenBucket
+	policyExpires time.Time
+	lastSeen      time.Time
+}
+
+// RateLimiter enforces per-subscriber request and byte budgets with
+// in-memory token buckets. Policies come from a ports.RateLimitPolicyStore
+// and are re-read after policyTTL. At most maxKeys subscribers are
+// tracked; beyond that the least recently seen one is evicted.
+type RateLimiter struct {
+	store     ports.RateLimitPolicyStore
+	defaults  ports.RateLimitPolicy
+	policyTTL time.Duration
+	maxKeys   int
+
+	mu          sync.Mutex
+	subscribers map[string]*subscriberLimits
+	lastSweep   time.Time
+}
+
+// NewRateLimiter constructs a new RateLimiter. maxKeys <= 0 leaves the
+// number of tracked subscribers unbounded.
+func NewRateLimiter(store ports.RateLimitPolicyStore, defaults ports.RateLimitPolicy, policyTTL time.Duration, maxKeys int) *RateLimiter {
+	return &RateLimiter{
+		store:       store,
+		defaults:    defaults,
+		policyTTL:   policyTTL,
+		maxKeys:     maxKeys,
+		subscribers: make(map[string]*subscriberLimits),
+		lastSweep:   time.Now(),
+	}
+}
+
+// Allow charges one request of size bytes against the subscriber's budget.
+// A request rejected by either budget is charged against neither.
+func (l *RateLimiter) Allow(ctx context.Context, subscriberID string, size int) RateLimitDecision {
+	limits := l.limitsFor(ctx, subscriberID)
+
+	if limits.requests != nil {
+		if ok, wait := limits.requests.Take(1, false); !ok {
+			return RateLimitDecision{Reason: RateLimitReasonRequests, RetryAfter: wait}
+		}
+	}
+	if limits.bytes != nil {
+		if ok, wait := limits.bytes.Take(float64(size), true); !ok {
+			if limits.requests != nil {
+				limits.requests.Refund(1)
+			}
+			return RateLimitDecision{Reason: RateLimitReasonBytes, RetryAfter: wait}
+		}
+	}
+	return RateLimitDecision{Allowed: true}
+}
+
+func (l *RateLimiter) limitsFor(ctx context.Context, subscriberID string) *subscriberLimits {
+	now := time.Now()
+
+	l.mu.Lock()
+	l.sweep(now)
+	limits, ok := l.subscribers[subscriberID]
+	if ok && now.Before(limits.policyExpires) {
+		limits.lastSeen = now
+		l.mu.Unlock()
+		return limits
+	}
+	l.mu.Unlock()
+
+	policy := l.resolvePolicy(ctx, subscriberID)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limits, ok = l.subscribers[subscriberID]
+	if !ok || limits.policy.RequestsPerSecond != policy.RequestsPerSecond ||
+		limits.policy.Burst != policy.Burst || limits.policy.BytesPerMinute != policy.BytesPerMinute {
+		if !ok {
+			l.makeRoom(now)
+		}
+		limits = newSubscriberLimits(policy)
+		l.subscribers[subscriberID] = limits
+	}
+	limits.policyExpires = now.Add(l.policyTTL)
+	limits.lastSeen = now
+	return limits
+}
+
+func (l *RateLimiter) resolvePolicy(ctx context.Context, subscriberID string) ports.RateLimitPolicy {
+	policy := l.defaults
+	policy.SubscriberID = subscriberID
+	if l.store == nil {
+		return policy
+	}
+
+	override, err := l.store.GetPolicy(ctx, subscriberID)
+	if err != nil {
+		logger.Errorf(ctx, err, "Failed to load rate limit policy for %s, using defaults", subscriberID)
+		return policy
+	}
+	if override != nil {
+		policy = *override
+	}
+	return policy
+}
+
+// sweep drops limits for subscribers that have been idle for a while so the
+// map does not grow without bound. Callers must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for id, limits := range l.subscribers {
+		if now.Sub(limits.lastSeen) > rateLimiterIdleTimeout {
+			delete(l.subscribers, id)
+		}
+	}
+}
+
+// makeRoom evicts subscribers until a new one fits under maxKeys: idle
+// ones first, then the least recently seen. Callers must hold l.mu.
+func (l *RateLimiter) makeRoom(now time.Time) {
+	if l.maxKeys <= 0 || len(l.subscribers) < l.maxKeys {
+		return
+	}
+	l.lastSweep = time.Time{}
+	l.sweep(now)
+	for len(l.subscribers) >= l.maxKeys {
+		var oldestID string
+		var oldest time.Time
+		for id, limits := range l.subscribers {
+			if oldestID == "" || limits.lastSeen.Before(oldest) {
+				oldestID, oldest = id, limits.lastSeen
+			}
+		}
+		delete(l.subscribers, oldestID)
+	}
+}
+
+func newSubscriberLimits(policy ports.RateLimitPolicy) *subscriberLimits {
+	limits := &subscriberLimits{policy: policy}
+	if policy.RequestsPerSecond > 0 {
+		burst := float64(policy.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+		limits.requests = ratelimit.NewTokenBucket(burst, policy.RequestsPerSecond)
+	}
+	if policy.BytesPerMinute > 0 {
+		perMinute := float64(policy.BytesPerMinute)
+		limits.bytes = ratelimit.NewTokenBucket(perMinute, perMinute/60)
+	}
+	return limits
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"adapter/internal/ports"
+)
+
+func TestRateLimiterBytesRejectionKeepsRequestBudget(t *testing.T) {
+	limiter := NewRateLimiter(nil, ports.RateLimitPolicy{RequestsPerSecond: 0.001, Burst: 2, BytesPerMinute: 100}, time.Minute, 0)
+	ctx := context.Background()
+
+	if decision := limiter.Allow(ctx, "bpp", 150); !decision.Allowed {
+		t.Fatalf("first request rejected: %+v", decision)
+	}
+	// The byte budget is in debt now; each rejection must leave the request
+	// budget untouched so the reason stays bytes.
+	for i := 0; i < 3; i++ {
+		decision := limiter.Allow(ctx, "bpp", 10)
+		if decision.Allowed || decision.Reason != RateLimitReasonBytes {
+			t.Fatalf("request %d: got %+v, want a bytes rejection", i+2, decision)
+		}
+	}
+}
+
+func TestRateLimiterRequestRejection(t *testing.T) {
+	limiter := NewRateLimiter(nil, ports.RateLimitPolicy{RequestsPerSecond: 0.001, Burst: 1}, time.Minute, 0)
+	ctx := context.Background()
+
+	if decision := limiter.Allow(ctx, "bpp", 10); !decision.Allowed {
+		t.Fatalf("first request rejected: %+v", decision)
+	}
+	decision := limiter.Allow(ctx, "bpp", 10)
+	if decision.Allowed || decision.Reason != RateLimitReasonRequests || decision.RetryAfter <= 0 {
+		t.Fatalf("got %+v, want a requests rejection with a retry delay", decision)
+	}
+}
+
+func TestRateLimiterEvictsBeyondMaxKeys(t *testing.T) {
+	limiter := NewRateLimiter(nil, ports.RateLimitPolicy{RequestsPerSecond: 0.001, Burst: 1}, time.Minute, 2)
+	ctx := context.Background()
+
+	for _, key := range []string{"10.0.0.1", "10.0.0.2"} {
+		if decision := limiter.Allow(ctx, key, 10); !decision.Allowed {
+			t.Fatalf("first request of %s rejected: %+v", key, decision)
+		}
+	}
+	// 10.0.0.1 was seen least recently and makes room for a third key.
+	if decision := limiter.Allow(ctx, "10.0.0.3", 10); !decision.Allowed {
+		t.Fatalf("first request of 10.0.0.3 rejected: %+v", decision)
+	}
+	if got := len(limiter.subscribers); got != 2 {
+		t.Fatalf("tracking %d subscribers, want 2", got)
+	}
+	if _, ok := limiter.subscribers["10.0.0.1"]; ok {
+		t.Fatal("least recently seen subscriber was not evicted")
+	}
+	if decision := limiter.Allow(ctx, "10.0.0.2", 10); decision.Allowed {
+		t.Fatal("kept subscriber lost its budget")
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"adapter/internal/ports"
+	"adapter/pkg/ondc"
+)
+
+// routeSearch narrows the adapters serving a search's domain and city to
+// those whose providers can answer it. A search addressed to one bpp_id
+// goes only to the adapter that owns it. Adapters whose providers are in
+// the serviceability index must have a location serving the intent's
+// category at its delivery point or area code; the categories and area
+// codes entered on the adapter are only used for adapters with no indexed
+// catalog yet. Intent fields the buyer left out do not restrict routing.
+func routeSearch(adapters []ports.Adapter, search *ondc.Search, index *ServiceabilityIndex) []ports.Adapter {
+	intent := search.Message.Intent
+	var serving map[string]bool
+	if index != nil {
+		query := ServiceabilityQuery{
+			AreaCode: intent.AreaCode(),
+			Category: intent.CategoryID(),
+			Domain:   search.Context.Domain,
+		}
+		// A malformed point is left to the adapters to reject
+		if point, err := ondc.ParseGPS(intent.GPS()); err == nil {
+			query.GPS = &point
+		}
+		serving = index.ServingBpps(query)
+	}
+
+	var routed []ports.Adapter
+	for _, adapter := range adapters {
+		if search.Context.BppID != "" && adapter.BppID != search.Context.BppID {
+			continue
+		}
+		if index != nil && index.Indexes(adapter.BppID) {
+			if !serving[adapter.BppID] {
+				continue
+			}
+		} else if !adapter.Matches(intent.CategoryID(), intent.AreaCode()) {
+			continue
+		}
+		routed = append(routed, adapter)
+	}
+	return routed
+}
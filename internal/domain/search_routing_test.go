@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"adapter/internal/ports"
+	"adapter/pkg/ondc"
+)
+
+func TestRouteSearch(t *testing.T) {
+	adapters := []ports.Adapter{
+		{ID: "grocery", BppID: "grocery.example.com", Categories: "Foodgrains,Dairy", AreaCodes: "560001,560002"},
+		{ID: "bakery", BppID: "bakery.example.com", Categories: "Bakery", AreaCodes: "*"},
+		{ID: "everything", BppID: "everything.example.com"},
+		// Indexed adapters are routed by their catalogs' serviceability,
+		// whatever their own lists say.
+		{ID: "kirana", BppID: "kirana.example.com", Categories: "Bakery", AreaCodes: "110001"},
+		{ID: "pharmacy", BppID: "pharmacy.example.com", AreaCodes: "560001"},
+	}
+	index := NewServiceabilityIndex()
+	index.Update("kirana.example.com", "P1", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+		{LocationID: "L1", Category: "Dairy", Type: ondc.ServiceabilityHyperlocal, Center: &ondc.Point{Lat: 12.97, Lng: 77.59}, RadiusKm: 3},
+	})
+	index.Update("pharmacy.example.com", "P1", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+		{LocationID: "L1", Category: "Pharma", Type: ondc.ServiceabilityIntercity, AreaCodes: []string{"560001-560010"}},
+	})
+
+	tests := []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{
+			name:   "no intent reaches every adapter",
+			search: `{"context":{},"message":{"intent":{}}}`,
+			want:   []string{"grocery", "bakery", "everything", "kirana", "pharmacy"},
+		},
+		{
+			name:   "category",
+			search: `{"context":{},"message":{"intent":{"category":{"id":"Dairy"}}}}`,
+			want:   []string{"grocery", "everything", "kirana"},
+		},
+		{
+			name:   "area code",
+			search: `{"context":{},"message":{"intent":{"fulfillment":{"end":{"location":{"address":{"area_code":"110001"}}}}}}}`,
+			want:   []string{"bakery", "everything"},
+		},
+		{
+			name:   "area code in an indexed range",
+			search: `{"context":{},"message":{"intent":{"fulfillment":{"end":{"location":{"address":{"area_code":"560005"}}}}}}}`,
+			want:   []string{"bakery", "everything", "pharmacy"},
+		},
+		{
+			name:   "category and area code",
+			search: `{"context":{},"message":{"intent":{"category":{"id":"Foodgrains"},"fulfillment":{"end":{"location":{"address":{"area_code":"560002"}}}}}}}`,
+			want:   []string{"grocery", "everything"},
+		},
+		{
+			name:   "gps inside an indexed circle",
+			search: `{"context":{},"message":{"intent":{"category":{"id":"Dairy"},"fulfillment":{"end":{"location":{"gps":"12.98,77.60"}}}}}}`,
+			want:   []string{"grocery", "everything", "kirana"},
+		},
+		{
+			name:   "gps outside the indexed circle",
+			search: `{"context":{},"message":{"intent":{"category":{"id":"Dairy"},"fulfillment":{"end":{"location":{"gps":"13.20,77.60"}}}}}}`,
+			want:   []string{"grocery", "everything"},
+		},
+		{
+			name:   "category the indexed catalog does not serve",
+			search: `{"context":{"bpp_id":"kirana.example.com"},"message":{"intent":{"category":{"id":"Bakery"}}}}`,
+		},
+		{
+			name:   "category not delivered to the area",
+			search: `{"context":{},"message":{"intent":{"category":{"id":"Foodgrains"},"fulfillment":{"end":{"location":{"address":{"area_code":"110001"}}}}}}}`,
+			want:   []string{"everything"},
+		},
+		{
+			name:   "addressed to one bpp_id",
+			search: `{"context":{"bpp_id":"bakery.example.com"},"message":{"intent":{}}}`,
+			want:   []string{"bakery"},
+		},
+		{
+			name:   "bpp_id that does not carry the category",
+			search: `{"context":{"bpp_id":"bakery.example.com"},"message":{"intent":{"category":{"id":"Dairy"}}}}`,
+		},
+		{
+			name:   "unknown bpp_id",
+			search: `{"context":{"bpp_id":"unknown.example.com"},"message":{"intent":{}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search, err := ondc.ParseSearch([]byte(tt.search))
+			if err != nil {
+				t.Fatalf("ParseSearch: %v", err)
+			}
+			var got []string
+			for _, adapter := range routeSearch(adapters, search, index) {
+				got = append(got, adapter.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("routed to %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteSearchWithoutIndex(t *testing.T) {
+	adapters := []ports.Adapter{{ID: "bakery", BppID: "bakery.example.com", Categories: "Bakery"}}
+	search, err := ondc.ParseSearch([]byte(`{"context":{},"message":{"intent":{"category":{"id":"Dairy"}}}}`))
+	if err != nil {
+		t.Fatalf("ParseSearch: %v", err)
+	}
+	if got := routeSearch(adapters, search, nil); len(got) != 0 {
+		t.Fatalf("routed to %v, want none", got)
+	}
+}
+
+func TestAdapterMatches(t *testing.T) {
+	tests := []struct {
+		categories, areaCodes string
+		category, areaCode    string
+		want                  bool
+	}{
+		{categories: "", areaCodes: "", category: "Dairy", areaCode: "560001", want: true},
+		{categories: "Dairy", areaCodes: "560001", category: "", areaCode: "", want: true},
+		{categories: "Bakery,Dairy", areaCodes: "560001", category: "Dairy", areaCode: "560001", want: true},
+		{categories: "Bakery", areaCodes: "560001", category: "Dairy", areaCode: "560001", want: false},
+		{categories: "*", areaCodes: "560001", category: "Dairy", areaCode: "560002", want: false},
+		{categories: "*", areaCodes: "*", category: "Dairy", areaCode: "560002", want: true},
+		// Entries are matched whole, not as prefixes.
+		{categories: "Dairy Products", areaCodes: "", category: "Dairy", want: false},
+	}
+	for _, tt := range tests {
+		adapter := ports.Adapter{Categories: tt.categories, AreaCodes: tt.areaCodes}
+		if got := adapter.Matches(tt.category, tt.areaCode); got != tt.want {
+			t.Errorf("Adapter{%q, %q}.Matches(%q, %q) = %v, want %v", tt.categories, tt.areaCodes, tt.category, tt.areaCode, got, tt.want)
+		}
+	}
+}
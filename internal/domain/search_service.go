@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fastjson"
+
+	"adapter/internal/ports"
+	"adapter/internal/shared/ack"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// SearchService accepts buyer app search requests and fans them out to
+// the seller adapters serving the requested domain and city whose
+// providers match the intent's category and delivery area, as known from
+// the serviceability index or else the adapter's own lists. Adapters
+// answer asynchronously with on_search callbacks, which arrive through the
+// regular /on-search ingestion pipeline. When a catalog index and a
+// dispatcher are configured, adapters whose catalogs are indexed and fresh
+// are answered locally with signed on_search responses instead.
+type SearchService struct {
+	validator      ports.SchemaValidator
+	adapters       *AdapterRegistry
+	transactions   ports.TransactionRepository
+	catalog        *CatalogIndex
+	serviceability *ServiceabilityIndex
+	dispatcher     *CallbackDispatcher
+	client         *http.Client
+	defaultTTL     time.Duration
+	maxTimeout     time.Duration
+	concurrency    int
+
+	// mu orders HandleSearch's wg.Add before Close's wg.Wait.
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// SearchServiceConfig tunes the fan-out.
+type SearchServiceConfig struct {
+	// DefaultTTL applies when context.ttl is absent.
+	DefaultTTL time.Duration
+	// MaxTimeout caps the per-adapter request timeout derived from the TTL.
+	MaxTimeout time.Duration
+	// Concurrency bounds the adapters called in parallel for one search.
+	Concurrency int
+}
+
+// NewSearchService constructs a new SearchService. catalog and dispatcher
+// may be nil, in which case every search is forwarded live; without
+// serviceability, searches are routed by the adapters' own lists.
+func NewSearchService(validator ports.SchemaValidator, adapters *AdapterRegistry, transactions ports.TransactionRepository, catalog *CatalogIndex, serviceability *ServiceabilityIndex, dispatcher *CallbackDispatcher, cfg SearchServiceConfig) *SearchService {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	return &SearchService{
+		validator:      validator,
+		adapters:       adapters,
+		transactions:   transactions,
+		catalog:        catalog,
+		serviceability: serviceability,
+		dispatcher:     dispatcher,
+		client:         &http.Client{},
+		defaultTTL:     cfg.DefaultTTL,
+		maxTimeout:     cfg.MaxTimeout,
+		concurrency:    cfg.Concurrency,
+	}
+}
+
+// HandleSearch validates a search request and starts the fan-out in the
+// background. It returns once the request is accepted.
+func (s *SearchService) HandleSearch(ctx context.Context, payload []byte) error {
+	request, err := ParseOnSearchCallback(ctx, payload)
+	if err != nil {
+		return err
+	}
+	if request.Action != "search" {
+		return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, fmt.Sprintf("context.action must be search, got %q", request.Action))
+	}
+
+	err = s.validator.Validate(ctx, request.Domain, request.Action, request.Payload)
+	if errors.Is(err, ports.ErrSchemaNotFound) {
+		logger.Warnf(ctx, "No schema for domain=%s, action=search, skipping validation", request.Domain)
+	} else if err != nil {
+		logger.Errorf(ctx, err, "Search schema validation failed")
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, fmt.Sprintf("schema validation failed: %v", err))
+	}
+
+	search, err := ondc.ParseSearch(request.Payload)
+	if err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, appError.ErrInvalidRequestBody.Message, err.Error())
+	}
+	inc, err := search.Message.Intent.CatalogInc()
+	if err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, appError.ErrInvalidFieldFormat.Message, err.Error())
+	}
+	// Recorded so on_search responses, including later pushes under this
+	// transaction, can be classified as deltas
+	request.CatalogMode = ports.CatalogModeFull
+	if inc != nil {
+		request.CatalogMode = ports.CatalogModeIncremental
+	}
+
+	ttl := s.defaultTTL
+	if request.TTL != "" {
+		if ttl, err = ondc.ParseDuration(request.TTL); err != nil {
+			return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, appError.ErrInvalidFieldFormat.Message, err.Error())
+		}
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return appError.ErrSearchUnavailable
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	// Recorded before the ACK so callback TTL checks can find the search
+	s.record(ctx, request, request.ID, "", ports.TransactionStatusReceived, nil)
+
+	go func() {
+		defer s.wg.Done()
+		// The fan-out outlives the HTTP request but not the search window
+		fanOutCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ttl)
+		defer cancel()
+		s.fanOut(fanOutCtx, request, search, ttl)
+	}()
+	return nil
+}
+
+func (s *SearchService) fanOut(ctx context.Context, request *OnSearchCallback, search *ondc.Search, ttl time.Duration) {
+	serving, err := s.adapters.Serving(ctx, request.Domain, request.City)
+	if err != nil {
+		logger.Errorf(ctx, err, "Failed to look up adapters for search transaction_id=%s", request.TransactionID)
+		s.record(ctx, request, request.ID, "", ports.TransactionStatusFailed, err)
+		return
+	}
+	adapters := routeSearch(serving, search, s.serviceability)
+	intent := search.Message.Intent
+	if len(adapters) == 0 {
+		logger.Warnf(ctx, "No adapter matches search transaction_id=%s (domain=%s city=%s category=%s area_code=%s, %d serving the city)",
+			request.TransactionID, request.Domain, request.City, intent.CategoryID(), intent.AreaCode(), len(serving))
+		s.record(ctx, request, request.ID, "", ports.TransactionStatusFailed, fmt.Errorf("no adapter matches domain %s, city %s, category %q, area code %q", request.Domain, request.City, intent.CategoryID(), intent.AreaCode()))
+		return
+	}
+
+	// Adapters with a fresh local catalog are answered without calling them
+	live := make([]ports.Adapter, 0, len(adapters))
+	served := 0
+	for _, adapter := range adapters {
+		if s.answerLocally(ctx, request, search, &adapter) {
+			served++
+			continue
+		}
+		live = append(live, adapter)
+	}
+	if len(live) == 0 {
+		s.record(ctx, request, request.ID, "", ports.TransactionStatusServed, nil)
+		logger.Infof(ctx, "Search transaction_id=%s answered from the local catalog for all %d adapters", request.TransactionID, served)
+		return
+	}
+	adapters = live
+
+	timeout := ttl
+	if s.maxTimeout > 0 && timeout > s.maxTimeout {
+		timeout = s.maxTimeout
+	}
+
+	logger.Infof(ctx, "Fanning out search transaction_id=%s to %d of %d adapters serving the city with %s timeout (%d answered locally)", request.TransactionID, len(adapters), len(serving), timeout, served)
+	var forwarded atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+	for i := range adapters {
+		adapter := &adapters[i]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := s.forward(ctx, adapter, request.Payload, timeout)
+			status := ports.TransactionStatusForwarded
+			if err != nil {
+				logger.Warnf(ctx, "Failed to forward search transaction_id=%s to adapter %s: %v", request.TransactionID, adapter.Name, err)
+				status = ports.TransactionStatusFailed
+			} else {
+				forwarded.Add(1)
+			}
+			s.record(ctx, request, uuid.NewString(), adapter.BppID, status, err)
+		}()
+	}
+	wg.Wait()
+
+	status := ports.TransactionStatusForwarded
+	var cause error
+	if forwarded.Load() == 0 && served > 0 {
+		status = ports.TransactionStatusServed
+	} else if forwarded.Load() == 0 {
+		status = ports.TransactionStatusFailed
+		cause = fmt.Errorf("no adapter accepted the search")
+	}
+	s.record(ctx, request, request.ID, "", status, cause)
+	logger.Infof(ctx, "Search transaction_id=%s forwarded to %d of %d adapters", request.TransactionID, forwarded.Load(), len(adapters))
+}
+
+// answerLocally sends the on_search responses for adapter's BPP built from
+// the catalog index. It returns false when the search has to be forwarded
+// to the adapter instead: local answers are disabled, the adapter has no
+// bpp_id, or its indexed catalog is missing or stale.
+func (s *SearchService) answerLocally(ctx context.Context, request *OnSearchCallback, search *ondc.Search, adapter *ports.Adapter) bool {
+	if s.catalog == nil || s.dispatcher == nil || adapter.BppID == "" || request.BapURI == "" {
+		return false
+	}
+	responses, fresh, err := s.catalog.Answer(ctx, request, search, adapter.BppID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to search local catalog of bpp_id=%s, forwarding instead: %v", adapter.BppID, err)
+		return false
+	}
+	if !fresh {
+		return false
+	}
+
+	for _, response := range responses {
+		s.dispatcher.Dispatch(ctx, &OnSearchCallback{
+			ID:            uuid.NewString(),
+			ReceivedAt:    time.Now().UTC(),
+			Domain:        request.Domain,
+			Action:        "on_search",
+			TransactionID: request.TransactionID,
+			MessageID:     request.MessageID,
+			BapID:         request.BapID,
+			BapURI:        request.BapURI,
+			BppID:         adapter.BppID,
+			City:          request.City,
+			CoreVersion:   request.CoreVersion,
+			Payload:       response,
+		})
+	}
+	logger.Infof(ctx, "Answered search transaction_id=%s for bpp_id=%s from the local catalog with %d providers", request.TransactionID, adapter.BppID, len(responses))
+	s.record(ctx, request, uuid.NewString(), adapter.BppID, ports.TransactionStatusServed, nil)
+	return true
+}
+
+// forward posts the search to one adapter and checks its ACK.
+func (s *SearchService) forward(ctx context.Context, adapter *ports.Adapter, payload []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(adapter.URL, "/")+"/search", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", adapter.APIKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("adapter returned %s", resp.Status)
+	}
+	if status := fastjson.GetString(body, "message", "ack", "status"); status == ack.StatusNack {
+		return fmt.Errorf("adapter returned NACK: %s", fastjson.GetString(body, "error", "message"))
+	}
+	return nil
+}
+
+// Close stops accepting searches and waits for running fan-outs.
+func (s *SearchService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("search fan-out did not finish: %w", ctx.Err())
+	}
+}
+
+// record upserts a transactions row for the search (id = request.ID) or
+// for one adapter it was forwarded to. Tracking is best effort.
+func (s *SearchService) record(ctx context.Context, request *OnSearchCallback, id, bppID, status string, cause error) {
+	if s.transactions == nil {
+		return
+	}
+
+	record := &ports.Transaction{
+		ID:            id,
+		TransactionID: request.TransactionID,
+		MessageID:     request.MessageID,
+		Domain:        request.Domain,
+		Action:        request.Action,
+		BapID:         request.BapID,
+		BppID:         bppID,
+		SizeBytes:     int64(len(request.Payload)),
+		Status:        status,
+		TTL:           request.TTL,
+		CatalogMode:   request.CatalogMode,
+		ReceivedAt:    request.ReceivedAt,
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if timestamp, err := ondc.ParseTimestamp(request.Timestamp); err == nil {
+		timestamp = timestamp.UTC()
+		record.ContextTimestamp = &timestamp
+	}
+	if cause != nil {
+		record.Error = cause.Error()
+	}
+
+	if err := s.transactions.Save(ctx, record); err != nil {
+		logger.Warnf(ctx, "Failed to record %s search %s for transaction_id=%s: %v", status, id, request.TransactionID, err)
+	}
+}
@@ -0,0 +1,409 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+const (
+	// serviceabilityCellDegrees is the grid cell size, about 11 km.
+	serviceabilityCellDegrees = 0.1
+	// maxCellsPerArea bounds the cells one area is bucketed into; larger
+	// areas are kept in the wide list and checked on every query.
+	maxCellsPerArea = 4096
+	kmPerDegree     = 111.32
+)
+
+// ServiceabilityMatch is a provider location serving a queried point or
+// area code.
+type ServiceabilityMatch struct {
+	BppID      string `json:"bpp_id"`
+	ProviderID string `json:"provider_id"`
+	LocationID string `json:"location_id,omitempty"`
+	Domain     string `json:"domain"`
+	City       string `json:"city,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Type       string `json:"type"`
+	// DistanceKm is set for hyperlocal areas: the distance from the
+	// location to the queried point.
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+}
+
+// ServiceabilityQuery selects the areas serving a point or area code.
+// Category and Domain are optional filters.
+type ServiceabilityQuery struct {
+	GPS      *ondc.Point
+	AreaCode string
+	Category string
+	Domain   string
+}
+
+// ServiceabilityStats describes the index size.
+type ServiceabilityStats struct {
+	Providers int `json:"providers"`
+	Areas     int `json:"areas"`
+	Cells     int `json:"cells"`
+	Wide      int `json:"wide"`
+	AreaCodes int `json:"area_codes"`
+}
+
+type providerKey struct {
+	bppID      string
+	providerID string
+}
+
+type cellKey struct {
+	lat int
+	lng int
+}
+
+type indexedArea struct {
+	provider providerKey
+	domain   string
+	city     string
+	area     ondc.ServiceArea
+}
+
+type indexedProvider struct {
+	areas []*indexedArea
+	cells []cellKey
+}
+
+// ServiceabilityIndex answers which provider locations serve a GPS point
+// or pincode for a category. Hyperlocal circles and polygons are bucketed
+// into a fixed lat/lng grid, pincode lists into a map, and pan-India or
+// very large areas are checked on every query. It is rebuilt per provider
+// as catalogs arrive and kept in memory only.
+type ServiceabilityIndex struct {
+	mu         sync.RWMutex
+	providers  map[providerKey]*indexedProvider
+	cells      map[cellKey][]*indexedArea
+	wide       []*indexedArea
+	areaCodes  map[string][]*indexedArea
+	areaRanges []*indexedArea
+	areaCount  int
+	// bpps counts the indexed providers of each seller app.
+	bpps map[string]int
+}
+
+// NewServiceabilityIndex constructs an empty ServiceabilityIndex.
+func NewServiceabilityIndex() *ServiceabilityIndex {
+	return &ServiceabilityIndex{
+		providers: make(map[providerKey]*indexedProvider),
+		cells:     make(map[cellKey][]*indexedArea),
+		areaCodes: make(map[string][]*indexedArea),
+		bpps:      make(map[string]int),
+	}
+}
+
+// IndexCatalog refreshes the providers of an on_search callback. Providers
+// of an incremental catalog that carry no serviceability keep their
+// indexed areas.
+func (x *ServiceabilityIndex) IndexCatalog(ctx context.Context, callback *OnSearchCallback) error {
+	providers := 0
+	err := ondc.EachProvider(callback.Payload, func(provider *ondc.Provider) error {
+		if provider.ID == "" {
+			return nil
+		}
+		areas := provider.ServiceAreas()
+		if len(areas) == 0 && callback.CatalogMode == ports.CatalogModeIncremental {
+			return nil
+		}
+		x.Update(callback.BppID, provider.ID, callback.Domain, callback.City, areas)
+		providers++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Refreshed serviceability of %d providers of bpp_id=%s", providers, callback.BppID)
+	return nil
+}
+
+// Load indexes providers kept in the catalog index, so serviceability is
+// known before their next catalog arrives.
+func (x *ServiceabilityIndex) Load(ctx context.Context, repo ports.CatalogRepository) error {
+	loaded := 0
+	err := repo.ScanProviders(ctx, func(providers []ports.CatalogProvider) error {
+		for _, stored := range providers {
+			var provider ondc.Provider
+			if err := json.Unmarshal([]byte(stored.Provider), &provider); err != nil {
+				logger.Warnf(ctx, "Skipping unreadable indexed provider %s of bpp_id=%s: %v", stored.ProviderID, stored.BppID, err)
+				continue
+			}
+			x.Update(stored.BppID, stored.ProviderID, stored.Domain, stored.City, provider.ServiceAreas())
+			loaded++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Loaded serviceability of %d providers", loaded)
+	return nil
+}
+
+// Update replaces the indexed areas of one provider.
+func (x *ServiceabilityIndex) Update(bppID, providerID, domain, city string, areas []ondc.ServiceArea) {
+	key := providerKey{bppID: bppID, providerID: providerID}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(key)
+	if len(areas) == 0 {
+		return
+	}
+
+	entry := &indexedProvider{}
+	for _, area := range areas {
+		indexed := &indexedArea{provider: key, domain: domain, city: city, area: area}
+		entry.areas = append(entry.areas, indexed)
+
+		switch area.Type {
+		case ondc.ServiceabilityIntercity:
+			for _, code := range area.AreaCodes {
+				if strings.Contains(code, "-") {
+					x.areaRanges = append(x.areaRanges, indexed)
+					continue
+				}
+				x.areaCodes[code] = append(x.areaCodes[code], indexed)
+			}
+		case ondc.ServiceabilityHyperlocal, ondc.ServiceabilityPolygon:
+			cells := areaCells(area)
+			if cells == nil {
+				x.wide = append(x.wide, indexed)
+				continue
+			}
+			for _, cell := range cells {
+				x.cells[cell] = append(x.cells[cell], indexed)
+			}
+			entry.cells = append(entry.cells, cells...)
+		default:
+			x.wide = append(x.wide, indexed)
+		}
+	}
+	x.providers[key] = entry
+	x.areaCount += len(entry.areas)
+	x.bpps[bppID]++
+}
+
+// remove drops every area of a provider. The caller holds the lock.
+func (x *ServiceabilityIndex) remove(key providerKey) {
+	entry, ok := x.providers[key]
+	if !ok {
+		return
+	}
+	delete(x.providers, key)
+	x.areaCount -= len(entry.areas)
+	if x.bpps[key.bppID]--; x.bpps[key.bppID] == 0 {
+		delete(x.bpps, key.bppID)
+	}
+
+	owned := func(area *indexedArea) bool { return area.provider == key }
+	for _, cell := range entry.cells {
+		if kept := withoutAreas(x.cells[cell], owned); len(kept) > 0 {
+			x.cells[cell] = kept
+		} else {
+			delete(x.cells, cell)
+		}
+	}
+	x.wide = withoutAreas(x.wide, owned)
+	x.areaRanges = withoutAreas(x.areaRanges, owned)
+	for _, area := range entry.areas {
+		for _, code := range area.area.AreaCodes {
+			if kept := withoutAreas(x.areaCodes[code], owned); len(kept) > 0 {
+				x.areaCodes[code] = kept
+			} else {
+				delete(x.areaCodes, code)
+			}
+		}
+	}
+}
+
+// Query returns the provider locations serving the query, nearest
+// hyperlocal locations first.
+func (x *ServiceabilityIndex) Query(query ServiceabilityQuery) []ServiceabilityMatch {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var candidates []*indexedArea
+	if query.GPS != nil {
+		candidates = append(candidates, x.cells[cellOf(*query.GPS)]...)
+	}
+	if query.AreaCode != "" {
+		candidates = append(candidates, x.areaCodes[query.AreaCode]...)
+		candidates = append(candidates, x.areaRanges...)
+	}
+	candidates = append(candidates, x.wide...)
+
+	type matchKey struct {
+		provider providerKey
+		location string
+		category string
+	}
+	seen := make(map[matchKey]bool)
+	var matches []ServiceabilityMatch
+	for _, candidate := range candidates {
+		area := candidate.area
+		if query.Domain != "" && candidate.domain != query.Domain {
+			continue
+		}
+		if !categoryMatches(area, query.Category) {
+			continue
+		}
+		// Areas needing a point or code the query lacks do not match
+		if (area.Type == ondc.ServiceabilityHyperlocal || area.Type == ondc.ServiceabilityPolygon) && query.GPS == nil {
+			continue
+		}
+		if area.Type == ondc.ServiceabilityIntercity && query.AreaCode == "" {
+			continue
+		}
+		if !area.Covers(query.GPS, query.AreaCode) {
+			continue
+		}
+
+		key := matchKey{provider: candidate.provider, location: area.LocationID, category: area.Category}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		match := ServiceabilityMatch{
+			BppID:      candidate.provider.bppID,
+			ProviderID: candidate.provider.providerID,
+			LocationID: area.LocationID,
+			Domain:     candidate.domain,
+			City:       candidate.city,
+			Category:   area.Category,
+			Type:       area.Type,
+		}
+		if area.Center != nil && query.GPS != nil {
+			distance := math.Round(ondc.DistanceKm(*area.Center, *query.GPS)*1000) / 1000
+			match.DistanceKm = &distance
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i].DistanceKm, matches[j].DistanceKm
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+	return matches
+}
+
+// Indexes reports whether any provider of the seller app is indexed.
+func (x *ServiceabilityIndex) Indexes(bppID string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.bpps[bppID] > 0
+}
+
+// ServingBpps returns the seller apps with a provider location serving
+// the query. A query without a point or area code leaves the delivery
+// location open, so only its domain and category are matched.
+func (x *ServiceabilityIndex) ServingBpps(query ServiceabilityQuery) map[string]bool {
+	serving := make(map[string]bool)
+	if query.GPS != nil || query.AreaCode != "" {
+		for _, match := range x.Query(query) {
+			serving[match.BppID] = true
+		}
+		return serving
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	for key, entry := range x.providers {
+		for _, area := range entry.areas {
+			if (query.Domain == "" || area.domain == query.Domain) && categoryMatches(area.area, query.Category) {
+				serving[key.bppID] = true
+				break
+			}
+		}
+	}
+	return serving
+}
+
+// Stats returns the index size.
+func (x *ServiceabilityIndex) Stats() ServiceabilityStats {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return ServiceabilityStats{
+		Providers: len(x.providers),
+		Areas:     x.areaCount,
+		Cells:     len(x.cells),
+		Wide:      len(x.wide),
+		AreaCodes: len(x.areaCodes),
+	}
+}
+
+// categoryMatches reports whether an area serves category. Areas without
+// a category, or with "*", serve every category.
+func categoryMatches(area ondc.ServiceArea, category string) bool {
+	return category == "" || area.Category == "" || area.Category == "*" || strings.EqualFold(area.Category, category)
+}
+
+func cellOf(p ondc.Point) cellKey {
+	return cellKey{
+		lat: int(math.Floor(p.Lat / serviceabilityCellDegrees)),
+		lng: int(math.Floor(p.Lng / serviceabilityCellDegrees)),
+	}
+}
+
+// areaCells returns the grid cells overlapping the area's bounding box, or
+// nil when there are more than maxCellsPerArea.
+func areaCells(area ondc.ServiceArea) []cellKey {
+	var minLat, maxLat, minLng, maxLng float64
+	switch {
+	case area.Center != nil:
+		latSpan := area.RadiusKm / kmPerDegree
+		lngSpan := area.RadiusKm / (kmPerDegree * math.Max(math.Cos(area.Center.Lat*math.Pi/180), 0.01))
+		minLat, maxLat = area.Center.Lat-latSpan, area.Center.Lat+latSpan
+		minLng, maxLng = area.Center.Lng-lngSpan, area.Center.Lng+lngSpan
+	case len(area.Polygon) > 0:
+		minLat, maxLat = area.Polygon[0].Lat, area.Polygon[0].Lat
+		minLng, maxLng = area.Polygon[0].Lng, area.Polygon[0].Lng
+		for _, p := range area.Polygon[1:] {
+			minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+			minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+		}
+	default:
+		return nil
+	}
+
+	from := cellOf(ondc.Point{Lat: minLat, Lng: minLng})
+	to := cellOf(ondc.Point{Lat: maxLat, Lng: maxLng})
+	if (to.lat-from.lat+1)*(to.lng-from.lng+1) > maxCellsPerArea {
+		return nil
+	}
+	cells := make([]cellKey, 0, (to.lat-from.lat+1)*(to.lng-from.lng+1))
+	for lat := from.lat; lat <= to.lat; lat++ {
+		for lng := from.lng; lng <= to.lng; lng++ {
+			cells = append(cells, cellKey{lat: lat, lng: lng})
+		}
+	}
+	return cells
+}
+
+func withoutAreas(areas []*indexedArea, drop func(*indexedArea) bool) []*indexedArea {
+	kept := areas[:0]
+	for _, area := range areas {
+		if !drop(area) {
+			kept = append(kept, area)
+		}
+	}
+	// Clear the tail so removed areas can be collected
+	for i := len(kept); i < len(areas); i++ {
+		areas[i] = nil
+	}
+	return kept
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"adapter/pkg/ondc"
+)
+
+// testServiceabilityIndex indexes one provider per serviceability type.
+func testServiceabilityIndex() *ServiceabilityIndex {
+	index := NewServiceabilityIndex()
+	index.Update("bpp-a", "circle", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+		{LocationID: "L1", Category: "Grocery", Type: ondc.ServiceabilityHyperlocal, Center: &ondc.Point{Lat: 12.97, Lng: 77.59}, RadiusKm: 3},
+	})
+	index.Update("bpp-a", "polygon", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+		{LocationID: "L2", Category: "*", Type: ondc.ServiceabilityPolygon, Polygon: []ondc.Point{
+			{Lat: 12.90, Lng: 77.60}, {Lat: 12.90, Lng: 77.65}, {Lat: 12.95, Lng: 77.65}, {Lat: 12.95, Lng: 77.60}, {Lat: 12.90, Lng: 77.60},
+		}},
+	})
+	index.Update("bpp-b", "pincodes", "ONDC:RET12", "std:080", []ondc.ServiceArea{
+		{LocationID: "L3", Category: "Fashion", Type: ondc.ServiceabilityIntercity, AreaCodes: []string{"560001", "110001-110010"}},
+	})
+	index.Update("bpp-c", "pan-india", "ONDC:RET14", "std:011", []ondc.ServiceArea{
+		{LocationID: "L4", Category: "Electronics", Type: ondc.ServiceabilityPanIndia},
+	})
+	return index
+}
+
+func matchedProviders(matches []ServiceabilityMatch) []string {
+	var providers []string
+	for _, match := range matches {
+		providers = append(providers, match.BppID+"/"+match.ProviderID)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+func TestServiceabilityIndexQuery(t *testing.T) {
+	index := testServiceabilityIndex()
+
+	tests := []struct {
+		name  string
+		query ServiceabilityQuery
+		want  []string
+	}{
+		{
+			name:  "inside the circle",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.98, Lng: 77.60}},
+			want:  []string{"bpp-a/circle", "bpp-c/pan-india"},
+		},
+		{
+			name:  "outside the circle",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 13.10, Lng: 77.59}},
+			want:  []string{"bpp-c/pan-india"},
+		},
+		{
+			name:  "inside the polygon",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.92, Lng: 77.62}},
+			want:  []string{"bpp-a/polygon", "bpp-c/pan-india"},
+		},
+		{
+			name:  "in the polygon's bounding cells but outside it",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.92, Lng: 77.67}},
+			want:  []string{"bpp-c/pan-india"},
+		},
+		{
+			name:  "pincode",
+			query: ServiceabilityQuery{AreaCode: "560001"},
+			want:  []string{"bpp-b/pincodes", "bpp-c/pan-india"},
+		},
+		{
+			name:  "pincode range",
+			query: ServiceabilityQuery{AreaCode: "110005"},
+			want:  []string{"bpp-b/pincodes", "bpp-c/pan-india"},
+		},
+		{
+			name:  "pincode past the range",
+			query: ServiceabilityQuery{AreaCode: "110011"},
+			want:  []string{"bpp-c/pan-india"},
+		},
+		{
+			name:  "pan-india serves a pincode indexed nowhere",
+			query: ServiceabilityQuery{AreaCode: "999999", Category: "Electronics"},
+			want:  []string{"bpp-c/pan-india"},
+		},
+		{
+			name:  "category",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.98, Lng: 77.60}, Category: "grocery"},
+			want:  []string{"bpp-a/circle"},
+		},
+		{
+			name:  "category served by no area at the point",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.98, Lng: 77.60}, Category: "Fashion"},
+		},
+		{
+			name:  "wildcard category",
+			query: ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.92, Lng: 77.62}, Category: "Fashion"},
+			want:  []string{"bpp-a/polygon"},
+		},
+		{
+			name:  "domain",
+			query: ServiceabilityQuery{AreaCode: "560001", Domain: "ONDC:RET12"},
+			want:  []string{"bpp-b/pincodes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchedProviders(index.Query(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Query(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceabilityIndexNearestFirst(t *testing.T) {
+	index := testServiceabilityIndex()
+	index.Update("bpp-d", "far", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+		{Type: ondc.ServiceabilityHyperlocal, Center: &ondc.Point{Lat: 12.99, Lng: 77.61}, RadiusKm: 10},
+	})
+
+	matches := index.Query(ServiceabilityQuery{GPS: &ondc.Point{Lat: 12.97, Lng: 77.59}})
+	var order []string
+	for _, match := range matches {
+		order = append(order, match.ProviderID)
+	}
+	if want := []string{"circle", "far", "pan-india"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("matched %v, want %v", order, want)
+	}
+	if matches[0].DistanceKm == nil || *matches[0].DistanceKm != 0 {
+		t.Fatalf("distance to the nearest location = %v, want 0", matches[0].DistanceKm)
+	}
+}
+
+func TestServiceabilityIndexReindex(t *testing.T) {
+	oldPoint := ondc.Point{Lat: 12.97, Lng: 77.59}
+	newPoint := ondc.Point{Lat: 28.61, Lng: 77.21}
+
+	tests := []struct {
+		name      string
+		areas     []ondc.ServiceArea
+		wantNew   bool
+		wantStats ServiceabilityStats
+	}{
+		{
+			name:      "moved circle",
+			areas:     []ondc.ServiceArea{{Type: ondc.ServiceabilityHyperlocal, Center: &newPoint, RadiusKm: 3}},
+			wantNew:   true,
+			wantStats: ServiceabilityStats{Providers: 1, Areas: 1, Cells: 4},
+		},
+		{
+			name:      "pincodes instead",
+			areas:     []ondc.ServiceArea{{Type: ondc.ServiceabilityIntercity, AreaCodes: []string{"110001"}}},
+			wantStats: ServiceabilityStats{Providers: 1, Areas: 1, AreaCodes: 1},
+		},
+		{
+			name: "no serviceability",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewServiceabilityIndex()
+			index.Update("bpp-a", "P1", "ONDC:RET10", "std:080", []ondc.ServiceArea{
+				{Type: ondc.ServiceabilityHyperlocal, Center: &oldPoint, RadiusKm: 3},
+				{Type: ondc.ServiceabilityIntercity, AreaCodes: []string{"560001"}},
+			})
+			index.Update("bpp-a", "P1", "ONDC:RET10", "std:080", tt.areas)
+
+			if got := index.Query(ServiceabilityQuery{GPS: &oldPoint}); len(got) != 0 {
+				t.Fatalf("old circle still matches: %v", matchedProviders(got))
+			}
+			if got := index.Query(ServiceabilityQuery{AreaCode: "560001"}); len(got) != 0 {
+				t.Fatalf("old pincode still matches: %v", matchedProviders(got))
+			}
+			if got := len(index.Query(ServiceabilityQuery{GPS: &newPoint})) == 1; got != tt.wantNew {
+				t.Fatalf("new circle matches = %v, want %v", got, tt.wantNew)
+			}
+			if got := index.Stats(); got != tt.wantStats {
+				t.Fatalf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+			if got := index.Indexes("bpp-a"); got != (len(tt.areas) > 0) {
+				t.Fatalf("Indexes(bpp-a) = %v after re-indexing", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// scheduleHorizonDays bounds how far ahead the next opening is searched,
+// long enough to step over a run of holidays.
+const scheduleHorizonDays = 31
+
+// LocationAvailability is whether one provider location takes orders at
+// a given time.
+type LocationAvailability struct {
+	BppID      string `json:"bpp_id"`
+	LocationID string `json:"location_id"`
+	Open       bool   `json:"open"`
+	Enabled    bool   `json:"enabled"`
+	Holiday    bool   `json:"holiday"`
+	// ClosesAt is set while open, NextOpeningAt while closed; both are
+	// empty when the location does not close or open again within the
+	// horizon.
+	ClosesAt      *time.Time          `json:"closes_at,omitempty"`
+	NextOpeningAt *time.Time          `json:"next_opening_at,omitempty"`
+	Windows       []ondc.TimingWindow `json:"windows"`
+	Holidays      []string            `json:"holidays,omitempty"`
+	Warnings      []string            `json:"warnings,omitempty"`
+}
+
+// ProviderAvailability is whether any location of a provider takes orders
+// at a given time.
+type ProviderAvailability struct {
+	ProviderID    string                 `json:"provider_id"`
+	At            time.Time              `json:"at"`
+	Open          bool                   `json:"open"`
+	NextOpeningAt *time.Time             `json:"next_opening_at,omitempty"`
+	Locations     []LocationAvailability `json:"locations"`
+}
+
+// StoreSchedules keeps the timing tags and holidays of provider locations
+// from received catalogs and answers whether a store is open.
+type StoreSchedules struct {
+	repo     ports.ScheduleRepository
+	location *time.Location
+}
+
+// NewStoreSchedules constructs a new StoreSchedules. Catalog times are read
+// in location.
+func NewStoreSchedules(repo ports.ScheduleRepository, location *time.Location) *StoreSchedules {
+	return &StoreSchedules{repo: repo, location: location}
+}
+
+// Track saves the schedules of every provider of an on_search callback,
+// logging the inconsistencies found. Providers of an incremental catalog
+// that carry no timing keep their saved schedules.
+func (s *StoreSchedules) Track(ctx context.Context, callback *OnSearchCallback) error {
+	if callback.BppID == "" {
+		return fmt.Errorf("context.bpp_id is missing")
+	}
+
+	now := time.Now().UTC()
+	providers := 0
+	err := ondc.EachProvider(callback.Payload, func(provider *ondc.Provider) error {
+		if provider.ID == "" {
+			return nil
+		}
+		schedules := provider.Schedules()
+		if callback.CatalogMode == ports.CatalogModeIncremental && !hasTiming(schedules) {
+			return nil
+		}
+
+		entities := make([]ports.LocationSchedule, 0, len(schedules))
+		for _, schedule := range schedules {
+			for _, warning := range schedule.Warnings {
+				logger.Warnf(ctx, "Timing of location %s of provider %s of bpp_id=%s: %s", schedule.LocationID, provider.ID, callback.BppID, warning)
+			}
+			windows, err := json.Marshal(nonNil(schedule.Windows))
+			if err != nil {
+				return err
+			}
+			warnings, err := json.Marshal(nonNil(schedule.Warnings))
+			if err != nil {
+				return err
+			}
+			entities = append(entities, ports.LocationSchedule{
+				BppID:      callback.BppID,
+				ProviderID: provider.ID,
+				LocationID: schedule.LocationID,
+				Domain:     callback.Domain,
+				City:       callback.City,
+				Enabled:    schedule.Enabled,
+				Windows:    string(windows),
+				Holidays:   strings.Join(schedule.Holidays, ","),
+				Warnings:   string(warnings),
+				SourceID:   callback.ID,
+				UpdatedAt:  now,
+			})
+		}
+		if err := s.repo.SaveProvider(ctx, callback.BppID, provider.ID, entities); err != nil {
+			return err
+		}
+		providers++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof(ctx, "Saved store timing of %d providers of bpp_id=%s", providers, callback.BppID)
+	return nil
+}
+
+// Availability reports whether the provider's locations take orders at
+// at, optionally restricted to one BPP and location. A location with no
+// Order timing is open around the clock except on its holidays.
+func (s *StoreSchedules) Availability(ctx context.Context, providerID, bppID, locationID string, at time.Time) (*ProviderAvailability, error) {
+	schedules, err := s.repo.ListByProvider(ctx, providerID, bppID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProviderAvailability{ProviderID: providerID, At: at, Locations: []LocationAvailability{}}
+	for _, schedule := range schedules {
+		if locationID != "" && schedule.LocationID != locationID {
+			continue
+		}
+		location := LocationAvailability{
+			BppID:      schedule.BppID,
+			LocationID: schedule.LocationID,
+			Enabled:    schedule.Enabled,
+		}
+		if err := json.Unmarshal([]byte(schedule.Windows), &location.Windows); err != nil {
+			return nil, appError.NewCustomError(500, appError.ErrHTTPInternalServer.Code, "failed to read store timing", err.Error())
+		}
+		if err := json.Unmarshal([]byte(schedule.Warnings), &location.Warnings); err != nil {
+			return nil, appError.NewCustomError(500, appError.ErrHTTPInternalServer.Code, "failed to read store timing", err.Error())
+		}
+		if schedule.Holidays != "" {
+			location.Holidays = strings.Split(schedule.Holidays, ",")
+		}
+		s.evaluate(&location, at)
+
+		if location.Open {
+			result.Open = true
+		} else if location.NextOpeningAt != nil && (result.NextOpeningAt == nil || location.NextOpeningAt.Before(*result.NextOpeningAt)) {
+			result.NextOpeningAt = location.NextOpeningAt
+		}
+		result.Locations = append(result.Locations, location)
+	}
+	if len(result.Locations) == 0 {
+		return nil, appError.ErrScheduleNotFound
+	}
+	if result.Open {
+		result.NextOpeningAt = nil
+	}
+	return result, nil
+}
+
+type openInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// evaluate fills Open, Holiday, ClosesAt and NextOpeningAt. Windows are
+// laid out on the calendar from the day before at, so that windows past
+// midnight are seen, skipping holidays and merging adjacent intervals.
+func (s *StoreSchedules) evaluate(location *LocationAvailability, at time.Time) {
+	local := at.In(s.location)
+	holidays := make(map[string]bool, len(location.Holidays))
+	for _, holiday := range location.Holidays {
+		holidays[holiday] = true
+	}
+	location.Holiday = holidays[local.Format(ondc.HolidayLayout)]
+	if !location.Enabled {
+		return
+	}
+
+	var windows []ondc.TimingWindow
+	for _, window := range location.Windows {
+		if window.TakesOrders() {
+			windows = append(windows, window)
+		}
+	}
+	if len(windows) == 0 {
+		windows = []ondc.TimingWindow{{Type: ondc.TimingAll, DayFrom: 1, DayTo: 7, TimeFrom: "0000", TimeTo: "2400"}}
+	}
+
+	var intervals []openInterval
+	for offset := -1; offset <= scheduleHorizonDays; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, s.location)
+		if holidays[day.Format(ondc.HolidayLayout)] {
+			continue
+		}
+		weekday := int(day.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		for _, window := range windows {
+			if !window.Covers(weekday) {
+				continue
+			}
+			from, to := window.Minutes()
+			end := to
+			if to <= from {
+				end = to + 24*60
+			}
+			intervals = append(intervals, openInterval{
+				start: time.Date(day.Year(), day.Month(), day.Day(), 0, from, 0, 0, s.location),
+				end:   time.Date(day.Year(), day.Month(), day.Day(), 0, end, 0, 0, s.location),
+			})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	var merged []openInterval
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && !interval.start.After(merged[last].end) {
+			if interval.end.After(merged[last].end) {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	// Intervals reaching the end of the horizon may stay open past it
+	horizonEnd := time.Date(local.Year(), local.Month(), local.Day()+scheduleHorizonDays+1, 0, 0, 0, 0, s.location)
+	for _, interval := range merged {
+		if !local.Before(interval.start) && local.Before(interval.end) {
+			location.Open = true
+			if interval.end.Before(horizonEnd) {
+				closesAt := interval.end.UTC()
+				location.ClosesAt = &closesAt
+			}
+			return
+		}
+		if interval.start.After(local) {
+			next := interval.start.UTC()
+			location.NextOpeningAt = &next
+			return
+		}
+	}
+}
+
+// hasTiming reports whether any schedule carries timing information.
+func hasTiming(schedules []ondc.LocationSchedule) bool {
+	for _, schedule := range schedules {
+		if len(schedule.Windows) > 0 || len(schedule.Holidays) > 0 || len(schedule.Warnings) > 0 || !schedule.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
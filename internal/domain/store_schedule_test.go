@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"adapter/pkg/ondc"
+)
+
+func TestStoreSchedulesEvaluate(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	schedules := NewStoreSchedules(nil, ist)
+	// 2026-10-19 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, ist)
+	}
+	weekdays := []ondc.TimingWindow{{Type: ondc.TimingOrder, DayFrom: 1, DayTo: 5, TimeFrom: "0900", TimeTo: "2100"}}
+	overnight := []ondc.TimingWindow{{Type: ondc.TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "2200", TimeTo: "0200"}}
+	// holidaysFrom lists n consecutive holidays starting on day.
+	holidaysFrom := func(day, n int) []string {
+		var holidays []string
+		for i := 0; i < n; i++ {
+			holidays = append(holidays, time.Date(2026, 10, day+i, 0, 0, 0, 0, ist).Format(ondc.HolidayLayout))
+		}
+		return holidays
+	}
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		windows     []ondc.TimingWindow
+		holidays    []string
+		at          time.Time
+		wantOpen    bool
+		wantHoliday bool
+		// wantCloses and wantNext are zero when not expected.
+		wantCloses time.Time
+		wantNext   time.Time
+	}{
+		{name: "inside a window", windows: weekdays, at: at(19, 10, 0), wantOpen: true, wantCloses: at(19, 21, 0)},
+		{name: "after closing", windows: weekdays, at: at(19, 22, 0), wantNext: at(20, 9, 0)},
+		{name: "closing time is exclusive", windows: weekdays, at: at(19, 21, 0), wantNext: at(20, 9, 0)},
+		{name: "over the weekend", windows: weekdays, at: at(23, 22, 0), wantNext: at(26, 9, 0)},
+		{name: "overnight window before midnight", windows: overnight, at: at(19, 23, 0), wantOpen: true, wantCloses: at(20, 2, 0)},
+		{name: "overnight window after midnight", windows: overnight, at: at(20, 1, 0), wantOpen: true, wantCloses: at(20, 2, 0)},
+		{name: "overnight window closed", windows: overnight, at: at(20, 3, 0), wantNext: at(20, 22, 0)},
+		{
+			name:        "holiday",
+			windows:     weekdays,
+			holidays:    holidaysFrom(19, 1),
+			at:          at(19, 10, 0),
+			wantHoliday: true,
+			wantNext:    at(20, 9, 0),
+		},
+		{
+			// The window is skipped on the day it starts, not the day it ends
+			name:     "holiday before an overnight window ends",
+			windows:  overnight,
+			holidays: holidaysFrom(19, 1),
+			at:       at(20, 1, 0),
+			wantNext: at(20, 22, 0),
+		},
+		{name: "disabled location", disabled: true, windows: weekdays, at: at(19, 10, 0)},
+		{
+			name: "overlapping windows are merged",
+			windows: []ondc.TimingWindow{
+				{Type: ondc.TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "0900", TimeTo: "1400"},
+				{Type: ondc.TimingAll, DayFrom: 1, DayTo: 7, TimeFrom: "1300", TimeTo: "1800"},
+				{Type: ondc.TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "1800", TimeTo: "2100"},
+			},
+			at:         at(19, 10, 0),
+			wantOpen:   true,
+			wantCloses: at(19, 21, 0),
+		},
+		{
+			name:     "delivery timing does not open the store",
+			windows:  append([]ondc.TimingWindow{{Type: ondc.TimingDelivery, DayFrom: 1, DayTo: 7, TimeFrom: "0000", TimeTo: "2400"}}, weekdays...),
+			at:       at(19, 22, 0),
+			wantNext: at(20, 9, 0),
+		},
+		{name: "no order timing is always open", at: at(19, 3, 0), wantOpen: true},
+		{
+			name:     "next opening after a run of holidays",
+			windows:  weekdays,
+			holidays: holidaysFrom(19, 20),
+			at:       at(19, 10, 0),
+			// 2026-11-08 is a Sunday
+			wantHoliday: true,
+			wantNext:    time.Date(2026, 11, 9, 9, 0, 0, 0, ist),
+		},
+		{
+			name:        "no opening within the horizon",
+			windows:     weekdays,
+			holidays:    holidaysFrom(19, scheduleHorizonDays+2),
+			at:          at(19, 10, 0),
+			wantHoliday: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := LocationAvailability{Enabled: !tt.disabled, Windows: tt.windows, Holidays: tt.holidays}
+			schedules.evaluate(&location, tt.at)
+
+			if location.Open != tt.wantOpen || location.Holiday != tt.wantHoliday {
+				t.Fatalf("open=%v holiday=%v, want open=%v holiday=%v", location.Open, location.Holiday, tt.wantOpen, tt.wantHoliday)
+			}
+			checkTime(t, "ClosesAt", location.ClosesAt, tt.wantCloses)
+			checkTime(t, "NextOpeningAt", location.NextOpeningAt, tt.wantNext)
+		})
+	}
+}
+
+func checkTime(t *testing.T, name string, got *time.Time, want time.Time) {
+	t.Helper()
+	switch {
+	case want.IsZero() && got != nil:
+		t.Fatalf("%s = %s, want none", name, got)
+	case !want.IsZero() && (got == nil || !got.Equal(want)):
+		t.Fatalf("%s = %v, want %s", name, got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"crypto/ecdh"
+	"fmt"
+
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/ondc"
+)
+
+// SubscriptionService answers the registry while the edge subscribes to
+// the network: it decrypts the /on_subscribe challenge and renders the
+// site verification page carrying the signed request id.
+type SubscriptionService struct {
+	subscriberID  string
+	encryptionKey *ecdh.PrivateKey
+	registryKey   *ecdh.PublicKey
+	// verificationPage is empty when no request id is configured.
+	verificationPage string
+}
+
+// NewSubscriptionService constructs a new SubscriptionService. The
+// challenge is answered when encryptionPrivateKey is set and the
+// verification page is served when requestID is set.
+func NewSubscriptionService(subscriberID, encryptionPrivateKey, registryPublicKey, signingPrivateKey, requestID string) (*SubscriptionService, error) {
+	s := &SubscriptionService{subscriberID: subscriberID}
+	if encryptionPrivateKey != "" {
+		key, err := ondc.ParseEncryptionPrivateKey(encryptionPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		registryKey, err := ondc.ParseEncryptionPublicKey(registryPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("registry %w", err)
+		}
+		s.encryptionKey, s.registryKey = key, registryKey
+	}
+	if requestID != "" {
+		key, err := ondc.ParsePrivateKey(signingPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		s.verificationPage = ondc.SiteVerificationHTML(ondc.SignRequestID(key, requestID))
+	}
+	return s, nil
+}
+
+// ChallengeEnabled reports whether /on_subscribe can be answered.
+func (s *SubscriptionService) ChallengeEnabled() bool {
+	return s.encryptionKey != nil
+}
+
+// AnswerChallenge decrypts the registry's challenge for this subscriber.
+func (s *SubscriptionService) AnswerChallenge(ctx context.Context, request *ondc.OnSubscribeRequest) (*ondc.OnSubscribeResponse, error) {
+	if request.SubscriberID != "" && request.SubscriberID != s.subscriberID {
+		logger.Warnf(ctx, "Received on_subscribe challenge for subscriber_id=%s, expected %s", request.SubscriberID, s.subscriberID)
+		return nil, appError.ErrSubscriberMismatch
+	}
+	answer, err := ondc.DecryptChallenge(request.Challenge, s.encryptionKey, s.registryKey)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to decrypt on_subscribe challenge: %v", err)
+		return nil, appError.NewCustomError(400, appError.ErrChallengeFailed.Code, appError.ErrChallengeFailed.Message, err.Error())
+	}
+	logger.Infof(ctx, "Answered on_subscribe challenge for subscriber_id=%s", s.subscriberID)
+	return &ondc.OnSubscribeResponse{Answer: answer}, nil
+}
+
+// SiteVerificationPage returns the HTML served at
+// ondc.SiteVerificationPath, or false when no request id is configured.
+func (s *SubscriptionService) SiteVerificationPage() (string, bool) {
+	return s.verificationPage, s.verificationPage != ""
+}
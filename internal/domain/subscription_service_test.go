@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	appError "adapter/internal/shared/error"
+	"adapter/pkg/ondc"
+)
+
+// encryptionKey returns an X25519 key pair like cmd/keygen, or a fixed one
+// derived from seed when it is not zero so failures are reproducible.
+func encryptionKey(t *testing.T, seed byte) (*ecdh.PrivateKey, string, string) {
+	t.Helper()
+	var key *ecdh.PrivateKey
+	var err error
+	if seed == 0 {
+		key, err = ecdh.X25519().GenerateKey(rand.Reader)
+	} else {
+		key, err = ecdh.X25519().NewPrivateKey(bytes.Repeat([]byte{seed}, 32))
+	}
+	if err != nil {
+		t.Fatalf("failed to generate encryption key: %v", err)
+	}
+	private, err := ondc.MarshalEncryptionPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode encryption key: %v", err)
+	}
+	public, err := ondc.MarshalEncryptionPublicKey(key.PublicKey())
+	if err != nil {
+		t.Fatalf("failed to encode encryption public key: %v", err)
+	}
+	return key, private, public
+}
+
+func TestSubscriptionServiceAnswerChallenge(t *testing.T) {
+	const subscriberID = "seller.example.com"
+
+	tests := []struct {
+		name string
+		// subscriberSeed, registrySeed and wrongSeed select the keys, see
+		// encryptionKey. wrongSeed, when set, is the registry key the
+		// subscriber is configured with instead of the one that encrypted.
+		subscriberSeed, registrySeed, wrongSeed byte
+		challenge                               string
+		requestSubscriber                       string
+		// mangle alters the encrypted challenge before it is answered.
+		mangle   func(string) string
+		wantErr  *appError.CustomError
+		inDetail string
+	}{
+		{name: "round trip", challenge: "0b8c5b1b-6c3e-4b8a-9d5b-0d4b0c6e8f21", requestSubscriber: subscriberID},
+		{name: "subscriber id omitted", challenge: "challenge", requestSubscriber: ""},
+		{name: "whole block challenge", challenge: "0123456789abcdef", requestSubscriber: subscriberID},
+		{name: "another subscriber", challenge: "challenge", requestSubscriber: "other.example.com", wantErr: appError.ErrSubscriberMismatch},
+		{
+			name: "wrong registry key", subscriberSeed: 1, registrySeed: 2, wrongSeed: 3,
+			challenge: "0b8c5b1b-6c3e-4b8a-9d5b-0d4b0c6e8f21", requestSubscriber: subscriberID,
+			wantErr: appError.ErrChallengeFailed, inDetail: "invalid padding",
+		},
+		{
+			name: "not block aligned", challenge: "challenge", requestSubscriber: subscriberID,
+			mangle: func(encrypted string) string {
+				raw, _ := base64.StdEncoding.DecodeString(encrypted)
+				return base64.StdEncoding.EncodeToString(raw[:len(raw)-1])
+			},
+			wantErr: appError.ErrChallengeFailed, inDetail: "not a whole number of AES blocks",
+		},
+		{
+			name: "empty challenge", challenge: "challenge", requestSubscriber: subscriberID,
+			mangle:  func(string) string { return "" },
+			wantErr: appError.ErrChallengeFailed, inDetail: "not a whole number of AES blocks",
+		},
+		{
+			name: "not base64", challenge: "challenge", requestSubscriber: subscriberID,
+			mangle:  func(string) string { return "not base64!" },
+			wantErr: appError.ErrChallengeFailed, inDetail: "not base64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriberKey, subscriberPrivate, _ := encryptionKey(t, tt.subscriberSeed)
+			registryKey, _, registryPublic := encryptionKey(t, tt.registrySeed)
+			if tt.wrongSeed != 0 {
+				_, _, registryPublic = encryptionKey(t, tt.wrongSeed)
+			}
+
+			service, err := NewSubscriptionService(subscriberID, subscriberPrivate, registryPublic, "", "")
+			if err != nil {
+				t.Fatalf("NewSubscriptionService: %v", err)
+			}
+			if !service.ChallengeEnabled() {
+				t.Fatal("ChallengeEnabled() = false with an encryption key")
+			}
+
+			// The registry encrypts with its private key and the subscriber's
+			// public key, as cmd/stub-registry does.
+			encrypted, err := ondc.EncryptChallenge(tt.challenge, registryKey, subscriberKey.PublicKey())
+			if err != nil {
+				t.Fatalf("EncryptChallenge: %v", err)
+			}
+			if tt.mangle != nil {
+				encrypted = tt.mangle(encrypted)
+			}
+
+			response, err := service.AnswerChallenge(context.Background(), &ondc.OnSubscribeRequest{SubscriberID: tt.requestSubscriber, Challenge: encrypted})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("AnswerChallenge: %v", err)
+				}
+				if response.Answer != tt.challenge {
+					t.Fatalf("Answer = %q, want %q", response.Answer, tt.challenge)
+				}
+				return
+			}
+			var customErr *appError.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != tt.wantErr.Code {
+				t.Fatalf("err = %v, want %s", err, tt.wantErr.Code)
+			}
+			if detail, _ := customErr.Details.(string); !strings.Contains(detail, tt.inDetail) {
+				t.Fatalf("details = %v, want them to contain %q", customErr.Details, tt.inDetail)
+			}
+		})
+	}
+}
+
+func TestNewSubscriptionServiceWithoutKeys(t *testing.T) {
+	service, err := NewSubscriptionService("seller.example.com", "", "", "", "")
+	if err != nil {
+		t.Fatalf("NewSubscriptionService: %v", err)
+	}
+	if service.ChallengeEnabled() {
+		t.Fatal("ChallengeEnabled() = true without an encryption key")
+	}
+	if _, ok := service.SiteVerificationPage(); ok {
+		t.Fatal("SiteVerificationPage() served without a request id")
+	}
+
+	_, private, _ := encryptionKey(t, 1)
+	if _, err := NewSubscriptionService("seller.example.com", private, "", "", ""); err == nil {
+		t.Fatal("NewSubscriptionService accepted an encryption key without a registry public key")
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+// TransactionService answers what was received for a transaction_id.
+type TransactionService struct {
+	repo    ports.TransactionRepository
+	states  ports.TransactionStateRepository
+	storage ports.ObjectStorage
+}
+
+func NewTransactionService(repo ports.TransactionRepository, states ports.TransactionStateRepository, storage ports.ObjectStorage) *TransactionService {
+	return &TransactionService{repo: repo, states: states, storage: storage}
+}
+
+// State returns the lifecycle state of a transaction, or nil when none of
+// its actions is part of the order lifecycle yet.
+func (s *TransactionService) State(ctx context.Context, transactionID string) (*ports.TransactionState, error) {
+	state, err := s.states.Get(ctx, transactionID)
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrDatabaseQueryFailed.Code, appError.ErrDatabaseQueryFailed.Message, err.Error())
+	}
+	return state, nil
+}
+
+// Timeline returns the callbacks of a transaction in arrival order. bppID
+// optionally restricts it to one seller app.
+func (s *TransactionService) Timeline(ctx context.Context, transactionID, bppID string) ([]ports.Transaction, error) {
+	records, err := s.repo.ListByTransactionID(ctx, transactionID, bppID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, appError.ErrTransactionNotFound
+	}
+	return records, nil
+}
+
+// Payload fetches the stored payload of one callback of a transaction.
+func (s *TransactionService) Payload(ctx context.Context, transactionID, callbackID string) (*ports.Transaction, []byte, error) {
+	record, err := s.repo.GetByID(ctx, callbackID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record.TransactionID != transactionID {
+		return nil, nil, appError.ErrTransactionNotFound
+	}
+	if record.ObjectKey == "" {
+		return nil, nil, appError.ErrPayloadNotStored
+	}
+
+	payload, err := s.storage.Download(ctx, record.ObjectKey)
+	if err != nil {
+		return nil, nil, appError.NewCustomError(
+			502,
+			appError.ErrHTTPInternalServer.Code,
+			"failed to fetch stored payload",
+			fmt.Sprintf("%s: %v", record.ObjectKey, err),
+		)
+	}
+	return record, payload, nil
+}
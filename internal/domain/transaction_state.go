@@ -0,0 +1,277 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"adapter/internal/ports"
+	logger "adapter/internal/shared/log"
+	"adapter/pkg/events"
+	"adapter/pkg/ondc"
+)
+
+// Transaction lifecycle stages, in protocol order.
+const (
+	StageSearch      = "search"
+	StageSelect      = "select"
+	StageInit        = "init"
+	StageConfirm     = "confirm"
+	StageFulfillment = "fulfillment"
+)
+
+// ONDC order states carried in message.order.state.
+const (
+	OrderStateCreated    = "Created"
+	OrderStateAccepted   = "Accepted"
+	OrderStateInProgress = "In-progress"
+	OrderStateCompleted  = "Completed"
+	OrderStateCancelled  = "Cancelled"
+)
+
+var stageRank = map[string]int{
+	StageSearch:      0,
+	StageSelect:      1,
+	StageInit:        2,
+	StageConfirm:     3,
+	StageFulfillment: 4,
+}
+
+// actionRule places an action in a stage and lists the actions, one of
+// which must already have been seen for it to be in order.
+type actionRule struct {
+	stage    string
+	requires []string
+}
+
+var actionRules = map[string]actionRule{
+	"search":     {stage: StageSearch},
+	"on_search":  {stage: StageSearch},
+	"select":     {stage: StageSelect},
+	"on_select":  {stage: StageSelect},
+	"init":       {stage: StageInit, requires: []string{"on_select"}},
+	"on_init":    {stage: StageInit, requires: []string{"on_select", "init"}},
+	"confirm":    {stage: StageConfirm, requires: []string{"on_init"}},
+	"on_confirm": {stage: StageConfirm, requires: []string{"on_init"}},
+	"status":     {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"on_status":  {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"update":     {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"on_update":  {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"cancel":     {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"on_cancel":  {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"track":      {stage: StageFulfillment, requires: []string{"on_confirm"}},
+	"on_track":   {stage: StageFulfillment, requires: []string{"on_confirm"}},
+}
+
+// orderStateTransitions lists the states each order state may move to.
+// Completed and Cancelled are terminal.
+var orderStateTransitions = map[string][]string{
+	OrderStateCreated:    {OrderStateAccepted, OrderStateInProgress, OrderStateCancelled},
+	OrderStateAccepted:   {OrderStateInProgress, OrderStateCompleted, OrderStateCancelled},
+	OrderStateInProgress: {OrderStateCompleted, OrderStateCancelled},
+	OrderStateCompleted:  {},
+	OrderStateCancelled:  {},
+}
+
+// actionsAfterTerminal are still valid once an order is Completed or
+// Cancelled (status checks, and updates for returns and refunds).
+var actionsAfterTerminal = map[string][]string{
+	OrderStateCompleted: {"status", "on_status", "update", "on_update", "track", "on_track"},
+	OrderStateCancelled: {"status", "on_status", "cancel", "on_cancel"},
+}
+
+// StateTransition is the outcome of applying one action to a transaction.
+type StateTransition struct {
+	Previous ports.TransactionState
+	Current  ports.TransactionState
+	// Violation is empty when the action was in order.
+	Violation string
+}
+
+// Changed reports whether the stage or order state moved.
+func (t *StateTransition) Changed() bool {
+	return t.Previous.Stage != t.Current.Stage || t.Previous.OrderState != t.Current.OrderState
+}
+
+// TransactionStateMachine validates each action of a transaction against
+// the ONDC order lifecycle, persists the resulting state and publishes a
+// state-change event whenever it moves or a violation is flagged.
+// Violations are flagged, never rejected: the seller app's callback is
+// still the source of truth for what happened.
+type TransactionStateMachine struct {
+	repo        ports.TransactionStateRepository
+	publisher   ports.EventPublisher
+	topic       string
+	eventSource string
+}
+
+func NewTransactionStateMachine(repo ports.TransactionStateRepository, publisher ports.EventPublisher, topic, eventSource string) *TransactionStateMachine {
+	return &TransactionStateMachine{
+		repo:        repo,
+		publisher:   publisher,
+		topic:       topic,
+		eventSource: eventSource,
+	}
+}
+
+// Apply records callback against its transaction's state machine. Actions
+// outside the order lifecycle are ignored and return nil.
+func (m *TransactionStateMachine) Apply(ctx context.Context, callback *OnSearchCallback) (*StateTransition, error) {
+	if _, ok := actionRules[callback.Action]; !ok {
+		return nil, nil
+	}
+
+	// Concurrent callbacks for one transaction race on the version column
+	const maxConflicts = 3
+	for attempt := 1; ; attempt++ {
+		transition, err := m.apply(ctx, callback)
+		if errors.Is(err, ports.ErrStateConflict) && attempt < maxConflicts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if transition.Violation != "" {
+			logger.Warnf(ctx, "Out-of-order %s for transaction_id=%s: %s", callback.Action, callback.TransactionID, transition.Violation)
+		}
+		if transition.Changed() || transition.Violation != "" {
+			m.publish(ctx, callback, transition)
+		}
+		return transition, nil
+	}
+}
+
+func (m *TransactionStateMachine) apply(ctx context.Context, callback *OnSearchCallback) (*StateTransition, error) {
+	stored, err := m.repo.Get(ctx, callback.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var previous ports.TransactionState
+	if stored != nil {
+		previous = *stored
+	} else {
+		previous = ports.TransactionState{
+			TransactionID: callback.TransactionID,
+			Domain:        callback.Domain,
+			CreatedAt:     now,
+		}
+	}
+
+	current := previous
+	current.LastAction = callback.Action
+	current.Version = previous.Version + 1
+	current.UpdatedAt = now
+	if callback.BapID != "" {
+		current.BapID = callback.BapID
+	}
+	// on_search arrives from every seller app; the transaction belongs to
+	// the one it moves forward with.
+	if callback.BppID != "" && (callback.Action != "on_search" || current.BppID == "") {
+		current.BppID = callback.BppID
+	}
+
+	seen := splitActions(previous.Actions)
+	rule := actionRules[callback.Action]
+	var violations []string
+
+	if len(rule.requires) > 0 && !slices.ContainsFunc(rule.requires, func(action string) bool { return slices.Contains(seen, action) }) {
+		violations = append(violations, fmt.Sprintf("%s without prior %s", callback.Action, strings.Join(rule.requires, " or ")))
+	}
+	// Late on_search responses are normal once the buyer has moved on
+	if previous.Stage != "" && rule.stage != StageSearch && stageRank[rule.stage] < stageRank[previous.Stage] {
+		violations = append(violations, fmt.Sprintf("%s after transaction reached %s", callback.Action, previous.Stage))
+	}
+	if stageRank[rule.stage] >= stageRank[previous.Stage] {
+		current.Stage = rule.stage
+	}
+
+	if allowed, terminal := actionsAfterTerminal[previous.OrderState]; terminal && !slices.Contains(allowed, callback.Action) {
+		violations = append(violations, fmt.Sprintf("%s after order was %s", callback.Action, previous.OrderState))
+	}
+
+	orderState := ondc.OrderState(callback.Payload)
+	if orderState == "" && callback.Action == "on_cancel" {
+		orderState = OrderStateCancelled
+	}
+	if orderState != "" && orderState != previous.OrderState {
+		if violation := orderStateViolation(previous.OrderState, orderState); violation != "" {
+			violations = append(violations, violation)
+		}
+		current.OrderState = orderState
+	}
+
+	if !slices.Contains(seen, callback.Action) {
+		current.Actions = strings.Join(append(seen, callback.Action), ",")
+	}
+
+	transition := &StateTransition{Previous: previous, Current: current}
+	if len(violations) > 0 {
+		transition.Violation = strings.Join(violations, "; ")
+		transition.Current.Violations++
+	}
+
+	if err := m.repo.Save(ctx, &transition.Current); err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
+
+func orderStateViolation(from, to string) string {
+	if _, known := orderStateTransitions[to]; !known {
+		return fmt.Sprintf("unknown order state %q", to)
+	}
+	if from == "" {
+		return ""
+	}
+	if !slices.Contains(orderStateTransitions[from], to) {
+		return fmt.Sprintf("order state %s cannot move to %s", from, to)
+	}
+	return ""
+}
+
+func splitActions(actions string) []string {
+	if actions == "" {
+		return nil
+	}
+	return strings.Split(actions, ",")
+}
+
+func (m *TransactionStateMachine) publish(ctx context.Context, callback *OnSearchCallback, transition *StateTransition) {
+	if m.publisher == nil {
+		return
+	}
+
+	change := TransactionStateChange{
+		TransactionID:      callback.TransactionID,
+		MessageID:          callback.MessageID,
+		Action:             callback.Action,
+		Domain:             callback.Domain,
+		BapID:              transition.Current.BapID,
+		BppID:              transition.Current.BppID,
+		PreviousStage:      transition.Previous.Stage,
+		Stage:              transition.Current.Stage,
+		PreviousOrderState: transition.Previous.OrderState,
+		OrderState:         transition.Current.OrderState,
+		OutOfOrder:         transition.Violation != "",
+		Violation:          transition.Violation,
+		Version:            transition.Current.Version,
+	}
+	event := events.NewEnvelope(m.eventSource, TransactionStateChangedEventType, callback.TransactionID, TransactionStateChangedSchemaPath, change)
+	body, headers, err := event.Encode(
+		ports.Header{Key: "ondc_transaction_id", Value: []byte(callback.TransactionID)},
+		ports.Header{Key: "ondc_action", Value: []byte(callback.Action)},
+	)
+	if err != nil {
+		logger.Errorf(ctx, err, "Failed to encode state change event")
+		return
+	}
+	if err := m.publisher.Publish(ctx, m.topic, []byte(callback.TransactionID), body, headers...); err != nil {
+		logger.Errorf(ctx, err, "Failed to publish state change for transaction_id=%s", callback.TransactionID)
+	}
+}
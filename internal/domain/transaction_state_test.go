@@ -0,0 +1,301 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"adapter/internal/ports"
+)
+
+// memoryStateRepository is an in-memory ports.TransactionStateRepository
+// with the same optimistic versioning as the Postgres one.
+type memoryStateRepository struct {
+	mu     sync.Mutex
+	states map[string]ports.TransactionState
+	// conflicts makes the next Save calls fail with ErrStateConflict.
+	conflicts int
+}
+
+func newMemoryStateRepository() *memoryStateRepository {
+	return &memoryStateRepository{states: make(map[string]ports.TransactionState)}
+}
+
+func (r *memoryStateRepository) Get(ctx context.Context, transactionID string) (*ports.TransactionState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[transactionID]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *memoryStateRepository) Save(ctx context.Context, state *ports.TransactionState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conflicts > 0 {
+		r.conflicts--
+		return ports.ErrStateConflict
+	}
+	if stored, ok := r.states[state.TransactionID]; (ok && stored.Version != state.Version-1) || (!ok && state.Version != 1) {
+		return ports.ErrStateConflict
+	}
+	r.states[state.TransactionID] = *state
+	return nil
+}
+
+// recordingPublisher keeps every published message.
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []ports.Message
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, key, value []byte, headers ...ports.Header) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, ports.Message{Topic: topic, Key: key, Value: value, Headers: headers})
+	return nil
+}
+
+type stateStep struct {
+	action     string
+	orderState string
+	// violation is a substring of the expected violation, empty when the
+	// step must be in order.
+	violation string
+}
+
+func stateCallback(action, orderState string) *OnSearchCallback {
+	payload := `{"context":{},"message":{}}`
+	if orderState != "" {
+		payload = fmt.Sprintf(`{"context":{},"message":{"order":{"state":%q}}}`, orderState)
+	}
+	return &OnSearchCallback{
+		TransactionID: "t-1",
+		MessageID:     "m-" + action,
+		Domain:        "ONDC:RET10",
+		Action:        action,
+		BapID:         "bap",
+		BppID:         "bpp",
+		Payload:       []byte(payload),
+	}
+}
+
+func TestTransactionStateMachine(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      []stateStep
+		stage      string
+		orderState string
+		violations int
+	}{
+		{
+			name: "happy path to completion",
+			steps: []stateStep{
+				{action: "search"}, {action: "on_search"}, {action: "select"}, {action: "on_select"},
+				{action: "init"}, {action: "on_init"}, {action: "confirm"},
+				{action: "on_confirm", orderState: OrderStateCreated},
+				{action: "on_status", orderState: OrderStateAccepted},
+				{action: "on_status", orderState: OrderStateInProgress},
+				{action: "on_status", orderState: OrderStateCompleted},
+				{action: "status"},
+			},
+			stage:      StageFulfillment,
+			orderState: OrderStateCompleted,
+		},
+		{
+			name: "init without on_select",
+			steps: []stateStep{
+				{action: "search"},
+				{action: "init", violation: "init without prior on_select"},
+			},
+			stage:      StageInit,
+			violations: 1,
+		},
+		{
+			name: "confirm without on_init",
+			steps: []stateStep{
+				{action: "on_select"},
+				{action: "confirm", violation: "confirm without prior on_init"},
+			},
+			stage:      StageConfirm,
+			violations: 1,
+		},
+		{
+			name: "going back a stage",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "select", violation: "select after transaction reached init"},
+			},
+			stage:      StageInit,
+			violations: 1,
+		},
+		{
+			name: "late on_search is in order",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_search"},
+			},
+			stage: StageInit,
+		},
+		{
+			name: "illegal order state move",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_confirm", orderState: OrderStateCreated},
+				{action: "on_status", orderState: OrderStateCompleted, violation: "order state Created cannot move to Completed"},
+			},
+			stage:      StageFulfillment,
+			orderState: OrderStateCompleted,
+			violations: 1,
+		},
+		{
+			name: "unknown order state",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_confirm", orderState: "Packed", violation: `unknown order state "Packed"`},
+			},
+			stage:      StageConfirm,
+			orderState: "Packed",
+			violations: 1,
+		},
+		{
+			name: "on_cancel without state cancels",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_confirm", orderState: OrderStateAccepted},
+				{action: "on_cancel"},
+				{action: "on_status"},
+			},
+			stage:      StageFulfillment,
+			orderState: OrderStateCancelled,
+		},
+		{
+			name: "update after cancellation",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_confirm", orderState: OrderStateCreated},
+				{action: "on_cancel", orderState: OrderStateCancelled},
+				{action: "update", violation: "update after order was Cancelled"},
+			},
+			stage:      StageFulfillment,
+			orderState: OrderStateCancelled,
+			violations: 1,
+		},
+		{
+			name: "update after completion",
+			steps: []stateStep{
+				{action: "on_select"}, {action: "on_init"},
+				{action: "on_confirm", orderState: OrderStateInProgress},
+				{action: "on_status", orderState: OrderStateCompleted},
+				{action: "on_update"},
+				{action: "cancel", violation: "cancel after order was Completed"},
+			},
+			stage:      StageFulfillment,
+			orderState: OrderStateCompleted,
+			violations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryStateRepository()
+			machine := NewTransactionStateMachine(repo, nil, "state", "/test")
+			ctx := context.Background()
+
+			for i, step := range tt.steps {
+				transition, err := machine.Apply(ctx, stateCallback(step.action, step.orderState))
+				if err != nil {
+					t.Fatalf("step %d %s: %v", i, step.action, err)
+				}
+				if step.violation == "" && transition.Violation != "" {
+					t.Fatalf("step %d %s: unexpected violation %q", i, step.action, transition.Violation)
+				}
+				if !strings.Contains(transition.Violation, step.violation) || (step.violation != "" && transition.Violation == "") {
+					t.Fatalf("step %d %s: violation %q, want %q", i, step.action, transition.Violation, step.violation)
+				}
+			}
+
+			state, _ := repo.Get(ctx, "t-1")
+			if state.Stage != tt.stage || state.OrderState != tt.orderState || state.Violations != tt.violations {
+				t.Fatalf("state = stage %q, order state %q, %d violations; want %q, %q, %d",
+					state.Stage, state.OrderState, state.Violations, tt.stage, tt.orderState, tt.violations)
+			}
+			if state.Version != len(tt.steps) {
+				t.Fatalf("version = %d, want %d", state.Version, len(tt.steps))
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachineIgnoresUnknownActions(t *testing.T) {
+	repo := newMemoryStateRepository()
+	machine := NewTransactionStateMachine(repo, nil, "state", "/test")
+
+	transition, err := machine.Apply(context.Background(), stateCallback("on_rating", ""))
+	if err != nil || transition != nil {
+		t.Fatalf("Apply = %v, %v; want nil, nil", transition, err)
+	}
+	if len(repo.states) != 0 {
+		t.Fatalf("unknown action was persisted: %+v", repo.states)
+	}
+}
+
+func TestTransactionStateMachineRetriesConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		wantErr   bool
+	}{
+		{name: "no conflict", conflicts: 0},
+		{name: "retried conflicts", conflicts: 2},
+		{name: "persistent conflict", conflicts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryStateRepository()
+			repo.conflicts = tt.conflicts
+			machine := NewTransactionStateMachine(repo, nil, "state", "/test")
+
+			_, err := machine.Apply(context.Background(), stateCallback("search", ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachinePublishesChanges(t *testing.T) {
+	repo := newMemoryStateRepository()
+	publisher := &recordingPublisher{}
+	machine := NewTransactionStateMachine(repo, publisher, "state", "/test")
+	ctx := context.Background()
+
+	steps := []struct {
+		action    string
+		published bool
+	}{
+		{action: "search", published: true},
+		// Same stage, no order state: nothing to tell consumers.
+		{action: "on_search", published: false},
+		{action: "select", published: true},
+		// Out of order is published even though the stage does not move.
+		{action: "confirm", published: true},
+	}
+	for _, step := range steps {
+		before := len(publisher.messages)
+		if _, err := machine.Apply(ctx, stateCallback(step.action, "")); err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+		if got := len(publisher.messages) > before; got != step.published {
+			t.Fatalf("%s: published = %v, want %v", step.action, got, step.published)
+		}
+	}
+	last := publisher.messages[len(publisher.messages)-1]
+	if last.Topic != "state" || string(last.Key) != "t-1" || !strings.Contains(string(last.Value), `"out_of_order":true`) {
+		t.Fatalf("unexpected state change event: %s %s %s", last.Topic, last.Key, last.Value)
+	}
+}
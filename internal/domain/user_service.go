@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+)
+
+const (
+	apiKeyPrefix      = "gcr_"
+	apiKeyRandomBytes = 32
+)
+
+type cachedUser struct {
+	user      *ports.User
+	expiresAt time.Time
+}
+
+// UserService manages API clients and authenticates their keys. Lookups
+// are cached by key hash so the database is not hit on every request.
+type UserService struct {
+	repo     ports.UserRepository
+	cacheTTL time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedUser
+}
+
+// IssuedUser is returned when a key is created or rotated. APIKey is the
+// plaintext key and is only ever shown once.
+type IssuedUser struct {
+	*ports.User
+	APIKey string `json:"api_key"`
+}
+
+// NewUserService constructs a new UserService.
+func NewUserService(repo ports.UserRepository, cacheTTL time.Duration) *UserService {
+	return &UserService{
+		repo:     repo,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedUser),
+	}
+}
+
+// CreateUser registers a new client and issues its first API key.
+func (s *UserService) CreateUser(ctx context.Context, name, url string) (*IssuedUser, error) {
+	name = strings.TrimSpace(name)
+	url = strings.TrimSpace(url)
+	if name == "" || url == "" {
+		return nil, appError.ErrMissingRequiredField
+	}
+
+	key, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrFailedToCreateUser.Code, appError.ErrFailedToCreateUser.Message, err.Error())
+	}
+
+	user := &ports.User{
+		ID:       uuid.NewString(),
+		Name:     name,
+		URL:      url,
+		APIKey:   hash,
+		IsActive: true,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "Created API client %s (%s)", user.Name, user.ID)
+
+	return &IssuedUser{User: user, APIKey: key}, nil
+}
+
+// RotateKey replaces a client's key. The old key stops working immediately.
+func (s *UserService) RotateKey(ctx context.Context, id string) (*IssuedUser, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, appError.NewCustomError(500, appError.ErrFailedToUpdateUser.Code, appError.ErrFailedToUpdateUser.Message, err.Error())
+	}
+	if err := s.repo.UpdateAPIKeyHash(ctx, id, hash); err != nil {
+		return nil, err
+	}
+	s.evict(user.APIKey)
+	logger.Infof(ctx, "Rotated API key for client %s (%s)", user.Name, user.ID)
+
+	user.APIKey = hash
+	return &IssuedUser{User: user, APIKey: key}, nil
+}
+
+// DeactivateUser disables a client without deleting it.
+func (s *UserService) DeactivateUser(ctx context.Context, id string) (*ports.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetActive(ctx, id, false); err != nil {
+		return nil, err
+	}
+	s.evict(user.APIKey)
+	logger.Infof(ctx, "Deactivated API client %s (%s)", user.Name, user.ID)
+
+	user.IsActive = false
+	return user, nil
+}
+
+// ListUsers returns every registered client.
+func (s *UserService) ListUsers(ctx context.Context) ([]ports.User, error) {
+	return s.repo.List(ctx)
+}
+
+// Authenticate resolves an API key to an active client.
+func (s *UserService) Authenticate(ctx context.Context, key string) (*ports.User, error) {
+	if key == "" {
+		return nil, appError.ErrMissingAPIKey
+	}
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) != len(apiKeyPrefix)+2*apiKeyRandomBytes {
+		return nil, appError.ErrInvalidAPIKeyFormat
+	}
+
+	hash := hashAPIKey(key)
+	user, ok := s.lookupCache(hash)
+	if !ok {
+		var err error
+		user, err = s.repo.GetByAPIKeyHash(ctx, hash)
+		if err != nil {
+			if err == appError.ErrUserNotFound {
+				return nil, appError.ErrInvalidAPIKey
+			}
+			return nil, err
+		}
+		s.store(hash, user)
+	}
+
+	if !user.IsActive {
+		return nil, appError.ErrUserNotActive
+	}
+	return user, nil
+}
+
+func (s *UserService) lookupCache(hash string) (*ports.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.cache[hash]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.user, true
+}
+
+func (s *UserService) store(hash string, user *ports.User) {
+	if s.cacheTTL <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[hash] = cachedUser{user: user, expiresAt: time.Now().Add(s.cacheTTL)}
+}
+
+func (s *UserService) evict(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, hash)
+}
+
+// generateAPIKey returns a new random key and its storage hash.
+func generateAPIKey() (string, string, error) {
+	buf := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+	return key, hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	appError "adapter/internal/shared/error"
+)
+
+type AdapterHandler struct {
+	registry *domain.AdapterRegistry
+}
+
+func NewAdapterHandler(registry *domain.AdapterRegistry) *AdapterHandler {
+	return &AdapterHandler{registry: registry}
+}
+
+// CreateAdapter registers a seller adapter. A generated API key is
+// returned once.
+func (h *AdapterHandler) CreateAdapter(c *fiber.Ctx) error {
+	var input domain.AdapterInput
+	if err := c.BodyParser(&input); err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, appError.ErrInvalidRequestBody.Message, err.Error())
+	}
+
+	issued, err := h.registry.Create(c.UserContext(), input)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+// ListAdapters returns every registered adapter with its health status.
+func (h *AdapterHandler) ListAdapters(c *fiber.Ctx) error {
+	adapters, err := h.registry.List(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"adapters": adapters})
+}
+
+// GetAdapter returns one adapter.
+func (h *AdapterHandler) GetAdapter(c *fiber.Ctx) error {
+	adapter, err := h.registry.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(adapter)
+}
+
+// UpdateAdapter changes the fields present in the request body.
+func (h *AdapterHandler) UpdateAdapter(c *fiber.Ctx) error {
+	var input domain.AdapterInput
+	if err := c.BodyParser(&input); err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, appError.ErrInvalidRequestBody.Message, err.Error())
+	}
+
+	adapter, err := h.registry.Update(c.UserContext(), c.Params("id"), input)
+	if err != nil {
+		return err
+	}
+	return c.JSON(adapter)
+}
+
+// DeleteAdapter removes an adapter from the registry.
+func (h *AdapterHandler) DeleteAdapter(c *fiber.Ctx) error {
+	if err := h.registry.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetAdapterForBPP returns the adapter that owns a bpp_id's catalogs.
+func (h *AdapterHandler) GetAdapterForBPP(c *fiber.Ctx) error {
+	adapter, err := h.registry.ForBPP(c.UserContext(), c.Params("bppId"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(adapter)
+}
+
+// ListServingAdapters returns the healthy adapters serving ?domain= and
+// ?city=.
+func (h *AdapterHandler) ListServingAdapters(c *fiber.Ctx) error {
+	adapters, err := h.registry.Serving(c.UserContext(), c.Query("domain"), c.Query("city"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"adapters": adapters})
+}
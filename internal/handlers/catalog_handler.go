@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	"adapter/internal/ports"
+)
+
+// defaultVersionLimit bounds the versions listed when ?limit= is absent.
+const defaultVersionLimit = 50
+
+type CatalogHandler struct {
+	catalogs *domain.CatalogMaterializer
+	// aggregator is nil when multi-part aggregation is disabled.
+	aggregator *domain.CatalogAggregator
+}
+
+func NewCatalogHandler(catalogs *domain.CatalogMaterializer, aggregator *domain.CatalogAggregator) *CatalogHandler {
+	return &CatalogHandler{catalogs: catalogs, aggregator: aggregator}
+}
+
+// GetCatalog returns the provider's current catalog as an on_search
+// payload, with every incremental update applied to its last full catalog.
+func (h *CatalogHandler) GetCatalog(c *fiber.Ctx) error {
+	payload, err := h.catalogs.Materialize(c.UserContext(), c.Params("bppId"), c.Params("providerId"))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(payload)
+}
+
+// ListVersions returns the full and incremental catalogs received for a
+// provider, newest first.
+func (h *CatalogHandler) ListVersions(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultVersionLimit)
+	versions, err := h.catalogs.Versions(c.UserContext(), c.Params("bppId"), c.Params("providerId"), limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"bpp_id":      c.Params("bppId"),
+		"provider_id": c.Params("providerId"),
+		"versions":    versions,
+	})
+}
+
+// GetAggregates returns the multi-part on_search aggregates of a
+// transaction, one per seller app.
+func (h *CatalogHandler) GetAggregates(c *fiber.Ctx) error {
+	if h.aggregator == nil {
+		return c.JSON(fiber.Map{"aggregation": "disabled", "aggregates": []ports.CatalogAggregate{}})
+	}
+	aggregates, err := h.aggregator.Aggregates(c.UserContext(), c.Params("transactionId"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"transaction_id": c.Params("transactionId"), "aggregates": aggregates})
+}
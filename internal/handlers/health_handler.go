@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/shared/health"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Livez reports whether the process is alive. It does not probe
+// dependencies.
+func (h *HealthHandler) Livez(c *fiber.Ctx) error {
+	return c.JSON(h.registry.Live())
+}
+
+// Readyz probes every registered dependency and returns 503 if any of them
+// is down or the service is shutting down.
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	report := h.registry.Ready(c.UserContext())
+	status := fiber.StatusOK
+	if !report.Healthy() {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
@@ -1,24 +1,33 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fastjson"
 
 	"adapter/internal/domain"
+	"adapter/internal/shared/ack"
 	appError "adapter/internal/shared/error"
 	logger "adapter/internal/shared/log"
 )
 
 type OnSearchHandler struct {
 	service *domain.OnSearchService
+	// queue is set in async ingestion mode.
+	queue             *domain.IngestionQueue
+	retryAfterSeconds int
 }
 
-func NewOnSearchHandler(service *domain.OnSearchService) *OnSearchHandler {
+func NewOnSearchHandler(service *domain.OnSearchService, queue *domain.IngestionQueue, retryAfterSeconds int) *OnSearchHandler {
 	fmt.Printf("[DEBUG] NewOnSearchHandler created with service: %p\n", service)
-	return &OnSearchHandler{service: service}
+	return &OnSearchHandler{service: service, queue: queue, retryAfterSeconds: retryAfterSeconds}
 }
 
 // HandleOnSearch is the HTTP adapter for the /on-search endpoint.
@@ -61,7 +70,13 @@ func (h *OnSearchHandler) HandleOnSearch(c *fiber.Ctx) error {
 
 	logger.Infof(ctx, "Processing on-search payload, size: %d bytes", len(payload))
 
-	err := h.service.HandleOnSearch(ctx, payload)
+	if h.queue != nil {
+		return h.enqueue(c, ctx, payload)
+	}
+
+	// The request body buffer is reused by fasthttp once the handler
+	// returns, while the callback dispatch outlives it.
+	err := h.service.HandleOnSearch(ctx, bytes.Clone(payload))
 	if err != nil {
 		logger.Errorf(ctx, err, "Failed to handle on-search request")
 		return err
@@ -73,3 +88,59 @@ func (h *OnSearchHandler) HandleOnSearch(c *fiber.Ctx) error {
 		"status": "accepted",
 	})
 }
+
+// HandleCallback serves the order lifecycle callbacks (on_select,
+// on_init, ...) through the same pipeline as on_search, after checking
+// that context.action matches the route.
+func (h *OnSearchHandler) HandleCallback(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if got := fastjson.GetString(c.Body(), "context", "action"); got != action {
+			return c.Status(fiber.StatusBadRequest).JSON(ack.NewNack(
+				ack.ErrorTypeContext,
+				appError.ErrInvalidFieldFormat.Code,
+				fmt.Sprintf("context.action %q does not match /%s", got, strings.ReplaceAll(action, "_", "-")),
+			))
+		}
+		return h.HandleOnSearch(c)
+	}
+}
+
+// enqueue validates the context, hands the payload to the ingestion queue
+// and ACKs without waiting for storage or publishing.
+func (h *OnSearchHandler) enqueue(c *fiber.Ctx, ctx context.Context, payload []byte) error {
+	// The request body buffer is reused by fasthttp once the handler returns.
+	callback, err := domain.ParseOnSearchCallback(ctx, bytes.Clone(payload))
+	if err != nil {
+		return err
+	}
+	// Timing is judged against the receive time, not when a worker
+	// picks the callback up.
+	if err := h.service.CheckTiming(ctx, callback); err != nil {
+		return err
+	}
+
+	if err := h.queue.Enqueue(ctx, callback); err != nil {
+		if errors.Is(err, appError.ErrIngestionQueueFull) || errors.Is(err, appError.ErrIngestionQueueClosed) {
+			var customErr *appError.CustomError
+			errors.As(err, &customErr)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(h.retryAfterSeconds))
+			return c.Status(fiber.StatusServiceUnavailable).JSON(ack.NewNack(
+				ack.ErrorTypeInternal,
+				customErr.Code,
+				fmt.Sprintf("%s, retry after %d seconds", customErr.Message, h.retryAfterSeconds),
+			))
+		}
+		return err
+	}
+
+	logger.Infof(ctx, "Queued on-search payload for transaction_id=%s", callback.TransactionID)
+	return c.Status(fiber.StatusAccepted).JSON(ack.NewAck())
+}
+
+// QueueStats reports the async ingestion queue depth.
+func (h *OnSearchHandler) QueueStats(c *fiber.Ctx) error {
+	if h.queue == nil {
+		return c.JSON(fiber.Map{"mode": "sync"})
+	}
+	return c.JSON(fiber.Map{"mode": "async", "queue": h.queue.Stats()})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/adapters/messaging"
+	"adapter/internal/adapters/validation"
+	"adapter/internal/domain"
+	"adapter/internal/ports"
+	"adapter/pkg/events"
+)
+
+const testOnSearchTopic = "ondc.on_search.pointer"
+
+// memoryStorage is an in-process ports.ObjectStorage.
+type memoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memoryStorage) Upload(ctx context.Context, objectName string, data []byte, contentType string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[objectName] = bytes.Clone(data)
+	return objectName, nil
+}
+
+func (s *memoryStorage) Download(ctx context.Context, objectName string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[objectName], nil
+}
+
+func (s *memoryStorage) GetBucket() string {
+	return "ondc-test"
+}
+
+func loadTestPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile("../../test_payloads.json")
+	if err != nil {
+		t.Fatalf("failed to read test payloads: %v", err)
+	}
+	var payloads map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &payloads); err != nil {
+		t.Fatalf("failed to parse test payloads: %v", err)
+	}
+	payload, ok := payloads[name]
+	if !ok {
+		t.Fatalf("test payload %s not found", name)
+	}
+	return payload
+}
+
+func TestOnSearchPublishesPointerOnMemoryBus(t *testing.T) {
+	validator, err := validation.NewJSONSchemaValidator()
+	if err != nil {
+		t.Fatalf("NewJSONSchemaValidator: %v", err)
+	}
+	storage := &memoryStorage{objects: make(map[string][]byte)}
+	bus := messaging.NewMemoryBus(4)
+	defer bus.Close(context.Background())
+	subscription := bus.Subscribe(testOnSearchTopic)
+
+	service, err := domain.NewOnSearchService(validator, storage, bus, nil, nil, nil, nil, nil, nil, nil, nil, nil, testOnSearchTopic, "/adapter/test")
+	if err != nil {
+		t.Fatalf("NewOnSearchService: %v", err)
+	}
+	app := fiber.New()
+	app.Post("/on-search", NewOnSearchHandler(service, nil, 5).HandleOnSearch)
+
+	payload := loadTestPayload(t, "ret11_on_search")
+	req := httptest.NewRequest(http.MethodPost, "/on-search", bytes.NewReader(payload))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST /on-search: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusAccepted)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, err := subscription.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("no pointer published: %v", err)
+	}
+	if string(msg.Key) != "T1" {
+		t.Errorf("key = %q, want T1", msg.Key)
+	}
+
+	var envelope struct {
+		SpecVersion string                 `json:"specversion"`
+		Type        string                 `json:"type"`
+		Subject     string                 `json:"subject"`
+		Source      string                 `json:"source"`
+		DataSchema  string                 `json:"dataschema"`
+		Data        events.OnSearchPointer `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		t.Fatalf("pointer is not JSON: %v", err)
+	}
+	if envelope.SpecVersion != events.CloudEventsSpecVersion || envelope.Type != events.OnSearchPointerType ||
+		envelope.Subject != "T1" || envelope.Source != "/adapter/test" || envelope.DataSchema != events.OnSearchPointerSchemaPath {
+		t.Errorf("unexpected envelope attributes: %+v", envelope)
+	}
+
+	pointer := envelope.Data
+	stored, ok := storage.objects[pointer.ObjectKey]
+	if !ok {
+		t.Fatalf("pointer references %s, which was not uploaded", pointer.ObjectKey)
+	}
+	checksum := sha256.Sum256(stored)
+	if !bytes.Equal(stored, payload) || pointer.SHA256 != hex.EncodeToString(checksum[:]) || pointer.SizeBytes != int64(len(payload)) {
+		t.Errorf("pointer does not describe the uploaded payload: %+v", pointer)
+	}
+	if pointer.Bucket != "ondc-test" || pointer.Domain != "ONDC:RET11" || pointer.Action != "on_search" ||
+		pointer.MessageID != "M1" || pointer.BapID != "bnp.com" || pointer.BppID != "snp.com" || pointer.City != "std:080" {
+		t.Errorf("unexpected pointer: %+v", pointer)
+	}
+
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	for key, want := range map[string]string{
+		"content-type":        "application/cloudevents+json; charset=UTF-8",
+		"ce_specversion":      events.CloudEventsSpecVersion,
+		"ce_type":             events.OnSearchPointerType,
+		"ce_source":           "/adapter/test",
+		"ce_subject":          "T1",
+		"ondc_domain":         "ONDC:RET11",
+		"ondc_action":         "on_search",
+		"ondc_transaction_id": "T1",
+		"ondc_message_id":     "M1",
+		"ondc_bap_id":         "bnp.com",
+		"ondc_bpp_id":         "snp.com",
+		"ondc_city":           "std:080",
+		"ondc_late":           "false",
+	} {
+		if got := headers[key]; got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
+	if headers["ce_id"] == "" || headers["ce_time"] == "" {
+		t.Errorf("ce_id and ce_time headers must be set: %v", headers)
+	}
+}
+
+var _ ports.ObjectStorage = (*memoryStorage)(nil)
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	appError "adapter/internal/shared/error"
+)
+
+type ProviderHandler struct {
+	// schedules is nil when store timing is disabled.
+	schedules *domain.StoreSchedules
+}
+
+func NewProviderHandler(schedules *domain.StoreSchedules) *ProviderHandler {
+	return &ProviderHandler{schedules: schedules}
+}
+
+// GetAvailability returns whether the provider's stores take orders at
+// ?at= (RFC 3339, now by default) and when they next open. ?bpp_id= and
+// ?location_id= narrow the locations considered.
+func (h *ProviderHandler) GetAvailability(c *fiber.Ctx) error {
+	if h.schedules == nil {
+		return appError.NewCustomError(503, appError.ErrHTTPServiceUnavailable.Code, "Store timing is disabled")
+	}
+
+	at := time.Now().UTC()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, appError.ErrInvalidFieldFormat.Message, "at must be an RFC 3339 timestamp")
+		}
+		at = parsed
+	}
+
+	availability, err := h.schedules.Availability(c.UserContext(), strings.Clone(c.Params("id")), c.Query("bpp_id"), c.Query("location_id"), at)
+	if err != nil {
+		return err
+	}
+	return c.JSON(availability)
+}
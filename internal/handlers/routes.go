@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 
+	"adapter/internal/config"
 	"adapter/internal/config/di"
+	"adapter/internal/middleware"
+	"adapter/pkg/ondc"
 )
 
 // RegisterRoutes wires all HTTP routes to their handlers.
@@ -21,13 +25,109 @@ func RegisterRoutes(app *fiber.App, container *di.Container) {
 	}
 	fmt.Printf("[DEBUG] OnSearchService is initialized, registering /on-search route\n")
 
+	healthHandler := NewHealthHandler(container.Health)
+	app.Get("/livez", healthHandler.Livez)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/schemas/events/:name", GetEventSchema)
+
 	// Test endpoint to verify routing works
 	app.Get("/test-on-search", func(c *fiber.Ctx) error {
 		fmt.Printf("[DEBUG] Test endpoint called\n")
 		return c.JSON(fiber.Map{"status": "ok", "message": "Routing works"})
 	})
 
-	onSearchHandler := NewOnSearchHandler(container.OnSearchService)
-	app.Post("/on-search", onSearchHandler.HandleOnSearch)
-	fmt.Printf("[DEBUG] Route /on-search registered successfully\n")
+	onSearchHandler := NewOnSearchHandler(container.OnSearchService, container.IngestionQueue, container.Config.IngestRetryAfterSeconds)
+	onSearchMiddleware := protocolMiddleware(container, "/on-search")
+	if container.RateLimiter != nil {
+		onSearchMiddleware = append(onSearchMiddleware, middleware.RateLimit(container.RateLimiter))
+	}
+	app.Post("/on-search", append(onSearchMiddleware, onSearchHandler.HandleOnSearch)...)
+
+	// Order lifecycle callbacks feed the transaction state machine
+	for _, action := range []string{"on_select", "on_init", "on_confirm", "on_status", "on_update", "on_cancel"} {
+		path := "/" + strings.ReplaceAll(action, "_", "-")
+		callbackMiddleware := protocolMiddleware(container, path)
+		if container.RateLimiter != nil {
+			callbackMiddleware = append(callbackMiddleware, middleware.RateLimit(container.RateLimiter))
+		}
+		app.Post(path, append(callbackMiddleware, onSearchHandler.HandleCallback(action))...)
+	}
+
+	searchHandler := NewSearchHandler(container.SearchService)
+	searchMiddleware := protocolMiddleware(container, "/search")
+	if container.RateLimiter != nil {
+		searchMiddleware = append(searchMiddleware, middleware.RateLimit(container.RateLimiter))
+	}
+	app.Post("/search", append(searchMiddleware, searchHandler.HandleSearch)...)
+
+	// Registry onboarding
+	if container.Subscription != nil {
+		subscribeHandler := NewSubscribeHandler(container.Subscription)
+		if container.Subscription.ChallengeEnabled() {
+			app.Post("/on_subscribe", subscribeHandler.OnSubscribe)
+		}
+		if _, ok := container.Subscription.SiteVerificationPage(); ok {
+			app.Get(ondc.SiteVerificationPath, subscribeHandler.SiteVerification)
+		}
+	}
+
+	userHandler := NewUserHandler(container.UserService)
+
+	// Internal endpoints require a client API key
+	internal := app.Group("/internal", middleware.APIKeyAuth(container.UserService))
+	internal.Get("/whoami", userHandler.WhoAmI)
+	internal.Get("/ingestion/queue", onSearchHandler.QueueStats)
+
+	adapterHandler := NewAdapterHandler(container.Adapters)
+	internal.Get("/adapters", adapterHandler.ListServingAdapters)
+	internal.Get("/adapters/bpp/:bppId", adapterHandler.GetAdapterForBPP)
+
+	transactionHandler := NewTransactionHandler(container.Transactions, container.Dispatcher)
+	transactions := app.Group("/transactions", middleware.APIKeyAuth(container.UserService))
+	transactions.Get("/:id", transactionHandler.GetTransaction)
+	transactions.Get("/:id/payloads/:callbackId", transactionHandler.GetPayload)
+	internal.Get("/deliveries/:messageId", transactionHandler.GetDeliveries)
+
+	catalogHandler := NewCatalogHandler(container.Catalogs, container.Aggregator)
+	internal.Get("/catalogs/:bppId/providers/:providerId", catalogHandler.GetCatalog)
+	internal.Get("/catalogs/:bppId/providers/:providerId/versions", catalogHandler.ListVersions)
+	internal.Get("/aggregates/:transactionId", catalogHandler.GetAggregates)
+
+	serviceabilityHandler := NewServiceabilityHandler(container.Serviceability)
+	internal.Get("/serviceability", serviceabilityHandler.Query)
+	internal.Get("/serviceability/stats", serviceabilityHandler.Stats)
+
+	providerHandler := NewProviderHandler(container.Schedules)
+	providers := app.Group("/providers", middleware.APIKeyAuth(container.UserService))
+	providers.Get("/:id/availability", providerHandler.GetAvailability)
+
+	if container.Config.AdminAPIKey == "" {
+		fmt.Printf("[DEBUG] ADMIN_API_KEY not set, admin routes disabled\n")
+		return
+	}
+	admin := app.Group("/admin", middleware.AdminKeyAuth(container.Config.AdminAPIKey))
+	admin.Post("/users", userHandler.CreateUser)
+	admin.Get("/users", userHandler.ListUsers)
+	admin.Post("/users/:id/rotate-key", userHandler.RotateKey)
+	admin.Post("/users/:id/deactivate", userHandler.DeactivateUser)
+	admin.Post("/adapters", adapterHandler.CreateAdapter)
+	admin.Get("/adapters", adapterHandler.ListAdapters)
+	admin.Get("/adapters/:id", adapterHandler.GetAdapter)
+	admin.Patch("/adapters/:id", adapterHandler.UpdateAdapter)
+	admin.Delete("/adapters/:id", adapterHandler.DeleteAdapter)
+}
+
+// protocolMiddleware returns the decoding and size checks shared by every
+// ONDC protocol endpoint.
+func protocolMiddleware(container *di.Container, path string) []fiber.Handler {
+	cfg := container.Config
+	// Validated in config.LoadConfig
+	domainLimits, _ := config.ParseSizeMap(cfg.BodyLimitDomains)
+	return []fiber.Handler{
+		middleware.Decompress(middleware.DecompressConfig{
+			MaxBytes: cfg.DecompressMaxBytes,
+			MaxRatio: cfg.DecompressMaxRatio,
+		}),
+		middleware.BodyLimit(cfg.RouteBodyLimit(path), domainLimits),
+	}
 }
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/adapters/validation"
+	appError "adapter/internal/shared/error"
+)
+
+// GetEventSchema serves the JSON Schema of a published event type so
+// consumers can validate what they read from Kafka.
+func GetEventSchema(c *fiber.Ctx) error {
+	eventType := strings.TrimSuffix(c.Params("name"), ".json")
+	schema, ok := validation.EventSchemas[eventType]
+	if !ok {
+		return appError.ErrHTTPNotFound
+	}
+	c.Set(fiber.HeaderContentType, "application/schema+json")
+	return c.Send(schema)
+}
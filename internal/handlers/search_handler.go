@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"bytes"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	"adapter/internal/shared/ack"
+	logger "adapter/internal/shared/log"
+)
+
+type SearchHandler struct {
+	service *domain.SearchService
+}
+
+func NewSearchHandler(service *domain.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// HandleSearch is the HTTP adapter for the /search endpoint. It ACKs once
+// the request is validated; seller adapters are called in the background.
+func (h *SearchHandler) HandleSearch(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	// The request body buffer is reused by fasthttp once the handler returns.
+	if err := h.service.HandleSearch(ctx, bytes.Clone(c.Body())); err != nil {
+		logger.Errorf(ctx, err, "Rejected search request")
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(ack.NewAck())
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	appError "adapter/internal/shared/error"
+	"adapter/pkg/ondc"
+)
+
+type ServiceabilityHandler struct {
+	// index is nil when the serviceability index is disabled.
+	index *domain.ServiceabilityIndex
+}
+
+func NewServiceabilityHandler(index *domain.ServiceabilityIndex) *ServiceabilityHandler {
+	return &ServiceabilityHandler{index: index}
+}
+
+// Query returns the provider locations serving ?gps=lat,lng or
+// ?area_code=, optionally filtered by ?category= and ?domain=.
+func (h *ServiceabilityHandler) Query(c *fiber.Ctx) error {
+	if h.index == nil {
+		return c.JSON(fiber.Map{"serviceability": "disabled", "matches": []domain.ServiceabilityMatch{}})
+	}
+
+	query := domain.ServiceabilityQuery{
+		AreaCode: c.Query("area_code"),
+		Category: c.Query("category"),
+		Domain:   c.Query("domain"),
+	}
+	if gps := c.Query("gps"); gps != "" {
+		point, err := ondc.ParseGPS(gps)
+		if err != nil {
+			return appError.NewCustomError(400, appError.ErrInvalidFieldFormat.Code, appError.ErrInvalidFieldFormat.Message, err.Error())
+		}
+		query.GPS = &point
+	}
+	if query.GPS == nil && query.AreaCode == "" {
+		return appError.NewCustomError(400, appError.ErrMissingRequiredField.Code, appError.ErrMissingRequiredField.Message, "gps or area_code is required")
+	}
+
+	matches := h.index.Query(query)
+	if matches == nil {
+		matches = []domain.ServiceabilityMatch{}
+	}
+	return c.JSON(fiber.Map{"count": len(matches), "matches": matches})
+}
+
+// Stats returns the size of the serviceability index.
+func (h *ServiceabilityHandler) Stats(c *fiber.Ctx) error {
+	if h.index == nil {
+		return c.JSON(fiber.Map{"serviceability": "disabled"})
+	}
+	return c.JSON(h.index.Stats())
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	appError "adapter/internal/shared/error"
+	"adapter/pkg/ondc"
+)
+
+type SubscribeHandler struct {
+	service *domain.SubscriptionService
+}
+
+func NewSubscribeHandler(service *domain.SubscriptionService) *SubscribeHandler {
+	return &SubscribeHandler{service: service}
+}
+
+// OnSubscribe answers the registry's challenge with its decrypted value.
+func (h *SubscribeHandler) OnSubscribe(c *fiber.Ctx) error {
+	var request ondc.OnSubscribeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, appError.ErrInvalidRequestBody.Message, err.Error())
+	}
+	if request.Challenge == "" {
+		return appError.NewCustomError(400, appError.ErrMissingRequiredField.Code, appError.ErrMissingRequiredField.Message, "challenge is required")
+	}
+
+	response, err := h.service.AnswerChallenge(c.UserContext(), &request)
+	if err != nil {
+		return err
+	}
+	return c.JSON(response)
+}
+
+// SiteVerification serves the page carrying the signed request id.
+func (h *SubscribeHandler) SiteVerification(c *fiber.Ctx) error {
+	page, ok := h.service.SiteVerificationPage()
+	if !ok {
+		return appError.ErrHTTPNotFound
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(page)
+}
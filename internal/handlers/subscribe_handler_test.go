@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"adapter/internal/domain"
+	appError "adapter/internal/shared/error"
+	"adapter/pkg/ondc"
+)
+
+var siteVerificationContent = regexp.MustCompile(`name='ondc-site-verification' content='([^']+)'`)
+
+// subscriberKeys are the keys the edge is configured with.
+type subscriberKeys struct {
+	encryption        *ecdh.PrivateKey
+	encryptionPrivate string
+	signingPrivate    string
+	signingPublic     string
+}
+
+func newSubscriberKeys(t *testing.T) subscriberKeys {
+	t.Helper()
+	encryption, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate encryption key: %v", err)
+	}
+	encryptionPrivate, err := ondc.MarshalEncryptionPrivateKey(encryption)
+	if err != nil {
+		t.Fatalf("failed to encode encryption key: %v", err)
+	}
+	signingPublic, signing, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	return subscriberKeys{
+		encryption:        encryption,
+		encryptionPrivate: encryptionPrivate,
+		signingPrivate:    base64.StdEncoding.EncodeToString(signing.Seed()),
+		signingPublic:     base64.StdEncoding.EncodeToString(signingPublic),
+	}
+}
+
+// newSubscribeServer serves the onboarding routes like RegisterRoutes.
+func newSubscribeServer(t *testing.T, service *domain.SubscriptionService) *httptest.Server {
+	t.Helper()
+	app := fiber.New(fiber.Config{ErrorHandler: appError.ErrorHandler()})
+	handler := NewSubscribeHandler(service)
+	app.Post("/on_subscribe", handler.OnSubscribe)
+	app.Get(ondc.SiteVerificationPath, handler.SiteVerification)
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSubscribeRoundTrip(t *testing.T) {
+	const (
+		subscriberID = "seller.example.com"
+		requestID    = "5a1c3f0e-7a4b-4d7e-9c21-8f0e6b2d4a10"
+		challenge    = "0b8c5b1b-6c3e-4b8a-9d5b-0d4b0c6e8f21"
+	)
+	registry, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate registry key: %v", err)
+	}
+	registryPublic, err := ondc.MarshalEncryptionPublicKey(registry.PublicKey())
+	if err != nil {
+		t.Fatalf("failed to encode registry key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		// registered replaces the keys the registry holds for the
+		// subscriber, as when the edge runs with keys it never registered.
+		registered      func(keys subscriberKeys) subscriberKeys
+		requestID       string
+		subscriber      string
+		wantStatus      int
+		wantVerifyError bool
+	}{
+		{name: "round trip", requestID: requestID, subscriber: subscriberID, wantStatus: http.StatusOK},
+		{
+			name: "unregistered encryption key",
+			registered: func(keys subscriberKeys) subscriberKeys {
+				keys.encryption = newSubscriberKeys(t).encryption
+				return keys
+			},
+			requestID:  requestID,
+			subscriber: subscriberID,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "other subscriber", requestID: requestID, subscriber: "other.example.com", wantStatus: http.StatusBadRequest},
+		{
+			name: "unregistered signing key",
+			registered: func(keys subscriberKeys) subscriberKeys {
+				keys.signingPublic = newSubscriberKeys(t).signingPublic
+				return keys
+			},
+			requestID:       requestID,
+			subscriber:      subscriberID,
+			wantStatus:      http.StatusOK,
+			wantVerifyError: true,
+		},
+		{name: "other request id", requestID: "other-request", subscriber: subscriberID, wantStatus: http.StatusOK, wantVerifyError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := newSubscriberKeys(t)
+			service, err := domain.NewSubscriptionService(subscriberID, keys.encryptionPrivate, registryPublic, keys.signingPrivate, requestID)
+			if err != nil {
+				t.Fatalf("NewSubscriptionService: %v", err)
+			}
+			server := newSubscribeServer(t, service)
+			registered := keys
+			if tt.registered != nil {
+				registered = tt.registered(keys)
+			}
+
+			// The registry encrypts the challenge for the registered key
+			encrypted, err := ondc.EncryptChallenge(challenge, registry, registered.encryption.PublicKey())
+			if err != nil {
+				t.Fatalf("EncryptChallenge: %v", err)
+			}
+			body, _ := json.Marshal(ondc.OnSubscribeRequest{SubscriberID: tt.subscriber, Challenge: encrypted})
+			resp, err := http.Post(server.URL+"/on_subscribe", fiber.MIMEApplicationJSON, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("POST /on_subscribe: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("/on_subscribe status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var answer ondc.OnSubscribeResponse
+			if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+				t.Fatalf("failed to decode answer: %v", err)
+			}
+			if answer.Answer != challenge {
+				t.Fatalf("answer = %q, want %q", answer.Answer, challenge)
+			}
+
+			// The registry then checks the signed request id
+			page, err := http.Get(server.URL + ondc.SiteVerificationPath)
+			if err != nil {
+				t.Fatalf("GET %s: %v", ondc.SiteVerificationPath, err)
+			}
+			defer page.Body.Close()
+			html, _ := io.ReadAll(page.Body)
+			if page.StatusCode != http.StatusOK {
+				t.Fatalf("%s status = %d", ondc.SiteVerificationPath, page.StatusCode)
+			}
+			match := siteVerificationContent.FindSubmatch(html)
+			if match == nil {
+				t.Fatalf("no ondc-site-verification meta tag in %s", html)
+			}
+			err = ondc.VerifyRequestID(registered.signingPublic, tt.requestID, string(match[1]))
+			if gotErr := err != nil; gotErr != tt.wantVerifyError {
+				t.Fatalf("VerifyRequestID error = %v, want error %v", err, tt.wantVerifyError)
+			}
+		})
+	}
+}
+
+func TestSiteVerificationWithoutRequestID(t *testing.T) {
+	keys := newSubscriberKeys(t)
+	service, err := domain.NewSubscriptionService("seller.example.com", "", "", keys.signingPrivate, "")
+	if err != nil {
+		t.Fatalf("NewSubscriptionService: %v", err)
+	}
+	resp, err := http.Get(newSubscribeServer(t, service).URL + ondc.SiteVerificationPath)
+	if err != nil {
+		t.Fatalf("GET %s: %v", ondc.SiteVerificationPath, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("%s status = %d, want 404", ondc.SiteVerificationPath, resp.StatusCode)
+	}
+}
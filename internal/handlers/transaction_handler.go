@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	"adapter/internal/ports"
+)
+
+type TransactionHandler struct {
+	service *domain.TransactionService
+	// dispatcher is nil when callback dispatch is disabled.
+	dispatcher *domain.CallbackDispatcher
+}
+
+func NewTransactionHandler(service *domain.TransactionService, dispatcher *domain.CallbackDispatcher) *TransactionHandler {
+	return &TransactionHandler{service: service, dispatcher: dispatcher}
+}
+
+type timelineEntry struct {
+	ports.Transaction
+	// PayloadURL is set once the payload has been stored.
+	PayloadURL string `json:"payload_url,omitempty"`
+}
+
+// GetTransaction returns the callbacks received for a transaction in
+// arrival order, optionally filtered by ?bpp_id=.
+func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	records, err := h.service.Timeline(c.UserContext(), transactionID, c.Query("bpp_id"))
+	if err != nil {
+		return err
+	}
+
+	timeline := make([]timelineEntry, 0, len(records))
+	for _, record := range records {
+		entry := timelineEntry{Transaction: record}
+		if record.ObjectKey != "" {
+			entry.PayloadURL = fmt.Sprintf("/transactions/%s/payloads/%s", transactionID, record.ID)
+		}
+		timeline = append(timeline, entry)
+	}
+	state, err := h.service.State(c.UserContext(), transactionID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"transaction_id": transactionID,
+		"state":          state,
+		"callbacks":      timeline,
+	})
+}
+
+// GetPayload returns the stored payload of one callback.
+func (h *TransactionHandler) GetPayload(c *fiber.Ctx) error {
+	_, payload, err := h.service.Payload(c.UserContext(), c.Params("id"), c.Params("callbackId"))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(payload)
+}
+
+// GetDeliveries returns the buyer app delivery status of every callback
+// received for a message_id.
+func (h *TransactionHandler) GetDeliveries(c *fiber.Ctx) error {
+	if h.dispatcher == nil {
+		return c.JSON(fiber.Map{"dispatch": "disabled", "deliveries": []ports.CallbackDelivery{}})
+	}
+	deliveries, err := h.dispatcher.Deliveries(c.UserContext(), c.Params("messageId"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"message_id": c.Params("messageId"), "deliveries": deliveries})
+}
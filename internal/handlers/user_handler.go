@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	"adapter/internal/middleware"
+	appError "adapter/internal/shared/error"
+)
+
+type UserHandler struct {
+	service *domain.UserService
+}
+
+func NewUserHandler(service *domain.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+type createUserRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// CreateUser registers a client and returns its API key once.
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	var req createUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return appError.NewCustomError(400, appError.ErrInvalidRequestBody.Code, appError.ErrInvalidRequestBody.Message, err.Error())
+	}
+
+	issued, err := h.service.CreateUser(c.UserContext(), req.Name, req.URL)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+// ListUsers returns every registered client without their keys.
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	users, err := h.service.ListUsers(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"users": users})
+}
+
+// RotateKey issues a new key for a client and invalidates the old one.
+func (h *UserHandler) RotateKey(c *fiber.Ctx) error {
+	issued, err := h.service.RotateKey(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(issued)
+}
+
+// DeactivateUser disables a client's key.
+func (h *UserHandler) DeactivateUser(c *fiber.Ctx) error {
+	user, err := h.service.DeactivateUser(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(user)
+}
+
+// WhoAmI returns the client authenticated by the request's API key.
+func (h *UserHandler) WhoAmI(c *fiber.Ctx) error {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		return appError.ErrHTTPUnauthorized
+	}
+	return c.JSON(user)
+}
@@ -1 +1,65 @@
 package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/ports"
+	appError "adapter/internal/shared/error"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	// UserLocalKey is the fiber.Ctx local holding the authenticated *ports.User.
+	UserLocalKey = "user"
+	// SubscriberLocalKey is the fiber.Ctx local holding the subscriber_id
+	// whose ONDC signature on the request was verified.
+	SubscriberLocalKey = "subscriber_id"
+)
+
+// Authenticator resolves an API key to an active client.
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (*ports.User, error)
+}
+
+// APIKeyAuth rejects requests without a valid, active API key and stores the
+// authenticated user in the request locals.
+func APIKeyAuth(auth Authenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := auth.Authenticate(c.UserContext(), c.Get(APIKeyHeader))
+		if err != nil {
+			return err
+		}
+		c.Locals(UserLocalKey, user)
+		return c.Next()
+	}
+}
+
+// AdminKeyAuth protects administrative endpoints with a single shared key.
+func AdminKeyAuth(adminKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(APIKeyHeader)
+		if key == "" {
+			return appError.ErrMissingAPIKey
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			return appError.ErrInvalidAPIKey
+		}
+		return c.Next()
+	}
+}
+
+// CurrentUser returns the user stored by APIKeyAuth, if any.
+func CurrentUser(c *fiber.Ctx) *ports.User {
+	user, _ := c.Locals(UserLocalKey).(*ports.User)
+	return user
+}
+
+// VerifiedSubscriber returns the subscriber_id stored by signature
+// verification, or "" when the request was not verified.
+func VerifiedSubscriber(c *fiber.Ctx) string {
+	subscriberID, _ := c.Locals(SubscriberLocalKey).(string)
+	return subscriberID
+}
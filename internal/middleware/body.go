@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fastjson"
+
+	appError "adapter/internal/shared/error"
+	logger "adapter/internal/shared/log"
+)
+
+// DecompressConfig bounds how far a compressed body may expand.
+type DecompressConfig struct {
+	// MaxBytes is the largest decoded body accepted.
+	MaxBytes int
+	// MaxRatio is the largest decoded/encoded size ratio accepted.
+	MaxRatio int
+}
+
+// Decompress decodes gzip, deflate and br request bodies in place, guarding
+// against decompression bombs. The Content-Encoding header is removed so
+// downstream handlers see the plain body.
+func Decompress(cfg DecompressConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		encoding := strings.ToLower(strings.TrimSpace(c.Get(fiber.HeaderContentEncoding)))
+		if encoding == "" || encoding == "identity" {
+			return c.Next()
+		}
+
+		compressed := c.Request().Body()
+		limit := cfg.MaxBytes
+		ratioLimited := false
+		if cfg.MaxRatio > 0 && len(compressed)*cfg.MaxRatio < limit {
+			limit = len(compressed) * cfg.MaxRatio
+			ratioLimited = true
+		}
+
+		decoded, err := decode(encoding, compressed, limit)
+		if err != nil {
+			return err
+		}
+		if len(decoded) > limit {
+			if ratioLimited {
+				return appError.NewCustomError(413, appError.ErrCompressionRatio.Code, appError.ErrCompressionRatio.Message,
+					fmt.Sprintf("body expands beyond %dx its compressed size of %d bytes", cfg.MaxRatio, len(compressed)))
+			}
+			return appError.NewCustomError(413, appError.ErrPayloadTooLarge.Code, appError.ErrPayloadTooLarge.Message,
+				fmt.Sprintf("decompressed body exceeds %d bytes", cfg.MaxBytes))
+		}
+
+		logger.Debugf(c.UserContext(), "Decompressed %s body from %d to %d bytes", encoding, len(compressed), len(decoded))
+		c.Request().Header.Del(fiber.HeaderContentEncoding)
+		c.Request().SetBodyRaw(decoded)
+		return c.Next()
+	}
+}
+
+// decode reads at most limit+1 bytes so callers can detect overflow without
+// materialising the whole expansion.
+func decode(encoding string, body []byte, limit int) ([]byte, error) {
+	var reader io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, decompressionError(encoding, err)
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		// HTTP "deflate" is zlib-wrapped, but some clients send raw DEFLATE.
+		if zr, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			defer zr.Close()
+			reader = zr
+		} else {
+			fr := flate.NewReader(bytes.NewReader(body))
+			defer fr.Close()
+			reader = fr
+		}
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		return nil, appError.NewCustomError(415, appError.ErrUnsupportedEncoding.Code, appError.ErrUnsupportedEncoding.Message,
+			fmt.Sprintf("content encoding %q is not supported, use gzip, deflate or br", encoding))
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, decompressionError(encoding, err)
+	}
+	return decoded, nil
+}
+
+func decompressionError(encoding string, err error) error {
+	return appError.NewCustomError(400, appError.ErrDecompressionFailed.Code, appError.ErrDecompressionFailed.Message,
+		fmt.Sprintf("%s: %v", encoding, err))
+}
+
+// BodyLimit rejects decoded bodies larger than routeLimit, or than the
+// limit configured for the payload's context.domain when one is set.
+func BodyLimit(routeLimit int, domainLimits map[string]int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Request().Body()
+
+		limit := routeLimit
+		scope := "route " + c.Route().Path
+		if len(domainLimits) > 0 {
+			if domain := fastjson.GetString(body, "context", "domain"); domain != "" {
+				if domainLimit, ok := domainLimits[domain]; ok {
+					limit = domainLimit
+					scope = "domain " + domain
+				}
+			}
+		}
+
+		if len(body) > limit {
+			logger.Warnf(c.UserContext(), "Rejected %d byte body, limit for %s is %d bytes", len(body), scope, limit)
+			return appError.NewCustomError(413, appError.ErrPayloadTooLarge.Code, appError.ErrPayloadTooLarge.Message,
+				fmt.Sprintf("body is %d bytes, limit for %s is %d bytes", len(body), scope, limit))
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"adapter/internal/domain"
+	"adapter/internal/shared/ack"
+	logger "adapter/internal/shared/log"
+)
+
+const (
+	CodeRateLimitRequests = "RATE_LIMIT_REQUESTS"
+	CodeRateLimitBytes    = "RATE_LIMIT_BYTES"
+)
+
+// RateLimit throttles protocol callbacks per caller. Requests are keyed on
+// the authenticated client, then on the subscriber whose signature was
+// verified, and finally on the remote IP. The body's bpp_id and bap_id are
+// never used since anyone can put any id there. Throttled requests receive
+// an ONDC NACK with a Retry-After hint.
+func RateLimit(limiter *domain.RateLimiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		body := c.Body()
+		subscriberID := rateLimitKey(c)
+
+		decision := limiter.Allow(ctx, subscriberID, len(body))
+		if decision.Allowed {
+			return c.Next()
+		}
+
+		retryAfter := int(math.Max(1, math.Ceil(decision.RetryAfter.Seconds())))
+		logger.Warnf(ctx, "Rate limit (%s) exceeded for %s, retry after %ds", decision.Reason, subscriberID, retryAfter)
+
+		code := CodeRateLimitRequests
+		if decision.Reason == domain.RateLimitReasonBytes {
+			code = CodeRateLimitBytes
+		}
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(ack.NewNack(
+			ack.ErrorTypePolicy,
+			code,
+			fmt.Sprintf("%s rate limit exceeded for %s, retry after %d seconds", decision.Reason, subscriberID, retryAfter),
+		))
+	}
+}
+
+func rateLimitKey(c *fiber.Ctx) string {
+	if user := CurrentUser(c); user != nil {
+		return user.Name
+	}
+	if subscriberID := VerifiedSubscriber(c); subscriberID != "" {
+		return subscriberID
+	}
+	return c.IP()
+}
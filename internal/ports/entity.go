@@ -1 +1,334 @@
 package ports
+
+import (
+	"strings"
+	"time"
+)
+
+// User is an API client allowed to call internal endpoints. APIKey holds the
+// SHA-256 hash of the issued key; the plaintext key is never stored.
+type User struct {
+	ID        string    `gorm:"column:id;primaryKey" json:"id"`
+	Name      string    `gorm:"column:name" json:"name"`
+	URL       string    `gorm:"column:url" json:"url"`
+	APIKey    string    `gorm:"column:api_key" json:"-"`
+	IsActive  bool      `gorm:"column:is_active" json:"is_active"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+// RateLimitPolicy bounds how much traffic a single subscriber (BPP) may send.
+// A non-positive limit disables that dimension.
+type RateLimitPolicy struct {
+	SubscriberID      string    `gorm:"column:subscriber_id;primaryKey" json:"subscriber_id"`
+	RequestsPerSecond float64   `gorm:"column:requests_per_second" json:"requests_per_second"`
+	Burst             int       `gorm:"column:burst" json:"burst"`
+	BytesPerMinute    int64     `gorm:"column:bytes_per_minute" json:"bytes_per_minute"`
+	CreatedAt         time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (RateLimitPolicy) TableName() string {
+	return "rate_limit_policies"
+}
+
+// Transaction statuses, in the order a callback normally moves through them.
+const (
+	TransactionStatusReceived  = "received"
+	TransactionStatusRejected  = "rejected"
+	TransactionStatusStored    = "stored"
+	TransactionStatusPublished = "published"
+	TransactionStatusFailed    = "failed"
+	// TransactionStatusForwarded marks a search sent on to seller adapters.
+	TransactionStatusForwarded = "forwarded"
+	// TransactionStatusServed marks a search answered from the local
+	// catalog index instead of being forwarded.
+	TransactionStatusServed = "served"
+)
+
+// Transaction records one ONDC callback received for a transaction_id and
+// where its payload was stored. OutOfOrder is set when the action violated
+// the transaction's state machine; Violation explains why. Late is set
+// when the callback arrived after the TTL of the request it answers, and
+// TimingViolation lists any timestamp or TTL problems found. CatalogMode
+// tells full from incremental (catalog_inc) searches and on_search
+// responses.
+type Transaction struct {
+	ID               string     `gorm:"column:id;primaryKey" json:"id"`
+	TransactionID    string     `gorm:"column:transaction_id" json:"transaction_id"`
+	MessageID        string     `gorm:"column:message_id" json:"message_id"`
+	Domain           string     `gorm:"column:domain" json:"domain"`
+	Action           string     `gorm:"column:action" json:"action"`
+	BapID            string     `gorm:"column:bap_id" json:"bap_id"`
+	BppID            string     `gorm:"column:bpp_id" json:"bpp_id"`
+	Bucket           string     `gorm:"column:bucket" json:"bucket,omitempty"`
+	ObjectKey        string     `gorm:"column:object_key" json:"object_key,omitempty"`
+	SizeBytes        int64      `gorm:"column:size_bytes" json:"size_bytes"`
+	Status           string     `gorm:"column:status" json:"status"`
+	Error            string     `gorm:"column:error" json:"error,omitempty"`
+	OutOfOrder       bool       `gorm:"column:out_of_order" json:"out_of_order"`
+	Violation        string     `gorm:"column:violation" json:"violation,omitempty"`
+	ContextTimestamp *time.Time `gorm:"column:context_timestamp" json:"context_timestamp,omitempty"`
+	TTL              string     `gorm:"column:ttl" json:"ttl,omitempty"`
+	Late             bool       `gorm:"column:late" json:"late"`
+	TimingViolation  string     `gorm:"column:timing_violation" json:"timing_violation,omitempty"`
+	CatalogMode      string     `gorm:"column:catalog_mode" json:"catalog_mode,omitempty"`
+	ReceivedAt       time.Time  `gorm:"column:received_at" json:"received_at"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+// TransactionState is the order lifecycle position of a transaction.
+// Actions holds every action seen so far, comma-separated. Version is used
+// for optimistic locking.
+type TransactionState struct {
+	TransactionID string    `gorm:"column:transaction_id;primaryKey" json:"transaction_id"`
+	Domain        string    `gorm:"column:domain" json:"domain"`
+	BapID         string    `gorm:"column:bap_id" json:"bap_id"`
+	BppID         string    `gorm:"column:bpp_id" json:"bpp_id"`
+	Stage         string    `gorm:"column:stage" json:"stage"`
+	LastAction    string    `gorm:"column:last_action" json:"last_action"`
+	Actions       string    `gorm:"column:actions" json:"actions"`
+	OrderState    string    `gorm:"column:order_state" json:"order_state,omitempty"`
+	Violations    int       `gorm:"column:violations" json:"violations"`
+	Version       int       `gorm:"column:version" json:"version"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (TransactionState) TableName() string {
+	return "transaction_states"
+}
+
+// Adapter health states.
+const (
+	AdapterHealthUnknown = "unknown"
+	AdapterHealthUp      = "up"
+	AdapterHealthDown    = "down"
+)
+
+// Adapter is a backend seller system behind the edge. BppID is the
+// subscriber whose catalogs it owns. Domains, Cities, Categories and
+// AreaCodes (pincodes) are comma-separated and an empty list means every
+// value. Categories and AreaCodes only route searches until the adapter's
+// catalogs are in the serviceability index. APIKey is sent to the adapter
+// when calling it and is never returned by the API.
+type Adapter struct {
+	ID              string     `gorm:"column:id;primaryKey" json:"id"`
+	Name            string     `gorm:"column:name" json:"name"`
+	URL             string     `gorm:"column:url" json:"url"`
+	CallbackURL     string     `gorm:"column:callback_url" json:"callback_url"`
+	APIKey          string     `gorm:"column:api_key" json:"-"`
+	BppID           string     `gorm:"column:bpp_id" json:"bpp_id"`
+	Domains         string     `gorm:"column:domains" json:"domains"`
+	Cities          string     `gorm:"column:cities" json:"cities"`
+	Categories      string     `gorm:"column:categories" json:"categories"`
+	AreaCodes       string     `gorm:"column:area_codes" json:"area_codes"`
+	IsActive        bool       `gorm:"column:is_active" json:"is_active"`
+	HealthStatus    string     `gorm:"column:health_status" json:"health_status"`
+	HealthError     string     `gorm:"column:health_error" json:"health_error,omitempty"`
+	HealthCheckedAt *time.Time `gorm:"column:health_checked_at" json:"health_checked_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Adapter) TableName() string {
+	return "adapters"
+}
+
+// Serves reports whether the adapter handles domain and city. An empty
+// domain or city matches any adapter.
+func (a Adapter) Serves(domain, city string) bool {
+	return listContains(a.Domains, domain) && listContains(a.Cities, city)
+}
+
+// Matches reports whether the adapter's providers carry category and
+// deliver to areaCode. An empty category or area code matches any adapter.
+func (a Adapter) Matches(category, areaCode string) bool {
+	return listContains(a.Categories, category) && listContains(a.AreaCodes, areaCode)
+}
+
+func listContains(list, value string) bool {
+	if list == "" || value == "" {
+		return true
+	}
+	for _, entry := range strings.Split(list, ",") {
+		if entry == value || entry == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Callback delivery states.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusRetrying  = "retrying"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// CallbackDelivery tracks forwarding one received callback to the buyer
+// app at BapURI. ID is the callback's id in the transactions table;
+// DispatchKey identifies the callback's content so a redelivered callback
+// is not forwarded twice.
+type CallbackDelivery struct {
+	ID             string     `gorm:"column:id;primaryKey" json:"id"`
+	DispatchKey    string     `gorm:"column:dispatch_key" json:"-"`
+	TransactionID  string     `gorm:"column:transaction_id" json:"transaction_id"`
+	MessageID      string     `gorm:"column:message_id" json:"message_id"`
+	Action         string     `gorm:"column:action" json:"action"`
+	BapID          string     `gorm:"column:bap_id" json:"bap_id"`
+	BapURI         string     `gorm:"column:bap_uri" json:"bap_uri"`
+	BppID          string     `gorm:"column:bpp_id" json:"bpp_id"`
+	Status         string     `gorm:"column:status" json:"status"`
+	Attempts       int        `gorm:"column:attempts" json:"attempts"`
+	LastStatusCode int        `gorm:"column:last_status_code" json:"last_status_code,omitempty"`
+	LastError      string     `gorm:"column:last_error" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (CallbackDelivery) TableName() string {
+	return "callback_deliveries"
+}
+
+// CatalogProvider is one seller provider indexed from a received
+// on_search catalog. Provider holds the provider JSON without its items;
+// BppDescriptor and BppFulfillments hold the catalog-level blocks it was
+// received with, so on_search responses can be rebuilt from the index.
+type CatalogProvider struct {
+	BppID           string    `gorm:"column:bpp_id;primaryKey" json:"bpp_id"`
+	ProviderID      string    `gorm:"column:provider_id;primaryKey" json:"provider_id"`
+	Domain          string    `gorm:"column:domain" json:"domain"`
+	City            string    `gorm:"column:city" json:"city"`
+	BppURI          string    `gorm:"column:bpp_uri" json:"bpp_uri"`
+	CoreVersion     string    `gorm:"column:core_version" json:"core_version"`
+	Name            string    `gorm:"column:name" json:"name"`
+	BppDescriptor   string    `gorm:"column:bpp_descriptor" json:"-"`
+	BppFulfillments string    `gorm:"column:bpp_fulfillments" json:"-"`
+	Provider        string    `gorm:"column:provider" json:"-"`
+	ItemCount       int       `gorm:"column:item_count" json:"item_count"`
+	SourceID        string    `gorm:"column:source_id" json:"source_id"`
+	IndexedAt       time.Time `gorm:"column:indexed_at" json:"indexed_at"`
+}
+
+func (CatalogProvider) TableName() string {
+	return "catalog_providers"
+}
+
+// CatalogItem is one indexed item of a CatalogProvider. CategoryIDs is
+// comma-separated and Item holds the raw item JSON.
+type CatalogItem struct {
+	BppID       string    `gorm:"column:bpp_id;primaryKey" json:"bpp_id"`
+	ProviderID  string    `gorm:"column:provider_id;primaryKey" json:"provider_id"`
+	ItemID      string    `gorm:"column:item_id;primaryKey" json:"item_id"`
+	Name        string    `gorm:"column:name" json:"name"`
+	CategoryIDs string    `gorm:"column:category_ids" json:"category_ids"`
+	Item        string    `gorm:"column:item" json:"-"`
+	IndexedAt   time.Time `gorm:"column:indexed_at" json:"indexed_at"`
+}
+
+func (CatalogItem) TableName() string {
+	return "catalog_items"
+}
+
+// Catalog modes of searches and on_search responses.
+const (
+	CatalogModeFull        = "full"
+	CatalogModeIncremental = "incremental"
+)
+
+// CatalogVersion records one provider's catalog as received in an
+// on_search stored at ObjectKey. Incremental versions are deltas on top of
+// BaseVersionID, the provider's latest full version when they arrived.
+// When a seller splits a full catalog across several on_search parts, the
+// later parts are full versions based on the first one.
+// Checksum is the SHA-256 of the on_search payload; a provider's version is
+// recorded once per message_id and checksum so a redelivered callback does
+// not add the same delta twice.
+type CatalogVersion struct {
+	ID            string    `gorm:"column:id;primaryKey" json:"id"`
+	BppID         string    `gorm:"column:bpp_id" json:"bpp_id"`
+	ProviderID    string    `gorm:"column:provider_id" json:"provider_id"`
+	Domain        string    `gorm:"column:domain" json:"domain"`
+	City          string    `gorm:"column:city" json:"city"`
+	Mode          string    `gorm:"column:mode" json:"mode"`
+	BaseVersionID string    `gorm:"column:base_version_id" json:"base_version_id,omitempty"`
+	CallbackID    string    `gorm:"column:callback_id" json:"callback_id"`
+	TransactionID string    `gorm:"column:transaction_id" json:"transaction_id"`
+	MessageID     string    `gorm:"column:message_id" json:"message_id"`
+	Bucket        string    `gorm:"column:bucket" json:"bucket"`
+	ObjectKey     string    `gorm:"column:object_key" json:"object_key"`
+	Checksum      string    `gorm:"column:checksum" json:"-"`
+	ReceivedAt    time.Time `gorm:"column:received_at" json:"received_at"`
+}
+
+func (CatalogVersion) TableName() string {
+	return "catalog_versions"
+}
+
+// Catalog aggregate states. An aggregate is claimed (closing) by one
+// instance when its window expires.
+const (
+	AggregateStatusOpen     = "open"
+	AggregateStatusClosing  = "closing"
+	AggregateStatusComplete = "complete"
+	AggregateStatusFailed   = "failed"
+)
+
+// CatalogAggregate collects the on_search parts a seller sends for one
+// search (transaction_id, message_id, bpp_id) until ClosesAt, when they are
+// merged into one catalog stored at ObjectKey. LateParts counts parts that
+// arrived after the window closed.
+type CatalogAggregate struct {
+	TransactionID string     `gorm:"column:transaction_id;primaryKey" json:"transaction_id"`
+	MessageID     string     `gorm:"column:message_id;primaryKey" json:"message_id"`
+	BppID         string     `gorm:"column:bpp_id;primaryKey" json:"bpp_id"`
+	Domain        string     `gorm:"column:domain" json:"domain"`
+	City          string     `gorm:"column:city" json:"city"`
+	BapID         string     `gorm:"column:bap_id" json:"bap_id"`
+	CoreVersion   string     `gorm:"column:core_version" json:"core_version"`
+	Status        string     `gorm:"column:status" json:"status"`
+	Parts         int        `gorm:"column:parts" json:"parts"`
+	LateParts     int        `gorm:"column:late_parts" json:"late_parts"`
+	FirstPartAt   time.Time  `gorm:"column:first_part_at" json:"first_part_at"`
+	ClosesAt      time.Time  `gorm:"column:closes_at" json:"closes_at"`
+	Bucket        string     `gorm:"column:bucket" json:"bucket,omitempty"`
+	ObjectKey     string     `gorm:"column:object_key" json:"object_key,omitempty"`
+	Error         string     `gorm:"column:error" json:"error,omitempty"`
+	CompletedAt   *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (CatalogAggregate) TableName() string {
+	return "catalog_aggregates"
+}
+
+// LocationSchedule is the normalized timing of one provider location.
+// Windows and Warnings hold JSON arrays and Holidays is comma-separated.
+type LocationSchedule struct {
+	BppID      string    `gorm:"column:bpp_id;primaryKey" json:"bpp_id"`
+	ProviderID string    `gorm:"column:provider_id;primaryKey" json:"provider_id"`
+	LocationID string    `gorm:"column:location_id;primaryKey" json:"location_id"`
+	Domain     string    `gorm:"column:domain" json:"domain"`
+	City       string    `gorm:"column:city" json:"city"`
+	Enabled    bool      `gorm:"column:enabled" json:"enabled"`
+	Windows    string    `gorm:"column:windows" json:"-"`
+	Holidays   string    `gorm:"column:holidays" json:"-"`
+	Warnings   string    `gorm:"column:warnings" json:"-"`
+	SourceID   string    `gorm:"column:source_id" json:"source_id"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (LocationSchedule) TableName() string {
+	return "location_schedules"
+}
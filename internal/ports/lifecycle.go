@@ -0,0 +1,15 @@
+package ports
+
+import "context"
+
+// HealthChecker is implemented by adapters that can report whether the
+// dependency behind them is reachable.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Closer is implemented by adapters that hold resources which must be
+// flushed or released during graceful shutdown.
+type Closer interface {
+	Close(ctx context.Context) error
+}
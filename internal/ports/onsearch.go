@@ -1,6 +1,15 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"adapter/pkg/events"
+)
+
+// ErrSchemaNotFound is returned by SchemaValidator.Validate when no schema
+// is registered for the domain and action.
+var ErrSchemaNotFound = errors.New("no schema found")
 
 // SchemaValidator defines a port for validating request payloads
 // against a schema (e.g. ONDC JSON Schema).
@@ -14,11 +23,11 @@ type SchemaValidator interface {
 // and returning the object key (path) where it was stored.
 type ObjectStorage interface {
 	Upload(ctx context.Context, objectName string, data []byte, contentType string) (string, error)
+	// Download returns the stored object as written, without decoding it.
+	Download(ctx context.Context, objectName string) ([]byte, error)
 	GetBucket() string
 }
 
 // EventPublisher defines a port for sending events/messages
 // (e.g. Kafka).
-type EventPublisher interface {
-	Publish(ctx context.Context, topic string, key, value []byte) error
-}
+type EventPublisher = events.Publisher
@@ -1 +1,144 @@
 package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// UserRepository defines a port for persisting API clients.
+type UserRepository interface {
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id string) (*User, error)
+	// GetByAPIKeyHash looks up a user by the SHA-256 hash of their API key.
+	GetByAPIKeyHash(ctx context.Context, hash string) (*User, error)
+	List(ctx context.Context) ([]User, error)
+	UpdateAPIKeyHash(ctx context.Context, id, hash string) error
+	SetActive(ctx context.Context, id string, active bool) error
+}
+
+// RateLimitPolicyStore defines a port for looking up per-subscriber rate
+// limits. GetPolicy returns nil and no error when the subscriber has no
+// override and the defaults apply.
+type RateLimitPolicyStore interface {
+	GetPolicy(ctx context.Context, subscriberID string) (*RateLimitPolicy, error)
+}
+
+// TransactionRepository defines a port for recording received callbacks.
+type TransactionRepository interface {
+	// Save inserts the record or updates it when the id already exists.
+	Save(ctx context.Context, record *Transaction) error
+	GetByID(ctx context.Context, id string) (*Transaction, error)
+	// ListByTransactionID returns the callbacks of a transaction ordered by
+	// arrival, optionally restricted to one seller app.
+	ListByTransactionID(ctx context.Context, transactionID, bppID string) ([]Transaction, error)
+	// FindRequest returns the earliest recorded request with the given
+	// action (e.g. "search") for a transaction and message, or nil when none
+	// was recorded.
+	FindRequest(ctx context.Context, transactionID, messageID, action string) (*Transaction, error)
+}
+
+// ErrStateConflict is returned by TransactionStateRepository.Save when the
+// state was changed concurrently; the caller reloads and retries.
+var ErrStateConflict = errors.New("transaction state was modified concurrently")
+
+// TransactionStateRepository defines a port for persisting transaction
+// state machines.
+type TransactionStateRepository interface {
+	// Get returns nil and no error for an unknown transaction.
+	Get(ctx context.Context, transactionID string) (*TransactionState, error)
+	// Save inserts the state when Version is 1, otherwise updates it only
+	// if the stored version is Version-1.
+	Save(ctx context.Context, state *TransactionState) error
+}
+
+// AdapterRepository defines a port for the seller adapter registry.
+type AdapterRepository interface {
+	Create(ctx context.Context, adapter *Adapter) error
+	GetByID(ctx context.Context, id string) (*Adapter, error)
+	GetByBppID(ctx context.Context, bppID string) (*Adapter, error)
+	List(ctx context.Context) ([]Adapter, error)
+	// Update saves every editable field of adapter.
+	Update(ctx context.Context, adapter *Adapter) error
+	Delete(ctx context.Context, id string) error
+	UpdateHealth(ctx context.Context, id, status, healthError string, checkedAt time.Time) error
+}
+
+// DeliveryRepository defines a port for persisting callback deliveries.
+type DeliveryRepository interface {
+	// Save inserts the delivery or updates it when the id already exists.
+	Save(ctx context.Context, delivery *CallbackDelivery) error
+	// Claim inserts the delivery unless one with the same id or
+	// DispatchKey exists, reporting whether the caller should deliver it.
+	// An existing delivery with the same DispatchKey is taken over, and
+	// delivery.ID set to its id, when it failed or was last updated before
+	// staleBefore while pending or retrying.
+	Claim(ctx context.Context, delivery *CallbackDelivery, staleBefore time.Time) (bool, error)
+	// ListByMessageID returns the deliveries of a message ordered by
+	// creation.
+	ListByMessageID(ctx context.Context, messageID string) ([]CallbackDelivery, error)
+}
+
+// CatalogItemQuery selects indexed items. Empty fields match everything;
+// Text is matched full-text against item names.
+type CatalogItemQuery struct {
+	BppID       string
+	ProviderIDs []string
+	Text        string
+	CategoryID  string
+}
+
+// CatalogRepository defines a port for the local catalog index.
+type CatalogRepository interface {
+	// SaveProvider upserts provider and its items. With replace set the
+	// provider's other items are removed, otherwise they are kept.
+	SaveProvider(ctx context.Context, provider *CatalogProvider, items []CatalogItem, replace bool) error
+	// ListProviders returns the providers of bppID indexed for domain and
+	// city, optionally restricted to one provider.
+	ListProviders(ctx context.Context, bppID, domain, city, providerID string) ([]CatalogProvider, error)
+	SearchItems(ctx context.Context, query CatalogItemQuery) ([]CatalogItem, error)
+	// ScanProviders calls fn with every indexed provider, in batches.
+	ScanProviders(ctx context.Context, fn func([]CatalogProvider) error) error
+}
+
+// CatalogVersionRepository defines a port for the received catalog
+// versions of each provider.
+type CatalogVersionRepository interface {
+	// Create records the version unless one with the same bpp_id,
+	// provider_id, message_id and checksum already exists.
+	Create(ctx context.Context, version *CatalogVersion) error
+	// LatestFull returns the first part of the provider's most recent full
+	// catalog, or nil when none was received.
+	LatestFull(ctx context.Context, bppID, providerID string) (*CatalogVersion, error)
+	// ListBasedOn returns the versions based on baseVersionID in arrival
+	// order: the later parts of a full catalog and the deltas applied to
+	// it. With an empty baseVersionID only deltas without a full catalog
+	// are returned.
+	ListBasedOn(ctx context.Context, bppID, providerID, baseVersionID string) ([]CatalogVersion, error)
+	// ListByProvider returns the provider's versions, newest first.
+	ListByProvider(ctx context.Context, bppID, providerID string, limit int) ([]CatalogVersion, error)
+}
+
+// AggregateRepository defines a port for multi-part on_search aggregates.
+type AggregateRepository interface {
+	// AddPart inserts an open aggregate with one part, or counts another
+	// part of an existing one; its window is set by the first part.
+	AddPart(ctx context.Context, aggregate *CatalogAggregate) error
+	// ClaimDue marks up to limit open aggregates whose window closed before
+	// now as closing and returns them. Aggregates left closing since before
+	// staleClaim (e.g. by a crashed instance) are claimed again.
+	ClaimDue(ctx context.Context, now, staleClaim time.Time, limit int) ([]CatalogAggregate, error)
+	// Finish saves the outcome of closing an aggregate.
+	Finish(ctx context.Context, aggregate *CatalogAggregate) error
+	ListByTransactionID(ctx context.Context, transactionID string) ([]CatalogAggregate, error)
+}
+
+// ScheduleRepository defines a port for the store timing of provider
+// locations.
+type ScheduleRepository interface {
+	// SaveProvider replaces the schedules of every location of a provider.
+	SaveProvider(ctx context.Context, bppID, providerID string, schedules []LocationSchedule) error
+	// ListByProvider returns the schedules of providerID, of every BPP
+	// when bppID is empty.
+	ListByProvider(ctx context.Context, providerID, bppID string) ([]LocationSchedule, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import "adapter/pkg/events"
+
+// The bus message types are part of the consumer contract in pkg/events
+// and are aliased here for the edge's own adapters.
+type (
+	// Header is a message header (e.g. a Kafka record header).
+	Header = events.Header
+	// Message is an event received from a subscription.
+	Message = events.Message
+	// EventSubscriber defines a port for consuming events with explicit
+	// commits (e.g. a Kafka consumer group).
+	EventSubscriber = events.Subscriber
+)
+
+// ErrSubscriptionClosed is returned by EventSubscriber.Fetch once the
+// subscription has been closed and no more messages will arrive.
+var ErrSubscriptionClosed = events.ErrSubscriptionClosed
@@ -0,0 +1,53 @@
+package ack
+
+// ONDC error types as defined by the protocol's Error schema.
+const (
+	ErrorTypeContext    = "CONTEXT-ERROR"
+	ErrorTypeCore       = "CORE-ERROR"
+	ErrorTypeInternal   = "INTERNAL-ERROR"
+	ErrorTypePolicy     = "POLICY-ERROR"
+	ErrorTypeJSONSchema = "JSON-SCHEMA-ERROR"
+)
+
+const (
+	StatusAck  = "ACK"
+	StatusNack = "NACK"
+)
+
+type Status struct {
+	Status string `json:"status"`
+}
+
+type Message struct {
+	Ack Status `json:"ack"`
+}
+
+type Error struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Response is the synchronous ONDC acknowledgement body.
+type Response struct {
+	Message Message `json:"message"`
+	Error   *Error  `json:"error,omitempty"`
+}
+
+// NewAck returns a positive acknowledgement.
+func NewAck() Response {
+	return Response{Message: Message{Ack: Status{Status: StatusAck}}}
+}
+
+// NewNack returns a negative acknowledgement carrying an ONDC error.
+func NewNack(errorType, code, message string) Response {
+	return Response{
+		Message: Message{Ack: Status{Status: StatusNack}},
+		Error: &Error{
+			Type:    errorType,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -57,6 +58,19 @@ func GetDB() *gorm.DB {
 	return DB
 }
 
+// Ping checks that the shared connection pool can reach the database.
+func Ping(ctx context.Context) error {
+	db := GetDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql DB: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 func Close() error {
 	if DB != nil {
 		sqlDB, err := DB.DB()
@@ -64,6 +64,33 @@ var (
 	ErrInvalidRequestBody   = NewCustomError(400, "REQUEST_2001", "Invalid request body")
 	ErrMissingRequiredField = NewCustomError(400, "REQUEST_2002", "Missing required field")
 	ErrInvalidFieldFormat   = NewCustomError(400, "REQUEST_2003", "Invalid field format")
+	ErrPayloadTooLarge      = NewCustomError(413, "REQUEST_2004", "Request body exceeds the size limit")
+	ErrUnsupportedEncoding  = NewCustomError(415, "REQUEST_2005", "Unsupported content encoding")
+	ErrDecompressionFailed  = NewCustomError(400, "REQUEST_2006", "Failed to decompress request body")
+	ErrCompressionRatio     = NewCustomError(413, "REQUEST_2007", "Request body compression ratio exceeds the limit")
+
+	ErrIngestionQueueFull   = NewCustomError(503, "INGEST_2001", "Ingestion queue is full")
+	ErrIngestionQueueClosed = NewCustomError(503, "INGEST_2002", "Ingestion queue is shutting down")
+
+	ErrTransactionNotFound = NewCustomError(404, "TXN_2001", "Transaction not found")
+	ErrPayloadNotStored    = NewCustomError(404, "TXN_2002", "Payload was not stored for this callback")
+
+	ErrStaleCallback = NewCustomError(400, "TIMING_2001", "Callback is outside the allowed time window")
+
+	ErrSearchUnavailable = NewCustomError(503, "SEARCH_2001", "Search is shutting down")
+
+	ErrAdapterNotFound       = NewCustomError(404, "ADAPTER_2001", "Adapter not found")
+	ErrDuplicateAdapter      = NewCustomError(409, "ADAPTER_2002", "Adapter with this name, API key or bpp_id already exists")
+	ErrFailedToSaveAdapter   = NewCustomError(500, "ADAPTER_2003", "Failed to save adapter")
+	ErrFailedToGetAdapter    = NewCustomError(500, "ADAPTER_2004", "Failed to retrieve adapter")
+	ErrFailedToDeleteAdapter = NewCustomError(500, "ADAPTER_2005", "Failed to delete adapter")
+
+	ErrCatalogNotFound = NewCustomError(404, "CATALOG_2001", "No catalog received for this provider")
+
+	ErrScheduleNotFound = NewCustomError(404, "SCHEDULE_2001", "No store timing received for this provider")
+
+	ErrSubscriberMismatch = NewCustomError(400, "SUBSCRIBE_2001", "Challenge is for another subscriber")
+	ErrChallengeFailed    = NewCustomError(400, "SUBSCRIBE_2002", "Failed to decrypt the challenge")
 
 	ErrHTTPBadRequest         = NewCustomError(400, "HTTP_400", "Bad Request")
 	ErrHTTPUnauthorized       = NewCustomError(401, "HTTP_401", "Unauthorized")
@@ -0,0 +1,156 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker probes a single dependency and returns an error when it is not
+// usable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a plain function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ComponentStatus is the result of probing one registered dependency.
+type ComponentStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report aggregates the status of every registered dependency.
+type Report struct {
+	Status       string            `json:"status"`
+	ShuttingDown bool              `json:"shutting_down"`
+	Components   []ComponentStatus `json:"components"`
+}
+
+// Healthy reports whether the service should receive traffic.
+func (r Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+type entry struct {
+	name    string
+	checker Checker
+}
+
+// Registry holds the dependency checkers used by the readiness probe and
+// tracks whether the process is shutting down.
+type Registry struct {
+	mu           sync.RWMutex
+	entries      []entry
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates an empty registry. Each checker is bounded by timeout.
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a named checker. Registering an existing name replaces it.
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].name == name {
+			r.entries[i].checker = checker
+			return
+		}
+	}
+	r.entries = append(r.entries, entry{name: name, checker: checker})
+}
+
+// MarkShuttingDown flips readiness to failing so load balancers stop
+// routing new requests while in-flight ones drain.
+func (r *Registry) MarkShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether MarkShuttingDown has been called.
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Live reports process liveness. It never touches dependencies so that a
+// flaky backend does not cause the orchestrator to restart the service.
+func (r *Registry) Live() Report {
+	return Report{
+		Status:       StatusUp,
+		ShuttingDown: r.ShuttingDown(),
+		Components:   []ComponentStatus{},
+	}
+}
+
+// Ready runs every registered checker concurrently and reports the
+// per-component status and latency.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make([]entry, len(r.entries))
+	copy(entries, r.entries)
+	r.mu.RUnlock()
+
+	components := make([]ComponentStatus, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e entry) {
+			defer wg.Done()
+			components[i] = r.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:       StatusUp,
+		ShuttingDown: r.ShuttingDown(),
+		Components:   components,
+	}
+	if report.ShuttingDown {
+		report.Status = StatusDown
+	}
+	for _, c := range components {
+		if c.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, e entry) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := e.checker.Check(ctx)
+	status := ComponentStatus{
+		Name:      e.name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
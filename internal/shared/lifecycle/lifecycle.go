@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "adapter/internal/shared/log"
+)
+
+// Phase groups closers that must run together. Phases run in ascending
+// order so that nothing is torn down while something upstream still uses it.
+type Phase int
+
+const (
+	// PhaseHTTP stops accepting connections and drains in-flight requests.
+	PhaseHTTP Phase = iota
+	// PhaseOutbound flushes and closes outbound publishers.
+	PhaseOutbound
+	// PhaseStorage closes object storage clients.
+	PhaseStorage
+	// PhaseDatabase closes database pools.
+	PhaseDatabase
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseHTTP:
+		return "http"
+	case PhaseOutbound:
+		return "outbound"
+	case PhaseStorage:
+		return "storage"
+	case PhaseDatabase:
+		return "database"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// CloseFunc releases a resource. It should honour ctx cancellation.
+type CloseFunc func(ctx context.Context) error
+
+type closer struct {
+	phase Phase
+	name  string
+	fn    CloseFunc
+}
+
+// Manager coordinates graceful shutdown of registered resources and keeps
+// track of in-flight HTTP requests.
+type Manager struct {
+	mu       sync.Mutex
+	closers  []closer
+	inFlight atomic.Int64
+	done     bool
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Register adds a closer to the given phase. Closers within a phase run in
+// reverse registration order, mirroring defer semantics.
+func (m *Manager) Register(phase Phase, name string, fn CloseFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{phase: phase, name: name, fn: fn})
+}
+
+// RequestStarted increments the in-flight request counter.
+func (m *Manager) RequestStarted() {
+	m.inFlight.Add(1)
+}
+
+// RequestFinished decrements the in-flight request counter.
+func (m *Manager) RequestFinished() {
+	m.inFlight.Add(-1)
+}
+
+// InFlight returns the number of requests currently being served.
+func (m *Manager) InFlight() int64 {
+	return m.inFlight.Load()
+}
+
+// Shutdown runs every registered closer phase by phase. Errors are logged
+// and collected; a failing closer does not prevent later phases from
+// running. Calling Shutdown more than once is a no-op.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	closers := make([]closer, len(m.closers))
+	copy(closers, m.closers)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, phase := range []Phase{PhaseHTTP, PhaseOutbound, PhaseStorage, PhaseDatabase} {
+		for i := len(closers) - 1; i >= 0; i-- {
+			c := closers[i]
+			if c.phase != phase {
+				continue
+			}
+			if err := m.runCloser(ctx, c); err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", c.phase, c.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) runCloser(ctx context.Context, c closer) error {
+	logger.Infof(ctx, "Shutting down %s (%s phase)", c.name, c.phase)
+	start := time.Now()
+
+	stopReporting := func() {}
+	if c.phase == PhaseHTTP {
+		stopReporting = m.reportInFlight(ctx)
+	}
+	err := c.fn(ctx)
+	stopReporting()
+
+	if err != nil {
+		logger.Errorf(ctx, err, "Failed to shut down %s", c.name)
+		return err
+	}
+	logger.Infof(ctx, "Shut down %s in %s", c.name, time.Since(start))
+	return nil
+}
+
+// reportInFlight logs the in-flight request count every second until the
+// returned stop function is called.
+func (m *Manager) reportInFlight(ctx context.Context) func() {
+	logger.Infof(ctx, "Draining %d in-flight requests", m.InFlight())
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				logger.Infof(ctx, "Still draining %d in-flight requests", m.InFlight())
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		if remaining := m.InFlight(); remaining > 0 {
+			logger.Warnf(ctx, "Abandoned %d in-flight requests", remaining)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestTracker counts requests that are currently being served.
+type RequestTracker interface {
+	RequestStarted()
+	RequestFinished()
+}
+
+// InFlightMiddleware reports every request to the tracker so shutdown can
+// tell how many requests are still draining.
+func InFlightMiddleware(tracker RequestTracker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tracker.RequestStarted()
+		defer tracker.RequestFinished()
+		return c.Next()
+	}
+}
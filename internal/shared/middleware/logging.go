@@ -24,7 +24,7 @@ type LoggingConfig struct {
 func DefaultLoggingConfig() LoggingConfig {
 	return LoggingConfig{
 		MaxBodyLogSize:  1024,
-		SkipPaths:       []string{"/health", "/metrics"},
+		SkipPaths:       []string{"/health", "/livez", "/readyz", "/metrics"},
 		LogRequestBody:  true,
 		LogResponseBody: false,
 	}
@@ -75,9 +75,11 @@ func LoggingMiddleware(config ...LoggingConfig) fiber.Handler {
 
 		start := time.Now()
 
+		// Read the raw body: c.Body() would transparently decompress it
+		// before the protocol routes' bomb protection gets a chance to run.
 		var requestBody []byte
-		if cfg.LogRequestBody && c.Body() != nil {
-			requestBody = c.Body()
+		if cfg.LogRequestBody && len(c.Get(fiber.HeaderContentEncoding)) == 0 {
+			requestBody = c.Request().Body()
 		}
 
 		log.RequestStart(ctx, httpReq, requestBody)
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a thread-safe token bucket. Tokens refill continuously at
+// rate per second up to capacity.
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucket returns a full bucket.
+func NewTokenBucket(capacity, ratePerSecond float64) *TokenBucket {
+	return &TokenBucket{
+		capacity: capacity,
+		rate:     ratePerSecond,
+		tokens:   capacity,
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Take removes n tokens if they are available. When allowDebt is true a
+// request is admitted as long as the bucket is not already in debt, letting
+// single requests larger than the capacity through once the bucket has
+// refilled. On rejection it returns how long to wait before retrying.
+func (b *TokenBucket) Take(n float64, allowDebt bool) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	if allowDebt {
+		if b.tokens > 0 {
+			b.tokens -= n
+			return true, 0
+		}
+		return false, b.waitFor(-b.tokens)
+	}
+
+	if b.tokens >= n {
+		b.tokens -= n
+		return true, 0
+	}
+	return false, b.waitFor(n - b.tokens)
+}
+
+// Refund returns n tokens taken by a request that was rejected elsewhere,
+// without exceeding the capacity.
+func (b *TokenBucket) Refund(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens = math.Min(b.capacity, b.tokens+n)
+}
+
+func (b *TokenBucket) refill() {
+	now := b.now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+}
+
+func (b *TokenBucket) waitFor(missing float64) time.Duration {
+	if b.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil(missing / b.rate * float64(time.Second)))
+}
@@ -0,0 +1,262 @@
+// Package consumer reads on_search pointer events, fetches the payloads
+// they reference from object storage and hands typed catalogs to a
+// handler. Messages are committed once handled or dead-lettered, so
+// delivery is at-least-once and handlers must be idempotent.
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"adapter/pkg/events"
+	"adapter/pkg/ondc"
+)
+
+// Delivery is a resolved pointer event passed to a Handler.
+type Delivery struct {
+	Message  events.Message
+	EventID  string
+	Pointer  events.OnSearchPointer
+	Payload  []byte
+	OnSearch *ondc.OnSearch
+	// Attempt starts at 1 and increases on each retry.
+	Attempt int
+}
+
+// Handler processes a delivery. Returning an error retries the delivery;
+// wrap it with Permanent to dead-letter it immediately.
+type Handler func(ctx context.Context, delivery *Delivery) error
+
+// Storage reads the objects pointers reference, e.g. a MinIO or S3 bucket.
+type Storage interface {
+	// Download returns the stored object as written, without decoding it.
+	Download(ctx context.Context, objectName string) ([]byte, error)
+	GetBucket() string
+}
+
+type Options struct {
+	// MaxAttempts is the number of times a delivery is tried before it is
+	// dead-lettered. Defaults to 5.
+	MaxAttempts int
+	// BackoffBase and BackoffMax bound the exponential delay between
+	// attempts. Default to 500ms and 30s.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// DLQTopic receives messages that could not be processed, with the
+	// failure recorded in dlq_* headers. Without a DLQPublisher failed
+	// messages are logged and committed.
+	DLQTopic     string
+	DLQPublisher events.Publisher
+	// MaxPayloadBytes caps a decompressed payload. Defaults to 256MiB.
+	MaxPayloadBytes int64
+	// Logger receives retries, skips and dead letters. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// PermanentError marks a failure that retrying cannot fix.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the delivery is dead-lettered without retries.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// Consumer drives a subscription through a Handler.
+type Consumer struct {
+	subscriber events.Subscriber
+	storage    Storage
+	handler    Handler
+	opts       Options
+	log        *slog.Logger
+}
+
+// New returns a Consumer reading pointers from subscriber and their
+// payloads from storage.
+func New(subscriber events.Subscriber, storage Storage, handler Handler, opts Options) (*Consumer, error) {
+	if subscriber == nil {
+		return nil, fmt.Errorf("subscriber is nil")
+	}
+	if storage == nil {
+		return nil, fmt.Errorf("object storage is nil")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler is nil")
+	}
+	if opts.DLQPublisher != nil && opts.DLQTopic == "" {
+		return nil, fmt.Errorf("dlq topic is required with a dlq publisher")
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.BackoffBase <= 0 {
+		opts.BackoffBase = 500 * time.Millisecond
+	}
+	if opts.BackoffMax <= 0 {
+		opts.BackoffMax = 30 * time.Second
+	}
+	if opts.MaxPayloadBytes <= 0 {
+		opts.MaxPayloadBytes = 256 << 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Consumer{
+		subscriber: subscriber,
+		storage:    storage,
+		handler:    handler,
+		opts:       opts,
+		log:        opts.Logger,
+	}, nil
+}
+
+// Run consumes until ctx is cancelled or the subscription is closed. It
+// returns an error only when a failed message could not be dead-lettered;
+// the message is then left uncommitted so it is redelivered.
+func (c *Consumer) Run(ctx context.Context) error {
+	fetchFailures := 0
+	for {
+		msg, err := c.subscriber.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, events.ErrSubscriptionClosed) {
+				return nil
+			}
+			fetchFailures++
+			c.log.ErrorContext(ctx, "Failed to fetch pointer event", "attempt", fetchFailures, "error", err)
+			c.sleep(ctx, fetchFailures)
+			continue
+		}
+		fetchFailures = 0
+
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, msg events.Message) error {
+	attempts, err := c.process(ctx, msg)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the message uncommitted for redelivery
+			return nil
+		}
+		if err := c.deadLetter(ctx, msg, attempts, err); err != nil {
+			return err
+		}
+	}
+
+	if err := c.subscriber.Commit(ctx, msg); err != nil {
+		c.log.ErrorContext(ctx, "Failed to commit, the message will be redelivered", "topic", msg.Topic, "offset", msg.Offset, "error", err)
+	}
+	return nil
+}
+
+// process resolves the pointer and runs the handler with retries. It
+// returns the number of attempts made.
+func (c *Consumer) process(ctx context.Context, msg events.Message) (int, error) {
+	event, pointer, err := decodePointer(msg.Value)
+	if err != nil {
+		return 0, Permanent(err)
+	}
+	if pointer == nil {
+		c.log.DebugContext(ctx, "Skipping event", "id", event.ID, "type", event.Type)
+		return 0, nil
+	}
+
+	delivery := &Delivery{
+		Message: msg,
+		EventID: event.ID,
+		Pointer: *pointer,
+	}
+
+	for attempt := 1; ; attempt++ {
+		delivery.Attempt = attempt
+		err := c.attempt(ctx, delivery)
+		if err == nil {
+			return attempt, nil
+		}
+		if isPermanent(err) || attempt >= c.opts.MaxAttempts || ctx.Err() != nil {
+			return attempt, err
+		}
+
+		c.log.WarnContext(ctx, "Processing failed", "object_key", pointer.ObjectKey, "attempt", attempt, "max_attempts", c.opts.MaxAttempts, "error", err)
+		c.sleep(ctx, attempt)
+	}
+}
+
+func (c *Consumer) attempt(ctx context.Context, delivery *Delivery) error {
+	if delivery.OnSearch == nil {
+		payload, err := c.fetchPayload(ctx, &delivery.Pointer)
+		if err != nil {
+			return err
+		}
+		var onSearch ondc.OnSearch
+		if err := json.Unmarshal(payload, &onSearch); err != nil {
+			return Permanent(fmt.Errorf("failed to decode on_search payload: %w", err))
+		}
+		delivery.Payload = payload
+		delivery.OnSearch = &onSearch
+	}
+	return c.handler(ctx, delivery)
+}
+
+// deadLetter forwards msg to the DLQ with the failure recorded in headers.
+func (c *Consumer) deadLetter(ctx context.Context, msg events.Message, attempts int, cause error) error {
+	if c.opts.DLQPublisher == nil {
+		c.log.ErrorContext(ctx, "Dropping message, no DLQ configured", "topic", msg.Topic, "offset", msg.Offset, "attempts", attempts, "error", cause)
+		return nil
+	}
+
+	headers := append([]events.Header(nil), msg.Headers...)
+	headers = append(headers,
+		events.Header{Key: "dlq_error", Value: []byte(cause.Error())},
+		events.Header{Key: "dlq_attempts", Value: []byte(strconv.Itoa(attempts))},
+		events.Header{Key: "dlq_source_topic", Value: []byte(msg.Topic)},
+		events.Header{Key: "dlq_partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		events.Header{Key: "dlq_offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		events.Header{Key: "dlq_failed_at", Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+	if err := c.opts.DLQPublisher.Publish(ctx, c.opts.DLQTopic, msg.Key, msg.Value, headers...); err != nil {
+		return fmt.Errorf("failed to dead-letter %s offset %d: %w", msg.Topic, msg.Offset, err)
+	}
+	c.log.WarnContext(ctx, "Dead-lettered message", "topic", msg.Topic, "offset", msg.Offset, "dlq_topic", c.opts.DLQTopic, "attempts", attempts, "error", cause)
+	return nil
+}
+
+func (c *Consumer) sleep(ctx context.Context, attempt int) {
+	delay := c.opts.BackoffBase << min(attempt-1, 20)
+	if delay <= 0 || delay > c.opts.BackoffMax {
+		delay = c.opts.BackoffMax
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
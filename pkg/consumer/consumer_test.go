@@ -0,0 +1,136 @@
+package consumer_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"adapter/pkg/consumer"
+	"adapter/pkg/events"
+)
+
+// queue is an events.Subscriber over a fixed list of messages. It closes
+// once they are all committed.
+type queue struct {
+	mu        sync.Mutex
+	messages  []events.Message
+	committed []int64
+}
+
+func (q *queue) Fetch(ctx context.Context) (events.Message, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.messages) == 0 {
+		return events.Message{}, events.ErrSubscriptionClosed
+	}
+	msg := q.messages[0]
+	q.messages = q.messages[1:]
+	return msg, nil
+}
+
+func (q *queue) Commit(ctx context.Context, msg events.Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.committed = append(q.committed, msg.Offset)
+	return nil
+}
+
+type bucket map[string][]byte
+
+func (b bucket) Download(ctx context.Context, objectName string) ([]byte, error) {
+	object, ok := b[objectName]
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", objectName)
+	}
+	return object, nil
+}
+
+func (b bucket) GetBucket() string {
+	return "ondc"
+}
+
+type deadLetters struct {
+	topics []string
+}
+
+func (d *deadLetters) Publish(ctx context.Context, topic string, key, value []byte, headers ...events.Header) error {
+	d.topics = append(d.topics, topic)
+	return nil
+}
+
+func pointerMessage(t *testing.T, offset int64, objectKey, checksum string) events.Message {
+	t.Helper()
+	envelope := events.NewEnvelope("/adapter/test", events.OnSearchPointerType, "T1", events.OnSearchPointerSchemaPath, events.OnSearchPointer{
+		Bucket:    "ondc",
+		ObjectKey: objectKey,
+		SHA256:    checksum,
+		Action:    "on_search",
+	})
+	value, headers, err := envelope.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return events.Message{Topic: "on_search", Value: value, Headers: headers, Offset: offset}
+}
+
+func TestConsumerRun(t *testing.T) {
+	payload := []byte(`{"context":{"action":"on_search","transaction_id":"T1"},"message":{"catalog":{"bpp/providers":[{"id":"P1"}]}}}`)
+	sum := sha256.Sum256(payload)
+	checksum := hex.EncodeToString(sum[:])
+
+	subscriber := &queue{messages: []events.Message{
+		pointerMessage(t, 1, "good.json", checksum),
+		pointerMessage(t, 2, "tampered.json", checksum),
+		{Topic: "on_search", Value: []byte(`{"type":"ondc.other.v1"}`), Offset: 3},
+	}}
+	storage := bucket{"good.json": payload, "tampered.json": []byte(`{}`)}
+	dlq := &deadLetters{}
+
+	var delivered []string
+	handler := func(ctx context.Context, delivery *consumer.Delivery) error {
+		delivered = append(delivered, delivery.OnSearch.Context.TransactionID+"/"+delivery.Pointer.ObjectKey)
+		return nil
+	}
+	c, err := consumer.New(subscriber, storage, handler, consumer.Options{
+		MaxAttempts:  2,
+		BackoffBase:  time.Millisecond,
+		DLQTopic:     "on_search.dlq",
+		DLQPublisher: dlq,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(delivered) != 1 || delivered[0] != "T1/good.json" {
+		t.Fatalf("delivered %v, want [T1/good.json]", delivered)
+	}
+	if len(dlq.topics) != 1 || dlq.topics[0] != "on_search.dlq" {
+		t.Fatalf("dead-lettered to %v, want the checksum mismatch on on_search.dlq", dlq.topics)
+	}
+	if len(subscriber.committed) != 3 {
+		t.Fatalf("committed offsets %v, want all three", subscriber.committed)
+	}
+}
+
+func TestNewRequiresDependencies(t *testing.T) {
+	handler := func(ctx context.Context, delivery *consumer.Delivery) error { return nil }
+	if _, err := consumer.New(nil, bucket{}, handler, consumer.Options{}); err == nil {
+		t.Fatal("New accepted a nil subscriber")
+	}
+	if _, err := consumer.New(&queue{}, bucket{}, handler, consumer.Options{DLQPublisher: &deadLetters{}}); err == nil {
+		t.Fatal("New accepted a DLQ publisher without a topic")
+	}
+	if _, err := consumer.New(&queue{}, bucket{}, handler, consumer.Options{}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"adapter/pkg/events"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// pointerEvent is events.Envelope with the data left undecoded so the
+// type can be checked first.
+type pointerEvent struct {
+	SpecVersion string          `json:"specversion"`
+	ID          string          `json:"id"`
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Time        time.Time       `json:"time"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func decodePointer(value []byte) (*pointerEvent, *events.OnSearchPointer, error) {
+	var event pointerEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode event envelope: %w", err)
+	}
+	if event.Type != events.OnSearchPointerType {
+		return &event, nil, nil
+	}
+
+	var pointer events.OnSearchPointer
+	if err := json.Unmarshal(event.Data, &pointer); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode on_search pointer: %w", err)
+	}
+	if pointer.ObjectKey == "" {
+		return nil, nil, fmt.Errorf("on_search pointer %s has no object key", event.ID)
+	}
+	return &event, &pointer, nil
+}
+
+// fetchPayload downloads the object a pointer references, gunzips it when
+// needed and verifies its checksum.
+func (c *Consumer) fetchPayload(ctx context.Context, pointer *events.OnSearchPointer) ([]byte, error) {
+	if bucket := c.storage.GetBucket(); pointer.Bucket != "" && pointer.Bucket != bucket {
+		return nil, Permanent(fmt.Errorf("pointer references bucket %s, consumer reads %s", pointer.Bucket, bucket))
+	}
+
+	object, err := c.storage.Download(ctx, pointer.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", pointer.ObjectKey, err)
+	}
+
+	payload := object
+	if bytes.HasPrefix(object, gzipMagic) || strings.HasSuffix(pointer.ObjectKey, ".gz") {
+		payload, err = gunzip(object, c.opts.MaxPayloadBytes)
+		if err != nil {
+			return nil, Permanent(fmt.Errorf("failed to decompress %s: %w", pointer.ObjectKey, err))
+		}
+	}
+
+	if pointer.SHA256 != "" {
+		sum := sha256.Sum256(payload)
+		if hex.EncodeToString(sum[:]) != pointer.SHA256 {
+			return nil, Permanent(fmt.Errorf("checksum mismatch for %s", pointer.ObjectKey))
+		}
+	}
+	return payload, nil
+}
+
+func gunzip(data []byte, maxBytes int64) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(payload)) > maxBytes {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", maxBytes)
+	}
+	return payload, nil
+}
@@ -0,0 +1,89 @@
+// Package eventbus subscribes to the edge's events on Kafka or NATS
+// JetStream. It holds the connection settings shared with the edge's own
+// publishers so that consumers in other modules connect the same way.
+package eventbus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// KafkaSecurityConfig configures TLS and SASL authentication.
+type KafkaSecurityConfig struct {
+	TLSEnabled            bool
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSInsecureSkipVerify bool
+
+	// SASLMechanism is empty (disabled), plain, scram-sha-256 or scram-sha-512.
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+}
+
+// KafkaTLSConfig returns the TLS configuration for cfg, or nil when TLS is
+// disabled.
+func KafkaTLSConfig(cfg KafkaSecurityConfig) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in kafka CA file %s", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// KafkaSASLMechanism returns the SASL mechanism for cfg, or nil when SASL
+// is disabled.
+func KafkaSASLMechanism(cfg KafkaSecurityConfig) (sasl.Mechanism, error) {
+	switch strings.ToLower(cfg.SASLMechanism) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "scram-sha-256":
+		mechanism, err := scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SCRAM-SHA-256: %w", err)
+		}
+		return mechanism, nil
+	case "scram-sha-512":
+		mechanism, err := scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SCRAM-SHA-512: %w", err)
+		}
+		return mechanism, nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism %q", cfg.SASLMechanism)
+	}
+}
@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"adapter/pkg/events"
+)
+
+// KafkaSubscriberConfig configures a consumer group member.
+type KafkaSubscriberConfig struct {
+	Brokers string
+	Topic   string
+	// GroupID is the consumer group; offsets are committed per group.
+	GroupID string
+
+	Security KafkaSecurityConfig
+
+	// StartOffset is "earliest" or "latest" and only applies when the group
+	// has no committed offset yet. Defaults to earliest.
+	StartOffset string
+	// MaxBytes caps a single fetch; defaults to 10MiB.
+	MaxBytes int
+}
+
+// KafkaSubscriber implements events.Subscriber with a kafka-go
+// consumer group reader. Offsets are committed explicitly.
+type KafkaSubscriber struct {
+	reader *kafka.Reader
+}
+
+func NewKafkaSubscriber(cfg KafkaSubscriberConfig) (*KafkaSubscriber, error) {
+	brokers := strings.Split(cfg.Brokers, ",")
+	if cfg.Brokers == "" {
+		return nil, fmt.Errorf("no kafka brokers configured")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("kafka consumer group id is required")
+	}
+
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	tlsConfig, err := KafkaTLSConfig(cfg.Security)
+	if err != nil {
+		return nil, err
+	}
+	dialer.TLS = tlsConfig
+	mechanism, err := KafkaSASLMechanism(cfg.Security)
+	if err != nil {
+		return nil, err
+	}
+	dialer.SASLMechanism = mechanism
+
+	startOffset := kafka.FirstOffset
+	switch cfg.StartOffset {
+	case "", "earliest":
+	case "latest":
+		startOffset = kafka.LastOffset
+	default:
+		return nil, fmt.Errorf("unsupported kafka start offset %q", cfg.StartOffset)
+	}
+
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+
+	return &KafkaSubscriber{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     brokers,
+			GroupID:     cfg.GroupID,
+			Topic:       cfg.Topic,
+			Dialer:      dialer,
+			StartOffset: startOffset,
+			MaxBytes:    maxBytes,
+			// Offsets are only committed through Commit
+			CommitInterval: 0,
+		}),
+	}, nil
+}
+
+func (s *KafkaSubscriber) Fetch(ctx context.Context) (events.Message, error) {
+	msg, err := s.reader.FetchMessage(ctx)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return events.Message{}, events.ErrSubscriptionClosed
+		}
+		return events.Message{}, fmt.Errorf("failed to fetch kafka message: %w", err)
+	}
+
+	headers := make([]events.Header, 0, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers = append(headers, events.Header{Key: header.Key, Value: header.Value})
+	}
+	return events.Message{
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Time,
+	}, nil
+}
+
+func (s *KafkaSubscriber) Commit(ctx context.Context, msg events.Message) error {
+	if err := s.reader.CommitMessages(ctx, kafka.Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}); err != nil {
+		return fmt.Errorf("failed to commit kafka offset %d on partition %d: %w", msg.Offset, msg.Partition, err)
+	}
+	return nil
+}
+
+// Close leaves the consumer group so its partitions are reassigned.
+func (s *KafkaSubscriber) Close(ctx context.Context) error {
+	if err := s.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close kafka reader: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSKeyHeader carries the message key, which JetStream has no notion of.
+const NATSKeyHeader = "Msg-Key"
+
+// NATSConfig configures a JetStream connection.
+type NATSConfig struct {
+	URL string
+	// Stream is the JetStream stream that captures the published subjects.
+	Stream string
+	// Topics are used as subjects verbatim (e.g. ondc.on_search.pointer).
+	Topics []string
+
+	Username  string
+	Password  string
+	Token     string
+	CredsFile string
+
+	// AutoCreateStream creates or updates Stream to capture Topics.
+	AutoCreateStream bool
+	Replicas         int
+	PublishTimeout   time.Duration
+}
+
+// ConnectNATS opens a JetStream connection and, when configured, creates
+// or updates the stream capturing cfg.Topics.
+func ConnectNATS(cfg NATSConfig) (*nats.Conn, jetstream.JetStream, error) {
+	opts := []nats.Option{
+		nats.Name("gcr-edge-service"),
+		nats.MaxReconnects(-1),
+	}
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.Token != "":
+		opts = append(opts, nats.Token(cfg.Token))
+	case cfg.Username != "":
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to init JetStream: %w", err)
+	}
+
+	if cfg.AutoCreateStream {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		replicas := cfg.Replicas
+		if replicas <= 0 {
+			replicas = 1
+		}
+		if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:     cfg.Stream,
+			Subjects: cfg.Topics,
+			Replicas: replicas,
+			Storage:  jetstream.FileStorage,
+		}); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to create JetStream stream %s: %w", cfg.Stream, err)
+		}
+	}
+	return conn, js, nil
+}
@@ -0,0 +1,136 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"adapter/pkg/events"
+)
+
+// natsFetchWait bounds a single pull so Fetch notices ctx cancellation.
+const natsFetchWait = time.Second
+
+// NATSSubscriber implements events.Subscriber with a durable JetStream
+// pull consumer. Commit acknowledges the message; unacknowledged messages
+// are redelivered after the consumer's ack wait.
+type NATSSubscriber struct {
+	conn     *nats.Conn
+	consumer jetstream.Consumer
+
+	mu      sync.Mutex
+	pending map[uint64]jetstream.Msg
+}
+
+// NewNATSSubscriber creates (or reuses) the durable consumer for topic on
+// cfg.Stream.
+func NewNATSSubscriber(cfg NATSConfig, topic, durable string) (*NATSSubscriber, error) {
+	if durable == "" {
+		return nil, fmt.Errorf("nats durable consumer name is required")
+	}
+
+	conn, js, err := ConnectNATS(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: topic,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       5 * time.Minute,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream consumer %s: %w", durable, err)
+	}
+
+	return &NATSSubscriber{
+		conn:     conn,
+		consumer: consumer,
+		pending:  make(map[uint64]jetstream.Msg),
+	}, nil
+}
+
+func (s *NATSSubscriber) Fetch(ctx context.Context) (events.Message, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return events.Message{}, err
+		}
+		if s.conn.IsClosed() {
+			return events.Message{}, events.ErrSubscriptionClosed
+		}
+
+		msg, err := s.consumer.Next(jetstream.FetchMaxWait(natsFetchWait))
+		if errors.Is(err, nats.ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return events.Message{}, fmt.Errorf("failed to fetch NATS message: %w", err)
+		}
+
+		meta, err := msg.Metadata()
+		if err != nil {
+			return events.Message{}, fmt.Errorf("failed to read NATS message metadata: %w", err)
+		}
+
+		var key []byte
+		var headers []events.Header
+		for name, values := range msg.Headers() {
+			if name == NATSKeyHeader {
+				key = []byte(values[0])
+				continue
+			}
+			for _, value := range values {
+				headers = append(headers, events.Header{Key: name, Value: []byte(value)})
+			}
+		}
+
+		s.mu.Lock()
+		s.pending[meta.Sequence.Stream] = msg
+		s.mu.Unlock()
+
+		return events.Message{
+			Topic:     msg.Subject(),
+			Key:       key,
+			Value:     msg.Data(),
+			Headers:   headers,
+			Offset:    int64(meta.Sequence.Stream),
+			Timestamp: meta.Timestamp,
+		}, nil
+	}
+}
+
+func (s *NATSSubscriber) Commit(ctx context.Context, msg events.Message) error {
+	s.mu.Lock()
+	pending, ok := s.pending[uint64(msg.Offset)]
+	delete(s.pending, uint64(msg.Offset))
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending NATS message with sequence %d", msg.Offset)
+	}
+	if err := pending.DoubleAck(ctx); err != nil {
+		return fmt.Errorf("failed to ack NATS message %d: %w", msg.Offset, err)
+	}
+	return nil
+}
+
+// Close drains the connection; unacknowledged messages are redelivered to
+// the next member of the durable consumer.
+func (s *NATSSubscriber) Close(ctx context.Context) error {
+	if err := s.conn.Drain(); err != nil {
+		s.conn.Close()
+		return fmt.Errorf("failed to drain NATS connection: %w", err)
+	}
+	return nil
+}
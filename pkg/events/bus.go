@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSubscriptionClosed is returned by Subscriber.Fetch once the
+// subscription has been closed and no more messages will arrive.
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// Header is a message header (e.g. a Kafka record header).
+type Header struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Message is an event received from a subscription.
+type Message struct {
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Partition int
+	Offset    int64
+	Timestamp time.Time
+}
+
+// Header returns the first header value with the given key.
+func (m Message) Header(key string) string {
+	for _, header := range m.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Publisher sends events to a topic (e.g. Kafka).
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte, headers ...Header) error
+}
+
+// Subscriber consumes events with explicit commits (e.g. a Kafka consumer
+// group).
+type Subscriber interface {
+	// Fetch blocks until the next message is available or ctx is done.
+	Fetch(ctx context.Context) (Message, error)
+	// Commit marks the message as processed so it is not redelivered.
+	Commit(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,113 @@
+// Package events defines the contract between the edge and the services
+// consuming its events: the CloudEvents envelope, the on_search pointer
+// and the bus messages carrying them. It has no dependency on the edge's
+// internals so other modules can import it.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CloudEventsSpecVersion is the CloudEvents version the envelope follows.
+	CloudEventsSpecVersion = "1.0"
+	// OnSearchPointerType is versioned: breaking changes to the data
+	// payload get a new type rather than silently changing this one.
+	OnSearchPointerType = "ondc.on_search.pointer.v1"
+	// OnSearchPointerSchemaPath is where the event's JSON Schema is served.
+	OnSearchPointerSchemaPath = "/schemas/events/ondc.on_search.pointer.v1.json"
+
+	eventContentType = "application/json"
+	// cloudEventsContentType marks a structured-mode CloudEvent on Kafka.
+	cloudEventsContentType = "application/cloudevents+json; charset=UTF-8"
+)
+
+// Envelope is a CloudEvents 1.0 structured-mode event.
+type Envelope struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema,omitempty"`
+	Data            any       `json:"data"`
+}
+
+// NewEnvelope wraps data in a CloudEvents envelope with a fresh id.
+func NewEnvelope(source, eventType, subject, dataSchema string, data any) Envelope {
+	return Envelope{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              uuid.NewString(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: eventContentType,
+		DataSchema:      dataSchema,
+		Data:            data,
+	}
+}
+
+// Encode serialises the envelope and returns the Kafka headers mirroring
+// its attributes, so consumers can route and filter without parsing the
+// body.
+func (e Envelope) Encode(extra ...Header) ([]byte, []Header, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode %s event: %w", e.Type, err)
+	}
+
+	headers := []Header{
+		{Key: "content-type", Value: []byte(cloudEventsContentType)},
+		{Key: "ce_specversion", Value: []byte(e.SpecVersion)},
+		{Key: "ce_id", Value: []byte(e.ID)},
+		{Key: "ce_source", Value: []byte(e.Source)},
+		{Key: "ce_type", Value: []byte(e.Type)},
+		{Key: "ce_time", Value: []byte(e.Time.Format(time.RFC3339Nano))},
+	}
+	if e.Subject != "" {
+		headers = append(headers, Header{Key: "ce_subject", Value: []byte(e.Subject)})
+	}
+	for _, header := range extra {
+		if len(header.Value) > 0 {
+			headers = append(headers, header)
+		}
+	}
+	return body, headers, nil
+}
+
+// OnSearchPointer references an on_search payload stored in object storage
+// together with the context needed to route it without downloading it.
+type OnSearchPointer struct {
+	Storage       string `json:"storage"`
+	Bucket        string `json:"bucket"`
+	ObjectKey     string `json:"object_key"`
+	ContentType   string `json:"content_type"`
+	SizeBytes     int64  `json:"size_bytes"`
+	SHA256        string `json:"sha256"`
+	Domain        string `json:"domain"`
+	Action        string `json:"action"`
+	TransactionID string `json:"transaction_id"`
+	MessageID     string `json:"message_id"`
+	BapID         string `json:"bap_id,omitempty"`
+	BppID         string `json:"bpp_id,omitempty"`
+	City          string `json:"city,omitempty"`
+	CoreVersion   string `json:"core_version,omitempty"`
+	// Late callbacks are still published so consumers can decide whether
+	// to use them.
+	Late             bool     `json:"late,omitempty"`
+	TimingViolations []string `json:"timing_violations,omitempty"`
+	// Mode is "incremental" for catalog_inc deltas, which must be applied
+	// to the provider's last full catalog, and "full" otherwise.
+	Mode string `json:"mode,omitempty"`
+	// Complete is set on the pointer to the catalog merged from every
+	// on_search part a seller sent for the search; Parts counts them.
+	Complete bool `json:"complete,omitempty"`
+	Parts    int  `json:"parts,omitempty"`
+}
@@ -0,0 +1,202 @@
+package ondc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fastjson"
+)
+
+// ErrNoContext is returned when a payload has no context object.
+var ErrNoContext = errors.New("payload has no context object")
+
+// The accessors below parse the payload with fastjson and decode only the
+// part they return, so routing a multi-megabyte catalog does not require
+// unmarshalling every item.
+
+// ParseContext decodes only the context block of payload.
+func ParseContext(payload []byte) (*Context, error) {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	return contextOf(v)
+}
+
+func contextOf(v *fastjson.Value) (*Context, error) {
+	ctxValue := v.Get("context")
+	if ctxValue == nil || ctxValue.Type() != fastjson.TypeObject {
+		return nil, ErrNoContext
+	}
+
+	var ctx Context
+	if err := json.Unmarshal(ctxValue.MarshalTo(nil), &ctx); err != nil {
+		return nil, fmt.Errorf("failed to decode context: %w", err)
+	}
+	return &ctx, nil
+}
+
+// EachProvider decodes the catalog providers of an on_search payload one at
+// a time. Returning an error from fn stops the iteration and returns it.
+func EachProvider(payload []byte, fn func(provider *Provider) error) error {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, providerValue := range catalogArray(v, "providers") {
+		var provider Provider
+		if err := json.Unmarshal(providerValue.MarshalTo(nil), &provider); err != nil {
+			return fmt.Errorf("failed to decode provider: %w", err)
+		}
+		if err := fn(&provider); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OrderState returns message.order.state, or "" when the payload carries no
+// order (e.g. on_search).
+func OrderState(payload []byte) string {
+	return fastjson.GetString(payload, "message", "order", "state")
+}
+
+// CatalogStats counts providers and items without decoding them.
+func CatalogStats(payload []byte) (providers, items int, err error) {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, provider := range catalogArray(v, "providers") {
+		providers++
+		items += len(asArray(provider.Get("items")))
+	}
+	return providers, items, nil
+}
+
+// catalogArray returns message.catalog["bpp/<name>"], falling back to the
+// unprefixed v2.x key.
+func catalogArray(v *fastjson.Value, name string) []*fastjson.Value {
+	catalog := v.Get("message", "catalog")
+	if catalog == nil {
+		return nil
+	}
+	if values := catalog.Get("bpp/" + name); values != nil {
+		return asArray(values)
+	}
+	return asArray(catalog.Get(name))
+}
+
+// asArray mirrors List: a single object is treated as a one-element array.
+func asArray(v *fastjson.Value) []*fastjson.Value {
+	if v == nil {
+		return nil
+	}
+	switch v.Type() {
+	case fastjson.TypeArray:
+		values, _ := v.Array()
+		return values
+	case fastjson.TypeObject:
+		return []*fastjson.Value{v}
+	}
+	return nil
+}
+
+// CatalogHeader is the part of an on_search catalog shared by all its
+// providers, kept as raw JSON.
+type CatalogHeader struct {
+	Descriptor   json.RawMessage
+	Fulfillments json.RawMessage
+}
+
+// RawProvider is one catalog provider decoded for lookups together with
+// its raw JSON, so it can be re-sent without losing fields the typed model
+// does not know. JSON holds the provider without its items; Items holds
+// each raw item in the order of Provider.Items.
+type RawProvider struct {
+	Provider Provider
+	JSON     json.RawMessage
+	Items    []json.RawMessage
+}
+
+// EachRawProvider walks the providers of an on_search payload like
+// EachProvider, also passing their raw JSON and the catalog header.
+func EachRawProvider(payload []byte, fn func(header *CatalogHeader, provider *RawProvider) error) error {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return err
+	}
+
+	header := &CatalogHeader{}
+	if catalog := v.Get("message", "catalog"); catalog != nil {
+		header.Descriptor = rawField(catalog, "descriptor")
+		header.Fulfillments = rawField(catalog, "fulfillments")
+	}
+
+	for _, providerValue := range catalogArray(v, "providers") {
+		raw := &RawProvider{}
+		if err := json.Unmarshal(providerValue.MarshalTo(nil), &raw.Provider); err != nil {
+			return fmt.Errorf("failed to decode provider: %w", err)
+		}
+		for _, item := range asArray(providerValue.Get("items")) {
+			raw.Items = append(raw.Items, item.MarshalTo(nil))
+		}
+		if providerValue.Type() == fastjson.TypeObject {
+			providerValue.Del("items")
+		}
+		raw.JSON = providerValue.MarshalTo(nil)
+		if err := fn(header, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawField returns catalog["bpp/<name>"] or catalog[name] as raw JSON.
+func rawField(catalog *fastjson.Value, name string) json.RawMessage {
+	value := catalog.Get("bpp/" + name)
+	if value == nil {
+		value = catalog.Get(name)
+	}
+	if value == nil {
+		return nil
+	}
+	return value.MarshalTo(nil)
+}
+
+// CatalogSummary is the part of an on_search catalog needed to classify it
+// without decoding providers.
+type CatalogSummary struct {
+	ProviderIDs []string
+	Tags        Tags
+}
+
+// SummarizeCatalog returns the provider ids and catalog-level tags of an
+// on_search payload.
+func SummarizeCatalog(payload []byte) (*CatalogSummary, error) {
+	var p fastjson.Parser
+	v, err := p.ParseBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CatalogSummary{}
+	for _, provider := range catalogArray(v, "providers") {
+		if id := string(provider.GetStringBytes("id")); id != "" {
+			summary.ProviderIDs = append(summary.ProviderIDs, id)
+		}
+	}
+	if tags := v.Get("message", "catalog", "tags"); tags != nil {
+		if err := json.Unmarshal(tags.MarshalTo(nil), &summary.Tags); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
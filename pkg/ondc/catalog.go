@@ -0,0 +1,321 @@
+package ondc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// OnSearch is an on_search callback.
+type OnSearch struct {
+	Context Context `json:"context"`
+	Message struct {
+		Catalog Catalog `json:"catalog"`
+	} `json:"message"`
+}
+
+// Catalog is the seller app catalog. v1.x prefixes its fields with "bpp/";
+// both spellings are accepted and Catalog always encodes the v1.x form.
+type Catalog struct {
+	Descriptor   Descriptor        `json:"bpp/descriptor"`
+	Providers    List[Provider]    `json:"bpp/providers"`
+	Fulfillments List[Fulfillment] `json:"bpp/fulfillments,omitempty"`
+	Categories   List[Category]    `json:"bpp/categories,omitempty"`
+	Exp          string            `json:"exp,omitempty"`
+	Tags         Tags              `json:"tags,omitempty"`
+}
+
+// catalogV2 is the unprefixed v2.x spelling.
+type catalogV2 struct {
+	Descriptor   *Descriptor       `json:"descriptor"`
+	Providers    List[Provider]    `json:"providers"`
+	Fulfillments List[Fulfillment] `json:"fulfillments"`
+	Categories   List[Category]    `json:"categories"`
+}
+
+func (c *Catalog) UnmarshalJSON(data []byte) error {
+	type plain Catalog
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Providers == nil && bytes.Contains(data, []byte(`"providers"`)) {
+		var v2 catalogV2
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return err
+		}
+		if v2.Descriptor != nil {
+			decoded.Descriptor = *v2.Descriptor
+		}
+		decoded.Providers = v2.Providers
+		if decoded.Fulfillments == nil {
+			decoded.Fulfillments = v2.Fulfillments
+		}
+		if decoded.Categories == nil {
+			decoded.Categories = v2.Categories
+		}
+	}
+
+	*c = Catalog(decoded)
+	return nil
+}
+
+// ItemCount returns the number of items across all providers.
+func (c Catalog) ItemCount() int {
+	count := 0
+	for _, provider := range c.Providers {
+		count += len(provider.Items)
+	}
+	return count
+}
+
+// Provider returns the provider with the given id.
+func (c Catalog) Provider(id string) (*Provider, bool) {
+	for i := range c.Providers {
+		if c.Providers[i].ID == id {
+			return &c.Providers[i], true
+		}
+	}
+	return nil, false
+}
+
+type Descriptor struct {
+	Name      string       `json:"name,omitempty"`
+	Code      string       `json:"code,omitempty"`
+	ShortDesc string       `json:"short_desc,omitempty"`
+	LongDesc  string       `json:"long_desc,omitempty"`
+	Symbol    string       `json:"symbol,omitempty"`
+	Images    List[string] `json:"images,omitempty"`
+}
+
+type Provider struct {
+	ID           string            `json:"id"`
+	Descriptor   Descriptor        `json:"descriptor"`
+	Locations    List[Location]    `json:"locations,omitempty"`
+	Items        List[Item]        `json:"items,omitempty"`
+	Categories   List[Category]    `json:"categories,omitempty"`
+	Fulfillments List[Fulfillment] `json:"fulfillments,omitempty"`
+	Tags         Tags              `json:"tags,omitempty"`
+	TTL          string            `json:"ttl,omitempty"`
+	Exp          string            `json:"exp,omitempty"`
+}
+
+// Location returns the provider location with the given id.
+func (p Provider) Location(id string) (*Location, bool) {
+	for i := range p.Locations {
+		if p.Locations[i].ID == id {
+			return &p.Locations[i], true
+		}
+	}
+	return nil, false
+}
+
+type Item struct {
+	ID            string       `json:"id"`
+	ParentItemID  string       `json:"parent_item_id,omitempty"`
+	Descriptor    Descriptor   `json:"descriptor"`
+	Price         Price        `json:"price"`
+	Quantity      *Quantity    `json:"quantity,omitempty"`
+	CategoryID    string       `json:"category_id,omitempty"`
+	CategoryIDs   List[string] `json:"category_ids,omitempty"`
+	FulfillmentID string       `json:"fulfillment_id,omitempty"`
+	LocationID    string       `json:"location_id,omitempty"`
+	Tags          Tags         `json:"tags,omitempty"`
+}
+
+// Categories returns category_id followed by category_ids without
+// duplicates.
+func (i Item) Categories() []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{i.CategoryID}, i.CategoryIDs...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			categories = append(categories, id)
+		}
+	}
+	return categories
+}
+
+type Quantity struct {
+	Available *QuantityCount `json:"available,omitempty"`
+	Maximum   *QuantityCount `json:"maximum,omitempty"`
+	Minimum   *QuantityCount `json:"minimum,omitempty"`
+	Unitized  *struct {
+		Measure struct {
+			Unit  string     `json:"unit"`
+			Value FlexString `json:"value"`
+		} `json:"measure"`
+	} `json:"unitized,omitempty"`
+}
+
+type QuantityCount struct {
+	Count FlexString `json:"count"`
+}
+
+type Price struct {
+	Currency      string     `json:"currency"`
+	Value         FlexString `json:"value"`
+	MaximumValue  FlexString `json:"maximum_value,omitempty"`
+	OfferedValue  FlexString `json:"offered_value,omitempty"`
+	ListedValue   FlexString `json:"listed_value,omitempty"`
+	EstimateValue FlexString `json:"estimated_value,omitempty"`
+}
+
+// Amount returns the numeric price value.
+func (p Price) Amount() (float64, bool) {
+	return p.Value.Float()
+}
+
+type Location struct {
+	ID      string   `json:"id"`
+	GPS     string   `json:"gps,omitempty"`
+	Address *Address `json:"address,omitempty"`
+	Circle  *Circle  `json:"circle,omitempty"`
+	Time    *Time    `json:"time,omitempty"`
+	Tags    Tags     `json:"tags,omitempty"`
+}
+
+type Address struct {
+	Name     string `json:"name,omitempty"`
+	Building string `json:"building,omitempty"`
+	Street   string `json:"street,omitempty"`
+	Locality string `json:"locality,omitempty"`
+	City     string `json:"city,omitempty"`
+	State    string `json:"state,omitempty"`
+	Country  string `json:"country,omitempty"`
+	AreaCode string `json:"area_code,omitempty"`
+}
+
+type Circle struct {
+	GPS    string  `json:"gps"`
+	Radius Scalars `json:"radius"`
+}
+
+// Scalars is a value with a unit, e.g. a radius.
+type Scalars struct {
+	Unit  string     `json:"unit"`
+	Value FlexString `json:"value"`
+}
+
+// Time is an ONDC time block (store timings, validity ranges).
+type Time struct {
+	Label     string     `json:"label,omitempty"`
+	Timestamp string     `json:"timestamp,omitempty"`
+	Days      string     `json:"days,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+	Schedule  *Schedule  `json:"schedule,omitempty"`
+	Range     *TimeRange `json:"range,omitempty"`
+}
+
+type Schedule struct {
+	Holidays  List[string] `json:"holidays,omitempty"`
+	Frequency string       `json:"frequency,omitempty"`
+	Times     List[string] `json:"times,omitempty"`
+}
+
+type TimeRange struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+type Fulfillment struct {
+	ID       string `json:"id"`
+	Type     string `json:"type,omitempty"`
+	Tracking bool   `json:"tracking,omitempty"`
+	Contact  *struct {
+		Phone string `json:"phone,omitempty"`
+		Email string `json:"email,omitempty"`
+	} `json:"contact,omitempty"`
+	Tags Tags `json:"tags,omitempty"`
+}
+
+type Category struct {
+	ID               string     `json:"id"`
+	ParentCategoryID string     `json:"parent_category_id,omitempty"`
+	Descriptor       Descriptor `json:"descriptor"`
+	Tags             Tags       `json:"tags,omitempty"`
+}
+
+// Tag is a tag group: {"code": "serviceability", "list": [...]}.
+type Tag struct {
+	Code string         `json:"code"`
+	Name string         `json:"name,omitempty"`
+	List List[TagValue] `json:"list,omitempty"`
+}
+
+// TagValue is a single entry of a tag group.
+type TagValue struct {
+	Code  string     `json:"code"`
+	Value FlexString `json:"value"`
+}
+
+// Value returns the value of code within the group.
+func (t Tag) Value(code string) (string, bool) {
+	for _, entry := range t.List {
+		if entry.Code == code {
+			return entry.Value.String(), true
+		}
+	}
+	return "", false
+}
+
+// Tags decodes the v1.2+ list of tag groups, a single group object, and
+// the older flat {"veg": "yes"} object, which becomes one group per key.
+type Tags []Tag
+
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' && !isTagGroup(data) {
+		var flat map[string]FlexString
+		if err := json.Unmarshal(data, &flat); err != nil {
+			return fmt.Errorf("failed to decode tags: %w", err)
+		}
+		tags := make(Tags, 0, len(flat))
+		for code, value := range flat {
+			tags = append(tags, Tag{Code: code, List: List[TagValue]{{Code: code, Value: value}}})
+		}
+		*t = tags
+		return nil
+	}
+
+	var groups List[Tag]
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return fmt.Errorf("failed to decode tags: %w", err)
+	}
+	*t = Tags(groups)
+	return nil
+}
+
+// isTagGroup reports whether a JSON object is a {"code", "list"} group
+// rather than a flat tag object.
+func isTagGroup(data []byte) bool {
+	var probe struct {
+		Code *string         `json:"code"`
+		List json.RawMessage `json:"list"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Code != nil && len(probe.List) > 0
+}
+
+// Groups returns every tag group with the given code; serviceability, for
+// one, repeats per location and category.
+func (t Tags) Groups(code string) []Tag {
+	var groups []Tag
+	for _, tag := range t {
+		if tag.Code == code {
+			groups = append(groups, tag)
+		}
+	}
+	return groups
+}
+
+// Group returns the tag group with the given code.
+func (t Tags) Group(code string) (*Tag, bool) {
+	for i := range t {
+		if t[i].Code == code {
+			return &t[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,59 @@
+// Package ondc is the typed model for ONDC payloads. Decoding is lenient:
+// unknown fields are ignored, numeric fields accept strings and numbers,
+// and the v1.x and v2.x shapes of context and catalog are both accepted.
+package ondc
+
+import "encoding/json"
+
+// Context is the context block shared by every ONDC request.
+type Context struct {
+	Domain        string `json:"domain"`
+	Country       string `json:"country,omitempty"`
+	City          string `json:"city,omitempty"`
+	Action        string `json:"action"`
+	CoreVersion   string `json:"core_version,omitempty"`
+	BapID         string `json:"bap_id,omitempty"`
+	BapURI        string `json:"bap_uri,omitempty"`
+	BppID         string `json:"bpp_id,omitempty"`
+	BppURI        string `json:"bpp_uri,omitempty"`
+	TransactionID string `json:"transaction_id"`
+	MessageID     string `json:"message_id"`
+	Timestamp     string `json:"timestamp,omitempty"`
+	Key           string `json:"key,omitempty"`
+	TTL           string `json:"ttl,omitempty"`
+}
+
+// contextV2 carries the fields renamed or nested in ONDC v2.x.
+type contextV2 struct {
+	Version  string `json:"version"`
+	Location struct {
+		City    struct{ Code string } `json:"city"`
+		Country struct{ Code string } `json:"country"`
+	} `json:"location"`
+}
+
+func (c *Context) UnmarshalJSON(data []byte) error {
+	type plain Context
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.City == "" || decoded.Country == "" || decoded.CoreVersion == "" {
+		var v2 contextV2
+		if err := json.Unmarshal(data, &v2); err == nil {
+			if decoded.City == "" {
+				decoded.City = v2.Location.City.Code
+			}
+			if decoded.Country == "" {
+				decoded.Country = v2.Location.Country.Code
+			}
+			if decoded.CoreVersion == "" {
+				decoded.CoreVersion = v2.Version
+			}
+		}
+	}
+
+	*c = Context(decoded)
+	return nil
+}
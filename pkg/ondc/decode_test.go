@@ -0,0 +1,265 @@
+package ondc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFlexStringDecoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    FlexString
+		wantErr bool
+	}{
+		{name: "quoted", input: `"120.50"`, want: "120.50"},
+		{name: "number", input: `120.50`, want: "120.50"},
+		{name: "integer", input: `7`, want: "7"},
+		{name: "boolean", input: `true`, want: "true"},
+		{name: "null", input: `null`, want: ""},
+		{name: "object", input: `{"value": "1"}`, wantErr: true},
+		{name: "array", input: `["1"]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FlexString
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexStringNumbers(t *testing.T) {
+	tests := []struct {
+		value     FlexString
+		wantFloat float64
+		wantInt   int
+		ok        bool
+	}{
+		{value: "10", wantFloat: 10, wantInt: 10, ok: true},
+		{value: "10.0", wantFloat: 10, wantInt: 10, ok: true},
+		{value: "99.9", wantFloat: 99.9, wantInt: 99, ok: true},
+		{value: "", ok: false},
+		{value: "ten", ok: false},
+	}
+	for _, tt := range tests {
+		f, okFloat := tt.value.Float()
+		i, okInt := tt.value.Int()
+		if okFloat != tt.ok || okInt != tt.ok || f != tt.wantFloat || i != tt.wantInt {
+			t.Errorf("%q: Float() = %v, %v; Int() = %v, %v", tt.value, f, okFloat, i, okInt)
+		}
+	}
+}
+
+func TestListDecoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  List[string]
+	}{
+		{name: "array", input: `["a", "b"]`, want: List[string]{"a", "b"}},
+		{name: "single value", input: `"a"`, want: List[string]{"a"}},
+		{name: "empty array", input: `[]`, want: List[string]{}},
+		{name: "null", input: `null`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got List[string]
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextDecoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Context
+	}{
+		{
+			name:  "v1",
+			input: `{"domain":"ONDC:RET10","country":"IND","city":"std:080","action":"on_search","core_version":"1.2.0","transaction_id":"t","message_id":"m","ttl":"PT30S","unknown":1}`,
+			want:  Context{Domain: "ONDC:RET10", Country: "IND", City: "std:080", Action: "on_search", CoreVersion: "1.2.0", TransactionID: "t", MessageID: "m", TTL: "PT30S"},
+		},
+		{
+			name:  "v2 location and version",
+			input: `{"domain":"ONDC:RET10","action":"on_search","version":"2.0.2","location":{"city":{"code":"std:011"},"country":{"code":"IND"}},"transaction_id":"t","message_id":"m"}`,
+			want:  Context{Domain: "ONDC:RET10", Country: "IND", City: "std:011", Action: "on_search", CoreVersion: "2.0.2", TransactionID: "t", MessageID: "m"},
+		},
+		{
+			name:  "v1 fields win over v2",
+			input: `{"domain":"d","action":"a","city":"std:080","core_version":"1.2.5","version":"2.0.0","location":{"city":{"code":"std:011"},"country":{"code":"IND"}}}`,
+			want:  Context{Domain: "d", Action: "a", City: "std:080", Country: "IND", CoreVersion: "1.2.5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Context
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogDecoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantName  string
+		providers []string
+		items     int
+		price     float64
+	}{
+		{
+			name:      "v1 prefixed",
+			input:     `{"bpp/descriptor":{"name":"Seller"},"bpp/providers":[{"id":"p1","items":[{"id":"i1","price":{"currency":"INR","value":"10.50"}}]}]}`,
+			wantName:  "Seller",
+			providers: []string{"p1"},
+			items:     1,
+			price:     10.5,
+		},
+		{
+			name:      "v2 unprefixed",
+			input:     `{"descriptor":{"name":"Seller"},"providers":[{"id":"p1","items":[{"id":"i1","price":{"value":10.5}},{"id":"i2","price":{"value":"3"}}]},{"id":"p2"}]}`,
+			wantName:  "Seller",
+			providers: []string{"p1", "p2"},
+			items:     2,
+			price:     10.5,
+		},
+		{
+			name:      "single provider and item objects",
+			input:     `{"bpp/providers":{"id":"p1","items":{"id":"i1","price":{"value":10.5}}}}`,
+			providers: []string{"p1"},
+			items:     1,
+			price:     10.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var catalog Catalog
+			if err := json.Unmarshal([]byte(tt.input), &catalog); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if catalog.Descriptor.Name != tt.wantName {
+				t.Errorf("descriptor name = %q, want %q", catalog.Descriptor.Name, tt.wantName)
+			}
+			var ids []string
+			for _, provider := range catalog.Providers {
+				ids = append(ids, provider.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.providers) {
+				t.Errorf("providers = %v, want %v", ids, tt.providers)
+			}
+			if catalog.ItemCount() != tt.items {
+				t.Errorf("ItemCount() = %d, want %d", catalog.ItemCount(), tt.items)
+			}
+			if amount, ok := catalog.Providers[0].Items[0].Price.Amount(); !ok || amount != tt.price {
+				t.Errorf("price = %v, %v, want %v", amount, ok, tt.price)
+			}
+		})
+	}
+}
+
+func TestTagsDecoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		group string
+		code  string
+		want  string
+	}{
+		{name: "tag groups", input: `[{"code":"serviceability","list":[{"code":"type","value":"12"}]}]`, group: "serviceability", code: "type", want: "12"},
+		{name: "numeric value", input: `[{"code":"serviceability","list":[{"code":"val","value":5}]}]`, group: "serviceability", code: "val", want: "5"},
+		{name: "single group and entry", input: `{"code":"timing","list":{"code":"day_from","value":"1"}}`, group: "timing", code: "day_from", want: "1"},
+		{name: "flat legacy object", input: `{"veg":"yes"}`, group: "veg", code: "veg", want: "yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags Tags
+			if err := json.Unmarshal([]byte(tt.input), &tags); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			group, ok := tags.Group(tt.group)
+			if !ok {
+				t.Fatalf("group %s not found in %+v", tt.group, tags)
+			}
+			if got, ok := group.Value(tt.code); !ok || got != tt.want {
+				t.Fatalf("Value(%s) = %q, %v, want %q", tt.code, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr error
+	}{
+		{name: "context", payload: `{"context":{"domain":"ONDC:RET10","action":"on_search"},"message":{}}`, want: "on_search"},
+		{name: "missing context", payload: `{"message":{}}`, wantErr: ErrNoContext},
+		{name: "context not an object", payload: `{"context":"x"}`, wantErr: ErrNoContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := ParseContext([]byte(tt.payload))
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && ctx.Action != tt.want {
+				t.Fatalf("action = %q, want %q", ctx.Action, tt.want)
+			}
+		})
+	}
+	if _, err := ParseContext([]byte(`{"context":`)); err == nil {
+		t.Fatal("ParseContext accepted malformed JSON")
+	}
+}
+
+func TestCatalogAccessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		providers int
+		items     int
+	}{
+		{name: "v1", payload: `{"message":{"catalog":{"bpp/providers":[{"id":"a","items":[{"id":"1"},{"id":"2"}]},{"id":"b"}]}}}`, providers: 2, items: 2},
+		{name: "v2", payload: `{"message":{"catalog":{"providers":[{"id":"a","items":[{"id":"1"}]}]}}}`, providers: 1, items: 1},
+		{name: "single objects", payload: `{"message":{"catalog":{"bpp/providers":{"id":"a","items":{"id":"1"}}}}}`, providers: 1, items: 1},
+		{name: "no catalog", payload: `{"message":{"order":{"state":"Created"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers, items, err := CatalogStats([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("CatalogStats: %v", err)
+			}
+			if providers != tt.providers || items != tt.items {
+				t.Fatalf("CatalogStats = %d, %d, want %d, %d", providers, items, tt.providers, tt.items)
+			}
+			seen := 0
+			if err := EachProvider([]byte(tt.payload), func(provider *Provider) error {
+				seen++
+				return nil
+			}); err != nil || seen != tt.providers {
+				t.Fatalf("EachProvider visited %d providers (err %v), want %d", seen, err, tt.providers)
+			}
+		})
+	}
+}
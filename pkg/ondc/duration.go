@@ -0,0 +1,60 @@
+package ondc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iso8601Duration matches PnYnMnWnDTnHnMnS; seconds may be fractional.
+var iso8601Duration = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration parses an ISO-8601 duration such as "PT30S" or "P1DT2H".
+// Years and months have no fixed length and are taken as 365 and 30 days.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	match := iso8601Duration.FindStringSubmatch(value)
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", value)
+	}
+
+	units := []time.Duration{
+		365 * 24 * time.Hour,
+		30 * 24 * time.Hour,
+		7 * 24 * time.Hour,
+		24 * time.Hour,
+		time.Hour,
+		time.Minute,
+	}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: %w", value, err)
+		}
+		total += time.Duration(n) * unit
+	}
+	if match[7] != "" {
+		seconds, err := strconv.ParseFloat(match[7], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: %w", value, err)
+		}
+		total += time.Duration(seconds * float64(time.Second))
+	}
+	return total, nil
+}
+
+// ParseTimestamp parses context.timestamp, an RFC 3339 time with optional
+// fractional seconds.
+func ParseTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", value, err)
+	}
+	return t, nil
+}
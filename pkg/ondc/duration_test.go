@@ -0,0 +1,68 @@
+package ondc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT30S", want: 30 * time.Second},
+		{value: "PT1.5S", want: 1500 * time.Millisecond},
+		{value: "PT2M", want: 2 * time.Minute},
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "P1W", want: 7 * 24 * time.Hour},
+		{value: "P1M", want: 30 * 24 * time.Hour},
+		{value: "P1Y", want: 365 * 24 * time.Hour},
+		{value: " pt30s ", want: 30 * time.Second},
+		{value: "", wantErr: true},
+		{value: "P", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "P1DT", wantErr: true},
+		{value: "30S", wantErr: true},
+		{value: "PT-5S", wantErr: true},
+		{value: "30s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2025-01-08T08:00:30.000Z", want: time.Date(2025, 1, 8, 8, 0, 30, 0, time.UTC)},
+		{value: "2025-01-08T08:00:30Z", want: time.Date(2025, 1, 8, 8, 0, 30, 0, time.UTC)},
+		{value: "2025-01-08T13:30:30.250+05:30", want: time.Date(2025, 1, 8, 8, 0, 30, 250e6, time.UTC)},
+		{value: "2025-01-08 08:00:30", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
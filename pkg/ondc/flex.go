@@ -0,0 +1,82 @@
+package ondc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// FlexString decodes JSON strings, numbers and booleans into a string.
+// Seller apps are inconsistent about quoting numeric fields such as prices
+// and counts, so those fields use FlexString instead of failing the whole
+// decode.
+type FlexString string
+
+func (s *FlexString) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*s = ""
+	case len(data) > 0 && data[0] == '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*s = FlexString(value)
+	case len(data) > 0 && (data[0] == '{' || data[0] == '['):
+		return fmt.Errorf("cannot decode %s into a string", data)
+	default:
+		*s = FlexString(data)
+	}
+	return nil
+}
+
+func (s FlexString) String() string {
+	return string(s)
+}
+
+// Float parses the value as a number, returning false when it is empty or
+// not numeric.
+func (s FlexString) Float() (float64, bool) {
+	value, err := strconv.ParseFloat(string(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// Int parses the value as an integer, accepting "10" and "10.0".
+func (s FlexString) Int() (int, bool) {
+	value, ok := s.Float()
+	if !ok {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// List decodes either a JSON array or a single object into a slice.
+type List[T any] []T
+
+func (l *List[T]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*l = nil
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	}
+
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*l = List[T]{item}
+	return nil
+}
@@ -0,0 +1,185 @@
+package ondc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Catalog increment modes carried in the catalog_inc intent tag.
+const (
+	CatalogIncStart = "start"
+	CatalogIncStop  = "stop"
+)
+
+// Search is a search request.
+type Search struct {
+	Context Context `json:"context"`
+	Message struct {
+		Intent Intent `json:"intent"`
+	} `json:"message"`
+}
+
+// ParseSearch decodes a search request. Search payloads are small, so the
+// whole request is unmarshalled.
+func ParseSearch(payload []byte) (*Search, error) {
+	var search Search
+	if err := json.Unmarshal(payload, &search); err != nil {
+		return nil, fmt.Errorf("failed to decode search: %w", err)
+	}
+	return &search, nil
+}
+
+// Intent is what the buyer is searching for. Every part is optional.
+type Intent struct {
+	Item        *IntentItem        `json:"item,omitempty"`
+	Category    *IntentCategory    `json:"category,omitempty"`
+	Provider    *IntentProvider    `json:"provider,omitempty"`
+	Fulfillment *IntentFulfillment `json:"fulfillment,omitempty"`
+	Payment     *IntentPayment     `json:"payment,omitempty"`
+	Tags        Tags               `json:"tags,omitempty"`
+}
+
+type IntentItem struct {
+	ID         string     `json:"id,omitempty"`
+	Descriptor Descriptor `json:"descriptor"`
+}
+
+type IntentCategory struct {
+	ID         string     `json:"id,omitempty"`
+	Descriptor Descriptor `json:"descriptor"`
+}
+
+type IntentProvider struct {
+	ID         string         `json:"id,omitempty"`
+	Descriptor Descriptor     `json:"descriptor"`
+	Locations  List[Location] `json:"locations,omitempty"`
+}
+
+type IntentFulfillment struct {
+	Type  string           `json:"type,omitempty"`
+	Start *FulfillmentStop `json:"start,omitempty"`
+	End   *FulfillmentStop `json:"end,omitempty"`
+}
+
+// FulfillmentStop is the start or end of a fulfillment.
+type FulfillmentStop struct {
+	Location Location `json:"location"`
+}
+
+// IntentPayment carries the v1.1 finder fee fields; v1.2 moved them to the
+// bap_terms tag.
+type IntentPayment struct {
+	Type            string     `json:"type,omitempty"`
+	FinderFeeType   string     `json:"@ondc/org/buyer_app_finder_fee_type,omitempty"`
+	FinderFeeAmount FlexString `json:"@ondc/org/buyer_app_finder_fee_amount,omitempty"`
+}
+
+// ItemName returns the searched item name.
+func (i Intent) ItemName() string {
+	if i.Item == nil {
+		return ""
+	}
+	return strings.TrimSpace(i.Item.Descriptor.Name)
+}
+
+// CategoryID returns the searched category id.
+func (i Intent) CategoryID() string {
+	if i.Category == nil {
+		return ""
+	}
+	return i.Category.ID
+}
+
+// ProviderID returns the provider the search is restricted to.
+func (i Intent) ProviderID() string {
+	if i.Provider == nil {
+		return ""
+	}
+	return i.Provider.ID
+}
+
+// EndLocation returns the delivery location, or nil.
+func (i Intent) EndLocation() *Location {
+	if i.Fulfillment == nil || i.Fulfillment.End == nil {
+		return nil
+	}
+	return &i.Fulfillment.End.Location
+}
+
+// AreaCode returns the delivery area (pincode).
+func (i Intent) AreaCode() string {
+	if location := i.EndLocation(); location != nil && location.Address != nil {
+		return location.Address.AreaCode
+	}
+	return ""
+}
+
+// GPS returns the delivery coordinates as "lat,lng".
+func (i Intent) GPS() string {
+	if location := i.EndLocation(); location != nil {
+		return location.GPS
+	}
+	return ""
+}
+
+// FinderFee returns the buyer app finder fee type ("percent" or "amount")
+// and value from the payment block or the bap_terms tag.
+func (i Intent) FinderFee() (feeType string, amount float64, ok bool) {
+	if i.Payment != nil && i.Payment.FinderFeeType != "" {
+		amount, ok = i.Payment.FinderFeeAmount.Float()
+		return i.Payment.FinderFeeType, amount, ok
+	}
+	if terms, found := i.Tags.Group("bap_terms"); found {
+		feeType, _ = terms.Value("finder_fee_type")
+		value, _ := terms.Value("finder_fee_amount")
+		amount, ok = FlexString(value).Float()
+		return feeType, amount, ok && feeType != ""
+	}
+	return "", 0, false
+}
+
+// CatalogInc is an incremental catalog pull: either a time range, or a
+// push subscription started or stopped with Mode.
+type CatalogInc struct {
+	Mode      string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// CatalogInc returns the catalog_inc tag, or nil when the search is a
+// regular full catalog search.
+func (i Intent) CatalogInc() (*CatalogInc, error) {
+	group, found := i.Tags.Group("catalog_inc")
+	if !found {
+		return nil, nil
+	}
+
+	inc := &CatalogInc{}
+	if mode, ok := group.Value("mode"); ok {
+		if mode != CatalogIncStart && mode != CatalogIncStop {
+			return nil, fmt.Errorf("catalog_inc mode must be %s or %s, got %q", CatalogIncStart, CatalogIncStop, mode)
+		}
+		inc.Mode = mode
+	}
+	for code, target := range map[string]*time.Time{"start_time": &inc.StartTime, "end_time": &inc.EndTime} {
+		value, ok := group.Value(code)
+		if !ok {
+			continue
+		}
+		parsed, err := ParseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("catalog_inc %s: %w", code, err)
+		}
+		*target = parsed
+	}
+
+	if inc.Mode == "" && (inc.StartTime.IsZero() || inc.EndTime.IsZero()) {
+		return nil, fmt.Errorf("catalog_inc needs a mode or both start_time and end_time")
+	}
+	if !inc.StartTime.IsZero() && !inc.EndTime.IsZero() && inc.EndTime.Before(inc.StartTime) {
+		return nil, fmt.Errorf("catalog_inc end_time is before start_time")
+	}
+	return inc, nil
+}
@@ -0,0 +1,147 @@
+package ondc
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseSearchIntent(t *testing.T) {
+	tests := []struct {
+		name       string
+		intent     string
+		item       string
+		category   string
+		provider   string
+		areaCode   string
+		gps        string
+		feeType    string
+		feeAmount  float64
+		feePresent bool
+	}{
+		{
+			name:   "empty intent",
+			intent: `{}`,
+		},
+		{
+			name:     "item, category and delivery area",
+			intent:   `{"item":{"descriptor":{"name":" atta "}},"category":{"id":"Foodgrains"},"fulfillment":{"type":"Delivery","end":{"location":{"gps":"12.97,77.59","address":{"area_code":"560001"}}}}}`,
+			item:     "atta",
+			category: "Foodgrains",
+			areaCode: "560001",
+			gps:      "12.97,77.59",
+		},
+		{
+			name:     "provider without delivery address",
+			intent:   `{"provider":{"id":"P1"},"fulfillment":{"end":{"location":{"gps":"12.97,77.59"}}}}`,
+			provider: "P1",
+			gps:      "12.97,77.59",
+		},
+		{
+			name:       "v1.1 finder fee in payment",
+			intent:     `{"payment":{"@ondc/org/buyer_app_finder_fee_type":"percent","@ondc/org/buyer_app_finder_fee_amount":3}}`,
+			feeType:    "percent",
+			feeAmount:  3,
+			feePresent: true,
+		},
+		{
+			name:       "v1.2 finder fee in bap_terms",
+			intent:     `{"tags":[{"code":"bap_terms","list":[{"code":"finder_fee_type","value":"amount"},{"code":"finder_fee_amount","value":"12.5"}]}]}`,
+			feeType:    "amount",
+			feeAmount:  12.5,
+			feePresent: true,
+		},
+		{
+			name:    "bap_terms without amount",
+			intent:  `{"tags":[{"code":"bap_terms","list":[{"code":"finder_fee_type","value":"percent"}]}]}`,
+			feeType: "percent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search, err := ParseSearch([]byte(`{"context":{"action":"search"},"message":{"intent":` + tt.intent + `}}`))
+			if err != nil {
+				t.Fatalf("ParseSearch: %v", err)
+			}
+			intent := search.Message.Intent
+			if intent.ItemName() != tt.item || intent.CategoryID() != tt.category || intent.ProviderID() != tt.provider ||
+				intent.AreaCode() != tt.areaCode || intent.GPS() != tt.gps {
+				t.Errorf("item %q, category %q, provider %q, area %q, gps %q",
+					intent.ItemName(), intent.CategoryID(), intent.ProviderID(), intent.AreaCode(), intent.GPS())
+			}
+			feeType, amount, ok := intent.FinderFee()
+			if feeType != tt.feeType || amount != tt.feeAmount || ok != tt.feePresent {
+				t.Errorf("FinderFee() = %q, %v, %v; want %q, %v, %v", feeType, amount, ok, tt.feeType, tt.feeAmount, tt.feePresent)
+			}
+		})
+	}
+}
+
+func TestParseSearchSamplePayload(t *testing.T) {
+	raw, err := os.ReadFile("../../test_payloads.json")
+	if err != nil {
+		t.Fatalf("failed to read test payloads: %v", err)
+	}
+	var payloads map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &payloads); err != nil {
+		t.Fatalf("failed to parse test payloads: %v", err)
+	}
+	search, err := ParseSearch(payloads["ret18_search"])
+	if err != nil {
+		t.Fatalf("ParseSearch: %v", err)
+	}
+	if search.Context.Domain != "ONDC:RET18" || search.Context.TTL != "PT30S" {
+		t.Fatalf("unexpected context: %+v", search.Context)
+	}
+	if feeType, amount, ok := search.Message.Intent.FinderFee(); feeType != "percent" || amount != 3 || !ok {
+		t.Fatalf("FinderFee() = %q, %v, %v", feeType, amount, ok)
+	}
+}
+
+func TestCatalogInc(t *testing.T) {
+	start := time.Date(2025, 1, 8, 8, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		tags    string
+		want    *CatalogInc
+		wantErr bool
+	}{
+		{name: "full catalog search", tags: `[]`},
+		{name: "push start", tags: `[{"code":"catalog_inc","list":[{"code":"mode","value":"start"}]}]`, want: &CatalogInc{Mode: CatalogIncStart}},
+		{name: "push stop", tags: `[{"code":"catalog_inc","list":[{"code":"mode","value":"stop"}]}]`, want: &CatalogInc{Mode: CatalogIncStop}},
+		{
+			name: "time range",
+			tags: `[{"code":"catalog_inc","list":[{"code":"start_time","value":"2025-01-08T08:00:00.000Z"},{"code":"end_time","value":"2025-01-08T09:00:00.000Z"}]}]`,
+			want: &CatalogInc{StartTime: start, EndTime: end},
+		},
+		{name: "unknown mode", tags: `[{"code":"catalog_inc","list":[{"code":"mode","value":"pause"}]}]`, wantErr: true},
+		{name: "start time only", tags: `[{"code":"catalog_inc","list":[{"code":"start_time","value":"2025-01-08T08:00:00.000Z"}]}]`, wantErr: true},
+		{name: "invalid time", tags: `[{"code":"catalog_inc","list":[{"code":"start_time","value":"yesterday"},{"code":"end_time","value":"2025-01-08T09:00:00.000Z"}]}]`, wantErr: true},
+		{
+			name:    "end before start",
+			tags:    `[{"code":"catalog_inc","list":[{"code":"start_time","value":"2025-01-08T09:00:00.000Z"},{"code":"end_time","value":"2025-01-08T08:00:00.000Z"}]}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var intent Intent
+			if err := json.Unmarshal([]byte(`{"tags":`+tt.tags+`}`), &intent); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			got, err := intent.CatalogInc()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (got.Mode != tt.want.Mode || !got.StartTime.Equal(tt.want.StartTime) || !got.EndTime.Equal(tt.want.EndTime)) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
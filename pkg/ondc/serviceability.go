@@ -0,0 +1,259 @@
+package ondc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Serviceability types carried in the provider's serviceability tags.
+const (
+	ServiceabilityHyperlocal = "10"
+	ServiceabilityIntercity  = "11"
+	ServiceabilityPanIndia   = "12"
+	ServiceabilityPolygon    = "13"
+)
+
+const earthRadiusKm = 6371.0
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// ParseGPS parses an ONDC "lat,lng" string.
+func ParseGPS(value string) (Point, error) {
+	latValue, lngValue, ok := strings.Cut(strings.TrimSpace(value), ",")
+	if !ok {
+		return Point{}, fmt.Errorf("invalid gps %q, expected lat,lng", value)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latValue), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return Point{}, fmt.Errorf("invalid latitude in gps %q", value)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngValue), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return Point{}, fmt.Errorf("invalid longitude in gps %q", value)
+	}
+	return Point{Lat: lat, Lng: lng}, nil
+}
+
+// DistanceKm returns the great-circle distance between a and b.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ServiceArea is one area a provider delivers to, from a serviceability tag
+// or a location circle.
+type ServiceArea struct {
+	LocationID string
+	Category   string
+	Type       string
+	// Center and RadiusKm are set for hyperlocal areas.
+	Center   *Point
+	RadiusKm float64
+	// AreaCodes holds the pincodes of intercity areas; ranges are kept as
+	// "from-to".
+	AreaCodes []string
+	// Polygon holds the outer ring of polygon areas.
+	Polygon []Point
+}
+
+// ServiceAreas returns the provider's serviceability. Serviceability tags
+// take precedence; without them each location circle is a hyperlocal area.
+// Areas that cannot be parsed are skipped.
+func (p Provider) ServiceAreas() []ServiceArea {
+	var areas []ServiceArea
+	for _, tag := range p.Tags.Groups("serviceability") {
+		area := ServiceArea{}
+		area.LocationID, _ = tag.Value("location")
+		area.Category, _ = tag.Value("category")
+		area.Type, _ = tag.Value("type")
+		value, _ := tag.Value("val")
+		unit, _ := tag.Value("unit")
+
+		switch area.Type {
+		case ServiceabilityHyperlocal:
+			location, ok := p.Location(area.LocationID)
+			if !ok {
+				continue
+			}
+			center, err := ParseGPS(location.GPS)
+			if err != nil {
+				continue
+			}
+			radius, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			if strings.EqualFold(unit, "m") {
+				radius /= 1000
+			}
+			area.Center, area.RadiusKm = &center, radius
+		case ServiceabilityIntercity:
+			for _, code := range strings.Split(value, ",") {
+				if code = strings.TrimSpace(code); code != "" {
+					area.AreaCodes = append(area.AreaCodes, code)
+				}
+			}
+		case ServiceabilityPanIndia:
+		case ServiceabilityPolygon:
+			polygon, err := parsePolygon(value)
+			if err != nil {
+				continue
+			}
+			area.Polygon = polygon
+		default:
+			continue
+		}
+		areas = append(areas, area)
+	}
+	if len(areas) > 0 {
+		return areas
+	}
+
+	for _, location := range p.Locations {
+		if location.Circle == nil {
+			continue
+		}
+		center, err := ParseGPS(location.Circle.GPS)
+		if err != nil {
+			continue
+		}
+		radius, ok := location.Circle.Radius.Value.Float()
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(location.Circle.Radius.Unit, "m") {
+			radius /= 1000
+		}
+		areas = append(areas, ServiceArea{LocationID: location.ID, Type: ServiceabilityHyperlocal, Center: &center, RadiusKm: radius})
+	}
+	return areas
+}
+
+// Covers reports whether the area includes the buyer at gps or areaCode.
+// An empty gps or area code is not checked against areas that need it.
+func (a ServiceArea) Covers(gps *Point, areaCode string) bool {
+	switch a.Type {
+	case ServiceabilityHyperlocal:
+		return gps == nil || DistanceKm(*a.Center, *gps) <= a.RadiusKm
+	case ServiceabilityIntercity:
+		return areaCode == "" || areaCodeIn(areaCode, a.AreaCodes)
+	case ServiceabilityPolygon:
+		return gps == nil || pointInPolygon(*gps, a.Polygon)
+	}
+	return true
+}
+
+// Serves reports whether the provider delivers to gps ("lat,lng") or
+// areaCode. Providers that declare no serviceability are assumed to serve
+// everywhere, as are buyers with no location.
+func (p Provider) Serves(gps, areaCode string) bool {
+	var point *Point
+	if gps != "" {
+		if parsed, err := ParseGPS(gps); err == nil {
+			point = &parsed
+		}
+	}
+	if point == nil && areaCode == "" {
+		return true
+	}
+
+	areas := p.ServiceAreas()
+	if len(areas) == 0 {
+		return true
+	}
+	for _, area := range areas {
+		if area.Covers(point, areaCode) {
+			return true
+		}
+	}
+	return false
+}
+
+func areaCodeIn(areaCode string, codes []string) bool {
+	for _, code := range codes {
+		from, to, isRange := strings.Cut(code, "-")
+		if !isRange {
+			if code == areaCode {
+				return true
+			}
+			continue
+		}
+		// Pincodes are fixed width, so ranges compare as strings
+		if len(from) == len(areaCode) && from <= areaCode && areaCode <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePolygon reads the outer ring of a GeoJSON Polygon or of the first
+// polygon of a MultiPolygon, given as a geometry or a Feature.
+func parsePolygon(value string) ([]Point, error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	}
+	if err := json.Unmarshal([]byte(value), &geometry); err != nil {
+		return nil, fmt.Errorf("invalid polygon: %w", err)
+	}
+	if geometry.Geometry != nil {
+		geometry.Type, geometry.Coordinates = geometry.Geometry.Type, geometry.Geometry.Coordinates
+	}
+
+	var ring [][]float64
+	switch geometry.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &rings); err != nil || len(rings) == 0 {
+			return nil, fmt.Errorf("invalid polygon coordinates")
+		}
+		ring = rings[0]
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil || len(polygons) == 0 || len(polygons[0]) == 0 {
+			return nil, fmt.Errorf("invalid multipolygon coordinates")
+		}
+		ring = polygons[0][0]
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", geometry.Type)
+	}
+
+	points := make([]Point, 0, len(ring))
+	for _, position := range ring {
+		if len(position) < 2 {
+			return nil, fmt.Errorf("invalid polygon position")
+		}
+		// GeoJSON positions are [lng, lat]
+		points = append(points, Point{Lat: position[1], Lng: position[0]})
+	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("polygon needs at least 3 points")
+	}
+	return points, nil
+}
+
+// pointInPolygon uses ray casting, which is accurate enough at city scale.
+func pointInPolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) && p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,164 @@
+package ondc
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrInvalidSignature is returned when an Authorization header does not
+// verify against the body.
+var ErrInvalidSignature = errors.New("invalid ONDC signature")
+
+// Signer produces the ONDC Authorization header: an ed25519 signature over
+// the created and expires times and the BLAKE2b-512 digest of the body.
+type Signer struct {
+	subscriberID string
+	uniqueKeyID  string
+	privateKey   ed25519.PrivateKey
+	validity     time.Duration
+}
+
+// NewSigner constructs a Signer. privateKey is base64 and may be either the
+// 32-byte seed or the 64-byte ed25519 private key.
+func NewSigner(subscriberID, uniqueKeyID, privateKey string, validity time.Duration) (*Signer, error) {
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if subscriberID == "" || uniqueKeyID == "" {
+		return nil, fmt.Errorf("subscriber id and unique key id are required")
+	}
+	return &Signer{
+		subscriberID: subscriberID,
+		uniqueKeyID:  uniqueKeyID,
+		privateKey:   key,
+		validity:     validity,
+	}, nil
+}
+
+// ParsePrivateKey decodes a base64 ed25519 seed or private key.
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+	return nil, fmt.Errorf("signing key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+}
+
+// PublicKey returns the base64 public key registered for the signer.
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the Authorization header value for body, valid from now.
+func (s *Signer) Sign(body []byte) string {
+	created := time.Now().Unix()
+	expires := created + int64(s.validity/time.Second)
+	signature := ed25519.Sign(s.privateKey, []byte(signingString(body, created, expires)))
+
+	return fmt.Sprintf(
+		`Signature keyId="%s|%s|ed25519",algorithm="ed25519",created="%d",expires="%d",headers="(created) (expires) digest",signature="%s"`,
+		s.subscriberID, s.uniqueKeyID, created, expires, base64.StdEncoding.EncodeToString(signature),
+	)
+}
+
+// SignatureParams are the fields of an ONDC Authorization header.
+type SignatureParams struct {
+	SubscriberID string
+	UniqueKeyID  string
+	Algorithm    string
+	Created      int64
+	Expires      int64
+	Signature    []byte
+}
+
+var signatureParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ParseAuthorization decodes an ONDC Authorization header.
+func ParseAuthorization(header string) (*SignatureParams, error) {
+	header = strings.TrimSpace(header)
+	if !strings.HasPrefix(header, "Signature ") {
+		return nil, fmt.Errorf("%w: not a Signature header", ErrInvalidSignature)
+	}
+
+	values := make(map[string]string)
+	for _, match := range signatureParam.FindAllStringSubmatch(header, -1) {
+		values[match[1]] = match[2]
+	}
+
+	keyID := strings.Split(values["keyId"], "|")
+	if len(keyID) != 3 {
+		return nil, fmt.Errorf("%w: keyId must be subscriber_id|unique_key_id|algorithm", ErrInvalidSignature)
+	}
+	created, err := strconv.ParseInt(values["created"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid created", ErrInvalidSignature)
+	}
+	expires, err := strconv.ParseInt(values["expires"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expires", ErrInvalidSignature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(values["signature"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature is not base64", ErrInvalidSignature)
+	}
+
+	return &SignatureParams{
+		SubscriberID: keyID[0],
+		UniqueKeyID:  keyID[1],
+		Algorithm:    keyID[2],
+		Created:      created,
+		Expires:      expires,
+		Signature:    signature,
+	}, nil
+}
+
+// VerifyAuthorization checks header against body using the base64 ed25519
+// publicKey of the sender.
+func VerifyAuthorization(header string, body []byte, publicKey string) (*SignatureParams, error) {
+	params, err := ParseAuthorization(header)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d base64 bytes", ed25519.PublicKeySize)
+	}
+
+	now := time.Now().Unix()
+	if now > params.Expires {
+		return params, fmt.Errorf("%w: signature expired", ErrInvalidSignature)
+	}
+	// Allow a little clock skew for created
+	if params.Created > now+5 {
+		return params, fmt.Errorf("%w: signature created in the future", ErrInvalidSignature)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), []byte(signingString(body, params.Created, params.Expires)), params.Signature) {
+		return params, ErrInvalidSignature
+	}
+	return params, nil
+}
+
+// Digest returns the BLAKE-512 digest header value of body.
+func Digest(body []byte) string {
+	sum := blake2b.Sum512(body)
+	return "BLAKE-512=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func signingString(body []byte, created, expires int64) string {
+	return fmt.Sprintf("(created): %d\n(expires): %d\ndigest: %s", created, expires, Digest(body))
+}
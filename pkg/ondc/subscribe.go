@@ -0,0 +1,235 @@
+package ondc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// SiteVerificationPath is where the registry fetches the signed request id
+// of a subscription.
+const SiteVerificationPath = "/ondc-site-verification.html"
+
+// SubscribeRequest is the payload sent to the registry's /subscribe.
+type SubscribeRequest struct {
+	Context struct {
+		Operation struct {
+			OpsNo int `json:"ops_no"`
+		} `json:"operation"`
+	} `json:"context"`
+	Message SubscribeMessage `json:"message"`
+}
+
+type SubscribeMessage struct {
+	RequestID          string               `json:"request_id"`
+	Timestamp          string               `json:"timestamp"`
+	Entity             SubscribeEntity      `json:"entity"`
+	NetworkParticipant []NetworkParticipant `json:"network_participant"`
+}
+
+type SubscribeEntity struct {
+	GST                       SubscriberGST `json:"gst"`
+	PAN                       SubscriberPAN `json:"pan"`
+	NameOfAuthorisedSignatory string        `json:"name_of_authorised_signatory"`
+	EmailID                   string        `json:"email_id"`
+	MobileNo                  int64         `json:"mobile_no"`
+	Country                   string        `json:"country"`
+	SubscriberID              string        `json:"subscriber_id"`
+	UniqueKeyID               string        `json:"unique_key_id"`
+	CallbackURL               string        `json:"callback_url"`
+	KeyPair                   KeyPair       `json:"key_pair"`
+}
+
+type SubscriberGST struct {
+	LegalEntityName string   `json:"legal_entity_name"`
+	BusinessAddress string   `json:"business_address"`
+	CityCode        []string `json:"city_code"`
+	GstNo           string   `json:"gst_no"`
+}
+
+type SubscriberPAN struct {
+	NameAsPerPan        string `json:"name_as_per_pan"`
+	PanNo               string `json:"pan_no"`
+	DateOfIncorporation string `json:"date_of_incorporation"`
+}
+
+// KeyPair holds the public keys registered for a subscriber: the base64
+// ed25519 signing key and the base64 DER X25519 encryption key.
+type KeyPair struct {
+	SigningPublicKey    string `json:"signing_public_key"`
+	EncryptionPublicKey string `json:"encryption_public_key"`
+	ValidFrom           string `json:"valid_from"`
+	ValidUntil          string `json:"valid_until"`
+}
+
+type NetworkParticipant struct {
+	SubscriberURL string   `json:"subscriber_url"`
+	Domain        string   `json:"domain"`
+	Type          string   `json:"type"`
+	MSN           bool     `json:"msn"`
+	CityCode      []string `json:"city_code"`
+}
+
+// OnSubscribeRequest is the registry's challenge to a subscriber.
+type OnSubscribeRequest struct {
+	SubscriberID string `json:"subscriber_id"`
+	Challenge    string `json:"challenge"`
+}
+
+// OnSubscribeResponse carries the decrypted challenge.
+type OnSubscribeResponse struct {
+	Answer string `json:"answer"`
+}
+
+// ParseEncryptionPrivateKey decodes a base64 X25519 private key, either
+// PKCS#8 DER as issued by the ONDC tooling or the raw 32 bytes.
+func ParseEncryptionPrivateKey(value string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not base64: %w", err)
+	}
+	if len(raw) == 32 {
+		return ecdh.X25519().NewPrivateKey(raw)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is neither 32 bytes nor PKCS#8: %w", err)
+	}
+	key, ok := parsed.(*ecdh.PrivateKey)
+	if !ok || key.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("encryption key is not an X25519 key")
+	}
+	return key, nil
+}
+
+// ParseEncryptionPublicKey decodes a base64 X25519 public key, either
+// SubjectPublicKeyInfo DER or the raw 32 bytes.
+func ParseEncryptionPublicKey(value string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("encryption public key is not base64: %w", err)
+	}
+	if len(raw) == 32 {
+		return ecdh.X25519().NewPublicKey(raw)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encryption public key is neither 32 bytes nor DER: %w", err)
+	}
+	key, ok := parsed.(*ecdh.PublicKey)
+	if !ok || key.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("encryption public key is not an X25519 key")
+	}
+	return key, nil
+}
+
+// MarshalEncryptionPrivateKey encodes key as base64 PKCS#8 DER.
+func MarshalEncryptionPrivateKey(key *ecdh.PrivateKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(der), nil
+}
+
+// MarshalEncryptionPublicKey encodes key as base64 SubjectPublicKeyInfo
+// DER, the form the registry expects.
+func MarshalEncryptionPublicKey(key *ecdh.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(der), nil
+}
+
+// DecryptChallenge decrypts an on_subscribe challenge: AES-256-ECB with
+// PKCS#7 padding, keyed by the X25519 secret shared between the
+// subscriber's private key and the registry's public key.
+func DecryptChallenge(challenge string, private *ecdh.PrivateKey, peer *ecdh.PublicKey) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(challenge))
+	if err != nil {
+		return "", fmt.Errorf("challenge is not base64: %w", err)
+	}
+	block, err := sharedCipher(private, peer)
+	if err != nil {
+		return "", err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("challenge is not a whole number of AES blocks")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	for i := 0; i < len(ciphertext); i += aes.BlockSize {
+		block.Decrypt(plaintext[i:i+aes.BlockSize], ciphertext[i:i+aes.BlockSize])
+	}
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return "", fmt.Errorf("challenge has invalid padding, the keys do not match")
+	}
+	return string(plaintext[:len(plaintext)-padding]), nil
+}
+
+// EncryptChallenge is the registry side of DecryptChallenge.
+func EncryptChallenge(challenge string, private *ecdh.PrivateKey, peer *ecdh.PublicKey) (string, error) {
+	block, err := sharedCipher(private, peer)
+	if err != nil {
+		return "", err
+	}
+	padding := aes.BlockSize - len(challenge)%aes.BlockSize
+	plaintext := append([]byte(challenge), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	ciphertext := make([]byte, len(plaintext))
+	for i := 0; i < len(plaintext); i += aes.BlockSize {
+		block.Encrypt(ciphertext[i:i+aes.BlockSize], plaintext[i:i+aes.BlockSize])
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func sharedCipher(private *ecdh.PrivateKey, peer *ecdh.PublicKey) (cipher.Block, error) {
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared key: %w", err)
+	}
+	return aes.NewCipher(shared)
+}
+
+// SignRequestID returns the base64 ed25519 signature of a subscription
+// request id, published on the site verification page.
+func SignRequestID(key ed25519.PrivateKey, requestID string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(requestID)))
+}
+
+// VerifyRequestID checks a signed request id against the base64 ed25519
+// signing public key.
+func VerifyRequestID(publicKey, requestID, signature string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("public key must be %d base64 bytes", ed25519.PublicKeySize)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64", ErrInvalidSignature)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), []byte(requestID), raw) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SiteVerificationHTML renders the page served at SiteVerificationPath.
+func SiteVerificationHTML(signedRequestID string) string {
+	return "<html>\n" +
+		"  <head>\n" +
+		"    <meta name='ondc-site-verification' content='" + signedRequestID + "' />\n" +
+		"  </head>\n" +
+		"  <body>\n" +
+		"    ONDC Site Verification Page\n" +
+		"  </body>\n" +
+		"</html>\n"
+}
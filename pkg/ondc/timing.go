@@ -0,0 +1,322 @@
+package ondc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timing types carried in the provider's timing tags.
+const (
+	TimingOrder      = "Order"
+	TimingDelivery   = "Delivery"
+	TimingSelfPickup = "Self-Pickup"
+	TimingAll        = "All"
+)
+
+// HolidayLayout is the date format of location holidays.
+const HolidayLayout = "2006-01-02"
+
+const minutesPerDay = 24 * 60
+
+// TimingWindow is a weekly recurring window. Days run from 1 (Monday) to
+// 7 (Sunday); times are "HHMM" in the store's local time. A window whose
+// TimeTo is before its TimeFrom runs past midnight.
+type TimingWindow struct {
+	Type     string `json:"type"`
+	DayFrom  int    `json:"day_from"`
+	DayTo    int    `json:"day_to"`
+	TimeFrom string `json:"time_from"`
+	TimeTo   string `json:"time_to"`
+}
+
+// Minutes returns the window's times as minutes since midnight.
+func (w TimingWindow) Minutes() (from, to int) {
+	from, _ = parseClock(w.TimeFrom)
+	to, _ = parseClock(w.TimeTo)
+	return from, to
+}
+
+// Covers reports whether the window opens on weekday (1-7).
+func (w TimingWindow) Covers(weekday int) bool {
+	return w.DayFrom <= weekday && weekday <= w.DayTo
+}
+
+// TakesOrders reports whether the window applies to ordering.
+func (w TimingWindow) TakesOrders() bool {
+	return w.Type == TimingOrder || w.Type == TimingAll || w.Type == ""
+}
+
+// LocationSchedule is the normalized timing of one provider location.
+type LocationSchedule struct {
+	LocationID string
+	// Enabled is false when the location's time.label is "disable".
+	Enabled  bool
+	Windows  []TimingWindow
+	Holidays []string
+	// Warnings lists the inconsistencies found while parsing; offending
+	// entries are left out of Windows and Holidays.
+	Warnings []string
+}
+
+// Schedules returns the timing of each provider location. Timing tags,
+// on the provider or the location, take precedence over the location's
+// time.days and time.range; a tag without a location applies to every
+// location. Locations with no timing at all have no windows.
+func (p Provider) Schedules() []LocationSchedule {
+	var schedules []*LocationSchedule
+	byID := make(map[string]*LocationSchedule)
+	scheduleOf := func(id string) *LocationSchedule {
+		if schedule, ok := byID[id]; ok {
+			return schedule
+		}
+		schedule := &LocationSchedule{LocationID: id, Enabled: true}
+		byID[id] = schedule
+		schedules = append(schedules, schedule)
+		return schedule
+	}
+
+	for _, location := range p.Locations {
+		schedule := scheduleOf(location.ID)
+		for _, tag := range location.Tags.Groups("timing") {
+			schedule.addTag(tag)
+		}
+	}
+
+	var shared []TimingWindow
+	var sharedWarnings []string
+	for _, tag := range p.Tags.Groups("timing") {
+		locationID, _ := tag.Value("location")
+		if locationID == "" || locationID == "*" {
+			window, err := parseTimingTag(tag)
+			if err != nil {
+				sharedWarnings = append(sharedWarnings, fmt.Sprintf("timing for all locations: %v", err))
+				continue
+			}
+			shared = append(shared, window)
+			continue
+		}
+		schedule, known := byID[locationID]
+		if !known {
+			schedule = scheduleOf(locationID)
+			schedule.Warnings = append(schedule.Warnings, fmt.Sprintf("timing refers to unknown location %q", locationID))
+		}
+		schedule.addTag(tag)
+	}
+	if len(schedules) == 0 && (len(shared) > 0 || len(sharedWarnings) > 0) {
+		scheduleOf("*")
+	}
+
+	for _, schedule := range schedules {
+		schedule.Windows = append(schedule.Windows, shared...)
+		schedule.Warnings = append(schedule.Warnings, sharedWarnings...)
+
+		location, ok := p.Location(schedule.LocationID)
+		if ok && location.Time != nil {
+			schedule.addTime(location.Time)
+		}
+		schedule.Warnings = append(schedule.Warnings, overlaps(schedule.Windows)...)
+	}
+
+	result := make([]LocationSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, *schedule)
+	}
+	return result
+}
+
+func (s *LocationSchedule) addTag(tag Tag) {
+	window, err := parseTimingTag(tag)
+	if err != nil {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("timing: %v", err))
+		return
+	}
+	s.Windows = append(s.Windows, window)
+}
+
+// addTime applies the location's time object: its label, its holidays
+// and, without timing tags, its days and range.
+func (s *LocationSchedule) addTime(t *Time) {
+	if strings.EqualFold(t.Label, "disable") {
+		s.Enabled = false
+	}
+	if t.Schedule != nil {
+		for _, holiday := range t.Schedule.Holidays {
+			holiday = strings.TrimSpace(holiday)
+			if _, err := time.Parse(HolidayLayout, holiday); err != nil {
+				s.Warnings = append(s.Warnings, fmt.Sprintf("invalid holiday %q, expected YYYY-MM-DD", holiday))
+				continue
+			}
+			s.Holidays = append(s.Holidays, holiday)
+		}
+	}
+	if len(s.Windows) > 0 || t.Range == nil {
+		return
+	}
+
+	from, errFrom := parseClock(t.Range.Start)
+	to, errTo := parseClock(t.Range.End)
+	if errFrom != nil || errTo != nil || from == to {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("invalid time.range %q-%q", t.Range.Start, t.Range.End))
+		return
+	}
+	days, err := parseDays(t.Days)
+	if err != nil {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("time.days: %v", err))
+		return
+	}
+	// Consecutive days become one window
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		s.Windows = append(s.Windows, TimingWindow{
+			Type:     TimingOrder,
+			DayFrom:  days[i],
+			DayTo:    days[j],
+			TimeFrom: formatClock(from),
+			TimeTo:   formatClock(to),
+		})
+		i = j + 1
+	}
+}
+
+func parseTimingTag(tag Tag) (TimingWindow, error) {
+	window := TimingWindow{}
+	window.Type, _ = tag.Value("type")
+	switch window.Type {
+	case TimingOrder, TimingDelivery, TimingSelfPickup, TimingAll:
+	default:
+		return window, fmt.Errorf("invalid type %q", window.Type)
+	}
+
+	dayFrom, _ := tag.Value("day_from")
+	dayTo, _ := tag.Value("day_to")
+	var err error
+	if window.DayFrom, err = parseDay(dayFrom); err != nil {
+		return window, fmt.Errorf("day_from: %w", err)
+	}
+	if window.DayTo, err = parseDay(dayTo); err != nil {
+		return window, fmt.Errorf("day_to: %w", err)
+	}
+	if window.DayFrom > window.DayTo {
+		return window, fmt.Errorf("day_from %d is after day_to %d", window.DayFrom, window.DayTo)
+	}
+
+	timeFrom, _ := tag.Value("time_from")
+	timeTo, _ := tag.Value("time_to")
+	from, err := parseClock(timeFrom)
+	if err != nil {
+		return window, fmt.Errorf("time_from: %w", err)
+	}
+	to, err := parseClock(timeTo)
+	if err != nil {
+		return window, fmt.Errorf("time_to: %w", err)
+	}
+	if from == to {
+		return window, fmt.Errorf("time_from and time_to are both %s", timeFrom)
+	}
+	window.TimeFrom, window.TimeTo = formatClock(from), formatClock(to)
+	return window, nil
+}
+
+func parseDay(value string) (int, error) {
+	day, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || day < 1 || day > 7 {
+		return 0, fmt.Errorf("invalid day code %q, expected 1 (Monday) to 7 (Sunday)", value)
+	}
+	return day, nil
+}
+
+// parseDays reads a comma-separated list of day codes, sorted and without
+// duplicates.
+func parseDays(value string) ([]int, error) {
+	var seen [8]bool
+	for _, code := range strings.Split(value, ",") {
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		day, err := parseDay(code)
+		if err != nil {
+			return nil, err
+		}
+		seen[day] = true
+	}
+	var days []int
+	for day := 1; day <= 7; day++ {
+		if seen[day] {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days given")
+	}
+	return days, nil
+}
+
+// parseClock reads "HHMM" or "HH:MM" as minutes since midnight; "2400"
+// is the end of the day.
+func parseClock(value string) (int, error) {
+	digits := strings.ReplaceAll(strings.TrimSpace(value), ":", "")
+	if len(digits) != 4 {
+		return 0, fmt.Errorf("invalid time %q, expected HHMM", value)
+	}
+	hours, errHours := strconv.Atoi(digits[:2])
+	minutes, errMinutes := strconv.Atoi(digits[2:])
+	if errHours != nil || errMinutes != nil || minutes > 59 || hours > 24 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HHMM", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d%02d", minutes/60, minutes%60)
+}
+
+// overlaps reports windows of the same type whose times overlap on a
+// shared day. Windows past midnight are split at midnight first.
+func overlaps(windows []TimingWindow) []string {
+	type segment struct {
+		window   int
+		day      int
+		from, to int
+	}
+	var segments []segment
+	for i, window := range windows {
+		from, to := window.Minutes()
+		for day := window.DayFrom; day <= window.DayTo; day++ {
+			if to > from {
+				segments = append(segments, segment{window: i, day: day, from: from, to: to})
+				continue
+			}
+			segments = append(segments, segment{window: i, day: day, from: from, to: minutesPerDay})
+			if to > 0 {
+				segments = append(segments, segment{window: i, day: day%7 + 1, from: 0, to: to})
+			}
+		}
+	}
+
+	var warnings []string
+	reported := make(map[[2]int]bool)
+	for i, a := range segments {
+		for _, b := range segments[i+1:] {
+			if a.window == b.window || a.day != b.day || a.from >= b.to || b.from >= a.to {
+				continue
+			}
+			wa, wb := windows[a.window], windows[b.window]
+			if wa.Type != wb.Type && wa.Type != TimingAll && wb.Type != TimingAll {
+				continue
+			}
+			pair := [2]int{a.window, b.window}
+			if reported[pair] {
+				continue
+			}
+			reported[pair] = true
+			warnings = append(warnings, fmt.Sprintf("%s timing days %d-%d %s-%s overlaps %s timing days %d-%d %s-%s",
+				wa.Type, wa.DayFrom, wa.DayTo, wa.TimeFrom, wa.TimeTo, wb.Type, wb.DayFrom, wb.DayTo, wb.TimeFrom, wb.TimeTo))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,203 @@
+package ondc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// timingTag renders a timing tag group for location.
+func timingTag(location, timingType, dayFrom, dayTo, timeFrom, timeTo string) string {
+	return fmt.Sprintf(`{"code":"timing","list":[{"code":"location","value":%q},{"code":"type","value":%q},{"code":"day_from","value":%q},{"code":"day_to","value":%q},{"code":"time_from","value":%q},{"code":"time_to","value":%q}]}`,
+		location, timingType, dayFrom, dayTo, timeFrom, timeTo)
+}
+
+func TestProviderSchedules(t *testing.T) {
+	tests := []struct {
+		name string
+		// locationTime is the time object of location L1, if any.
+		locationTime string
+		tags         []string
+		wantEnabled  bool
+		wantWindows  []TimingWindow
+		wantHolidays []string
+		// wantWarnings are substrings of the warnings, in order.
+		wantWarnings []string
+	}{
+		{
+			name:        "window for all locations",
+			tags:        []string{timingTag("", "Order", "1", "5", "0900", "2100")},
+			wantEnabled: true,
+			wantWindows: []TimingWindow{{Type: TimingOrder, DayFrom: 1, DayTo: 5, TimeFrom: "0900", TimeTo: "2100"}},
+		},
+		{
+			name:        "overnight window",
+			tags:        []string{timingTag("L1", "Order", "1", "7", "22:00", "02:00")},
+			wantEnabled: true,
+			wantWindows: []TimingWindow{{Type: TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "2200", TimeTo: "0200"}},
+		},
+		{
+			name:         "day_from after day_to",
+			tags:         []string{timingTag("L1", "Order", "5", "2", "0900", "2100")},
+			wantEnabled:  true,
+			wantWarnings: []string{"day_from 5 is after day_to 2"},
+		},
+		{
+			name:         "invalid day code",
+			tags:         []string{timingTag("L1", "Order", "0", "7", "0900", "2100")},
+			wantEnabled:  true,
+			wantWarnings: []string{"day_from: invalid day code"},
+		},
+		{
+			name:         "invalid type",
+			tags:         []string{timingTag("L1", "Pickup", "1", "7", "0900", "2100")},
+			wantEnabled:  true,
+			wantWarnings: []string{`invalid type "Pickup"`},
+		},
+		{
+			name:         "empty window",
+			tags:         []string{timingTag("L1", "Order", "1", "7", "0900", "0900")},
+			wantEnabled:  true,
+			wantWarnings: []string{"time_from and time_to are both 0900"},
+		},
+		{
+			name:         "invalid time",
+			tags:         []string{timingTag("L1", "Order", "1", "7", "0900", "2430")},
+			wantEnabled:  true,
+			wantWarnings: []string{`time_to: invalid time "2430"`},
+		},
+		{
+			name: "overlapping windows",
+			tags: []string{
+				timingTag("L1", "Order", "1", "5", "0900", "1800"),
+				timingTag("L1", "Order", "3", "7", "1700", "2200"),
+			},
+			wantEnabled: true,
+			wantWindows: []TimingWindow{
+				{Type: TimingOrder, DayFrom: 1, DayTo: 5, TimeFrom: "0900", TimeTo: "1800"},
+				{Type: TimingOrder, DayFrom: 3, DayTo: 7, TimeFrom: "1700", TimeTo: "2200"},
+			},
+			wantWarnings: []string{"Order timing days 1-5 0900-1800 overlaps Order timing days 3-7 1700-2200"},
+		},
+		{
+			name: "overlap past midnight",
+			tags: []string{
+				timingTag("L1", "Order", "5", "5", "2200", "0200"),
+				timingTag("L1", "All", "6", "6", "0100", "0500"),
+			},
+			wantEnabled: true,
+			wantWindows: []TimingWindow{
+				{Type: TimingOrder, DayFrom: 5, DayTo: 5, TimeFrom: "2200", TimeTo: "0200"},
+				{Type: TimingAll, DayFrom: 6, DayTo: 6, TimeFrom: "0100", TimeTo: "0500"},
+			},
+			wantWarnings: []string{"overlaps All timing days 6-6"},
+		},
+		{
+			name: "different types do not overlap",
+			tags: []string{
+				timingTag("L1", "Order", "1", "7", "0900", "2100"),
+				timingTag("L1", "Delivery", "1", "7", "1000", "2000"),
+			},
+			wantEnabled: true,
+			wantWindows: []TimingWindow{
+				{Type: TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "0900", TimeTo: "2100"},
+				{Type: TimingDelivery, DayFrom: 1, DayTo: 7, TimeFrom: "1000", TimeTo: "2000"},
+			},
+		},
+		{
+			name:         "disabled location",
+			locationTime: `{"label":"disable","schedule":{"holidays":["2026-10-20"]}}`,
+			tags:         []string{timingTag("L1", "Order", "1", "7", "0900", "2100")},
+			wantWindows:  []TimingWindow{{Type: TimingOrder, DayFrom: 1, DayTo: 7, TimeFrom: "0900", TimeTo: "2100"}},
+			wantHolidays: []string{"2026-10-20"},
+		},
+		{
+			name:         "holidays",
+			locationTime: `{"label":"enable","schedule":{"holidays":["2026-10-20","20-10-2026"]}}`,
+			wantEnabled:  true,
+			wantHolidays: []string{"2026-10-20"},
+			wantWarnings: []string{`invalid holiday "20-10-2026"`},
+		},
+		{
+			name:         "time days and range without tags",
+			locationTime: `{"days":"1,2,3,5","range":{"start":"0900","end":"1800"}}`,
+			wantEnabled:  true,
+			wantWindows: []TimingWindow{
+				{Type: TimingOrder, DayFrom: 1, DayTo: 3, TimeFrom: "0900", TimeTo: "1800"},
+				{Type: TimingOrder, DayFrom: 5, DayTo: 5, TimeFrom: "0900", TimeTo: "1800"},
+			},
+		},
+		{
+			name:         "tags take precedence over time range",
+			locationTime: `{"days":"1,2,3","range":{"start":"0900","end":"1800"}}`,
+			tags:         []string{timingTag("L1", "Order", "6", "7", "1000", "1400")},
+			wantEnabled:  true,
+			wantWindows:  []TimingWindow{{Type: TimingOrder, DayFrom: 6, DayTo: 7, TimeFrom: "1000", TimeTo: "1400"}},
+		},
+		{
+			name:         "invalid time range",
+			locationTime: `{"days":"1","range":{"start":"1800","end":"1800"}}`,
+			wantEnabled:  true,
+			wantWarnings: []string{`invalid time.range "1800"-"1800"`},
+		},
+		{
+			name:         "invalid time days",
+			locationTime: `{"days":"1,8","range":{"start":"0900","end":"1800"}}`,
+			wantEnabled:  true,
+			wantWarnings: []string{"time.days: invalid day code"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := `{"id":"L1"}`
+			if tt.locationTime != "" {
+				location = fmt.Sprintf(`{"id":"L1","time":%s}`, tt.locationTime)
+			}
+			payload := fmt.Sprintf(`{"id":"P1","locations":[%s],"tags":[%s]}`, location, strings.Join(tt.tags, ","))
+			var provider Provider
+			if err := json.Unmarshal([]byte(payload), &provider); err != nil {
+				t.Fatalf("failed to decode provider: %v", err)
+			}
+
+			schedules := provider.Schedules()
+			if len(schedules) != 1 || schedules[0].LocationID != "L1" {
+				t.Fatalf("Schedules() = %+v, want one schedule for L1", schedules)
+			}
+			schedule := schedules[0]
+			if schedule.Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %v, want %v", schedule.Enabled, tt.wantEnabled)
+			}
+			if !reflect.DeepEqual(schedule.Windows, tt.wantWindows) {
+				t.Errorf("Windows = %+v, want %+v", schedule.Windows, tt.wantWindows)
+			}
+			if !reflect.DeepEqual(schedule.Holidays, tt.wantHolidays) {
+				t.Errorf("Holidays = %v, want %v", schedule.Holidays, tt.wantHolidays)
+			}
+			if len(schedule.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("Warnings = %q, want %d matching %q", schedule.Warnings, len(tt.wantWarnings), tt.wantWarnings)
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.Contains(schedule.Warnings[i], want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, schedule.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderSchedulesUnknownLocation(t *testing.T) {
+	payload := `{"id":"P1","locations":[{"id":"L1"}],"tags":[` + timingTag("L9", "Order", "1", "7", "0900", "2100") + `]}`
+	var provider Provider
+	if err := json.Unmarshal([]byte(payload), &provider); err != nil {
+		t.Fatalf("failed to decode provider: %v", err)
+	}
+	schedules := provider.Schedules()
+	if len(schedules) != 2 || schedules[1].LocationID != "L9" {
+		t.Fatalf("Schedules() = %+v, want L1 and L9", schedules)
+	}
+	if warnings := schedules[1].Warnings; len(warnings) != 1 || !strings.Contains(warnings[0], `unknown location "L9"`) {
+		t.Fatalf("L9 warnings = %q, want the unknown location", warnings)
+	}
+}
@@ -10,11 +10,7 @@ PGPASSWORD=postgres psql -h localhost -U postgres -p 5432 << EOF
 SELECT 'CREATE DATABASE adapter_db'
 WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = 'adapter_db')\gexec
 
--- Connect to adapter_db and run migrations
-\c adapter_db
-
--- Run migrations
-$(cat scripts/migrations/001_create_adapters_table.sql)
+-- Tables are created by the embedded migrations when the service starts
 
 EOF
 